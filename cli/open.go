@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// ProcessURL returns the URL to reach a process on port: its tailnet DNS
+// URL when the hostname is known, otherwise its local URL. local forces
+// the local URL and dns the tailnet one, failing without a hostname.
+func ProcessURL(port int, hostname string, dns, local bool) (string, error) {
+	switch {
+	case local:
+		return fmt.Sprintf("http://localhost:%d", port), nil
+	case hostname != "":
+		return fmt.Sprintf("https://%s:%d", hostname, port), nil
+	case dns:
+		return "", fmt.Errorf("no tailnet hostname: is tailscale running?")
+	}
+	return fmt.Sprintf("http://localhost:%d", port), nil
+}
+
+// OpenBrowser opens url in the default browser, with xdg-open (open on
+// macOS). It fails when there is no opener, as over SSH. The opener is
+// not waited for, since xdg-open without a desktop runs $BROWSER until it
+// is closed; it is reaped in the background, so only failing to start it
+// is reported.
+func OpenBrowser(url string) error {
+	name := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		name = "open"
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("%s not found", name)
+	}
+	cmd := exec.Command(path, url)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// qrQuietZone is the light border around a QR code, in modules. The
+// standard asks for 4 but phones read 2 fine, and terminals are small.
+const qrQuietZone = 2
+
+// RenderQR renders text as a QR code for the terminal, two rows of
+// modules per line using half blocks. Light modules are drawn and dark
+// ones left blank, so it scans on a dark background.
+func RenderQR(text string) (string, error) {
+	code, err := qr.Encode(text, qr.M)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	n := code.Size + 2*qrQuietZone
+	light := func(x, y int) bool {
+		// Black is false outside the code, giving the quiet zone.
+		return y < n && !code.Black(x-qrQuietZone, y-qrQuietZone)
+	}
+
+	var b strings.Builder
+	for y := 0; y < n; y += 2 {
+		for x := range n {
+			top, bottom := light(x, y), light(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
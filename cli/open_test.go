@@ -0,0 +1,92 @@
+package cli_test
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestProcessURL(t *testing.T) {
+	tests := []struct {
+		hostname   string
+		dns, local bool
+		want       string
+		wantErr    bool
+	}{
+		{"host.example.ts.net", false, false, "https://host.example.ts.net:3000", false},
+		{"", false, false, "http://localhost:3000", false},
+		{"host.example.ts.net", false, true, "http://localhost:3000", false},
+		{"host.example.ts.net", true, false, "https://host.example.ts.net:3000", false},
+		{"", true, false, "", true},
+	}
+	for _, tt := range tests {
+		got, err := cli.ProcessURL(3000, tt.hostname, tt.dns, tt.local)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ProcessURL(%q, dns=%v, local=%v) = %q, %v; want %q", tt.hostname, tt.dns, tt.local, got, err, tt.want)
+		}
+	}
+}
+
+func TestOpenBrowser(t *testing.T) {
+	dir := t.TempDir()
+	args := filepath.Join(dir, "args")
+	// Like xdg-open running $BROWSER, stay until the browser is closed.
+	script := "#!/bin/sh\necho \"$@\" > " + args + "\nsleep 10\n"
+	if err := os.WriteFile(filepath.Join(dir, "xdg-open"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+":"+os.Getenv("PATH"))
+
+	start := time.Now()
+	if err := cli.OpenBrowser("http://localhost:3000"); err != nil {
+		t.Fatalf("OpenBrowser failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("OpenBrowser waited %v for the browser to exit", elapsed)
+	}
+	var got string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		data, err := os.ReadFile(args)
+		if got = strings.TrimSpace(string(data)); err == nil && got != "" {
+			break
+		}
+	}
+	if got != "http://localhost:3000" {
+		t.Errorf("expected xdg-open to get the URL, got %q", got)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if err := cli.OpenBrowser("http://localhost:3000"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error without xdg-open, got %v", err)
+	}
+}
+
+func TestRenderQR(t *testing.T) {
+	out, err := cli.RenderQR("https://host.example.ts.net:3000")
+	if err != nil {
+		t.Fatalf("RenderQR failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	width := utf8.RuneCountInString(lines[0])
+	// Two module rows per line, with a two module quiet zone all round.
+	if len(lines) != (width+1)/2 {
+		t.Errorf("expected %d lines for a %d wide code, got %d", (width+1)/2, width, len(lines))
+	}
+	for i, line := range lines {
+		if n := utf8.RuneCountInString(line); n != width {
+			t.Fatalf("line %d is %d wide, want %d", i, n, width)
+		}
+	}
+	if lines[0] != strings.Repeat("█", width) {
+		t.Errorf("expected the quiet zone on top, got %q", lines[0])
+	}
+	// The top of the finder pattern at the top left: a dark row of 7,
+	// then the ring's sides around its light inside.
+	if !strings.HasPrefix(lines[1], "██ ▄▄▄▄▄ ") {
+		t.Errorf("expected the finder pattern at the top left, got %q", lines[1])
+	}
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/protocol"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how command results are written to stdout.
+type Format string
+
+const (
+	FormatTable Format = "table" // styled, human-readable output (default)
+	FormatPlain Format = "plain" // unstyled, tab-separated output
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+// Formats lists every supported output format.
+var Formats = []Format{FormatTable, FormatPlain, FormatJSON, FormatYAML}
+
+// output is the format used by Print and Spin.
+var output = FormatTable
+
+// ParseFormat validates an --output flag value.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("invalid output format %q (must be one of %s)", s, strings.Join(names, ", "))
+}
+
+// SetFormat sets the format used by Print and Spin.
+func SetFormat(f Format) {
+	output = f
+}
+
+// OutputFormat returns the active output format.
+func OutputFormat() Format {
+	return output
+}
+
+// MachineReadable reports whether the active format is meant for scripts
+// rather than people.
+func MachineReadable() bool {
+	return output == FormatJSON || output == FormatYAML
+}
+
+// Print writes v to stdout in the active output format.
+func Print(v any) error {
+	return Write(os.Stdout, output, v)
+}
+
+// Write renders v in format f and writes it to w followed by a newline.
+func Write(w io.Writer, f Format, v any) error {
+	s, err := Render(f, v)
+	if err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	_, err = fmt.Fprintln(w, strings.TrimRight(s, "\n"))
+	return err
+}
+
+// Render renders a command result in the given format. JSON and YAML emit
+// the result struct itself so field names match the protocol; table and
+// plain render the types known to the CLI.
+func Render(f Format, v any) (string, error) {
+	switch f {
+	case FormatJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode json: %w", err)
+		}
+		return string(data), nil
+	case FormatYAML:
+		return renderYAML(v)
+	case FormatPlain:
+		return renderPlain(v)
+	case FormatTable:
+		return renderTable(v)
+	}
+	return "", fmt.Errorf("unsupported output format %q", f)
+}
+
+// renderYAML encodes v as YAML using its JSON field names, so both
+// machine-readable formats share one schema.
+func renderYAML(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return "", fmt.Errorf("failed to encode yaml: %w", err)
+	}
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(unquoteNumbers(generic)); err != nil {
+		return "", fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	return b.String(), nil
+}
+
+// unquoteNumbers converts json.Number values back to numeric types so the
+// YAML encoder does not emit them as strings.
+func unquoteNumbers(v any) any {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	case map[string]any:
+		for k, e := range t {
+			t[k] = unquoteNumbers(e)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = unquoteNumbers(e)
+		}
+	}
+	return v
+}
+
+// renderTable renders v with the styled renderers used by each command.
+func renderTable(v any) (string, error) {
+	switch t := v.(type) {
+	case *protocol.ListResult:
+		return RenderTable(t), nil
+	case *protocol.ServeResult:
+		return RenderServeResult(t), nil
+	case *protocol.LogsResult:
+		return RenderLogs(t), nil
+	case *protocol.DaemonLogsResult:
+		return RenderDaemonLogs(t), nil
+	case *protocol.PortResult:
+		return RenderPortResult(t), nil
+	case *protocol.MessageResult:
+		return Success(t.Message), nil
+	case *protocol.ErrorResult:
+		return Error(t.Error), nil
+	case []config.ProcessConfig:
+		return RenderConfigTable(t), nil
+	case []config.SettingValue:
+		return RenderSettingsTable(t), nil
+	case *config.SettingValue:
+		return t.Value, nil
+	}
+	return "", fmt.Errorf("no table renderer for %T", v)
+}
+
+// renderPlain renders v without styling, one record per line with
+// tab-separated fields.
+func renderPlain(v any) (string, error) {
+	var b strings.Builder
+	switch t := v.(type) {
+	case *protocol.ListResult:
+		for _, e := range t.Processes {
+			b.WriteString(plainURLRow(e.Name, e.Port, t.IP, t.Hostname))
+		}
+	case *protocol.ServeResult:
+		b.WriteString(plainURLRow(t.Name, t.Port, t.IP, t.Hostname))
+	case *protocol.LogsResult:
+		for _, line := range t.Stdout {
+			b.WriteString("stdout\t" + line + "\n")
+		}
+		for _, line := range t.Stderr {
+			b.WriteString("stderr\t" + line + "\n")
+		}
+	case *protocol.DaemonLogsResult:
+		for _, line := range t.Lines {
+			b.WriteString(line + "\n")
+		}
+	case *protocol.PortResult:
+		for _, o := range t.Owners {
+			fmt.Fprintf(&b, "%d\t%d\t%s\t%s\t%s\t%s\n", t.Port, o.Pid, o.Kind, o.Process, o.Command, o.Dir)
+		}
+	case *protocol.MessageResult:
+		b.WriteString(t.Message)
+	case *protocol.ErrorResult:
+		b.WriteString(t.Error)
+	case []config.ProcessConfig:
+		for _, c := range t {
+			fmt.Fprintf(&b, "%s\t%d\t%s\t%s\n", c.Name, c.Port, c.CommandLine(), c.Directory)
+		}
+	case []config.SettingValue:
+		for _, v := range t {
+			fmt.Fprintf(&b, "%s\t%s\t%s\n", v.Key, v.Value, v.Source)
+		}
+	case *config.SettingValue:
+		b.WriteString(t.Value)
+	default:
+		return "", fmt.Errorf("no plain renderer for %T", v)
+	}
+	return b.String(), nil
+}
+
+// plainURLRow formats a process as name, port and its local, IP and DNS
+// URLs. URLs that cannot be built are left empty.
+func plainURLRow(name string, port int, ip, hostname string) string {
+	ipURL, dnsURL := "", ""
+	if ip != "" {
+		ipURL = fmt.Sprintf("http://%s:%d", ip, port)
+	}
+	if hostname != "" {
+		dnsURL = fmt.Sprintf("https://%s:%d", hostname, port)
+	}
+	return fmt.Sprintf("%s\t%d\thttp://localhost:%d\t%s\t%s\n", name, port, port, ipURL, dnsURL)
+}
@@ -0,0 +1,119 @@
+package cli_test
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/protocol"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestMain(m *testing.M) {
+	// Golden files are recorded without colors regardless of the terminal.
+	lipgloss.SetColorProfile(termenv.Ascii)
+	os.Exit(m.Run())
+}
+
+// outputFixtures covers every result type a command can print.
+var outputFixtures = []struct {
+	name  string
+	value any
+}{
+	{"list", &protocol.ListResult{
+		Processes: []protocol.ListEntry{
+			{Name: "web", Port: 3000, Command: "npm run dev", Dir: "/projects/web", Usage: &protocol.Usage{
+				Time: time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC), CPUPercent: 12.5, RSSBytes: 150 << 20, Threads: 11, FDs: 42, Procs: 3,
+			}},
+			{Name: "api", Port: 8080, Command: "go run .", Dir: "/projects/api"},
+		},
+		Hostname: "host.example.ts.net",
+		IP:       "100.1.2.3",
+	}},
+	{"list_empty", &protocol.ListResult{Processes: []protocol.ListEntry{}}},
+	{"serve", &protocol.ServeResult{Name: "web", Port: 3000, Hostname: "host.example.ts.net", IP: "100.1.2.3"}},
+	{"logs", &protocol.LogsResult{
+		Stdout: []string{"ready in 300ms", "GET / 200"},
+		Stderr: []string{"warning: deprecated option"},
+	}},
+	{"daemon_logs", &protocol.DaemonLogsResult{Lines: []string{"2026/01/02 15:04:05 daemon started"}}},
+	{"port", &protocol.PortResult{Port: 3000, InUse: true, Owners: []protocol.PortOwner{
+		{Pid: 4242, Command: "node server.js", Dir: "/projects/web", Kind: "orphan", Process: "web"},
+		{Pid: 4343, Command: "python3 -m http.server 3000", Dir: "/tmp", Kind: "other"},
+	}}},
+	{"port_free", &protocol.PortResult{Port: 3000}},
+	{"message", &protocol.MessageResult{Name: "web", Message: "process 'web' stopped"}},
+	{"configs", []config.ProcessConfig{
+		{Name: "web", Port: 3000, Command: "npm run dev", Directory: "/projects/web"},
+	}},
+	{"settings", []config.SettingValue{
+		{Key: "tunnel", Value: "none", Source: config.SourceFile, Env: "DEVSERVE_TUNNEL", Help: "tunnel provider"},
+		{Key: "timeouts.start", Value: "2m", Source: config.SourceEnv, Env: "DEVSERVE_TIMEOUTS_START", Help: "start timeout"},
+		{Key: "defaults.shell", Source: config.SourceDefault, Env: "DEVSERVE_DEFAULTS_SHELL", Help: "shell"},
+	}},
+	{"setting", &config.SettingValue{Key: "timeouts.start", Value: "15s", Source: config.SourceDefault, Env: "DEVSERVE_TIMEOUTS_START", Help: "start timeout"}},
+	{"error", &protocol.ErrorResult{Error: "process 'ghost' not found", Kind: protocol.KindNotFound}},
+}
+
+func TestRenderGolden(t *testing.T) {
+	for _, fx := range outputFixtures {
+		for _, f := range cli.Formats {
+			t.Run(fx.name+"/"+string(f), func(t *testing.T) {
+				got, err := cli.Render(f, fx.value)
+				if err != nil {
+					t.Fatalf("Render failed: %v", err)
+				}
+
+				path := filepath.Join("testdata", fx.name+"."+string(f)+".golden")
+				if *update {
+					if err := os.MkdirAll("testdata", 0755); err != nil {
+						t.Fatalf("failed to create testdata: %v", err)
+					}
+					if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+						t.Fatalf("failed to write golden file: %v", err)
+					}
+				}
+
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+				}
+				if got != string(want) {
+					t.Errorf("output mismatch for %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range cli.Formats {
+		got, err := cli.ParseFormat(string(f))
+		if err != nil {
+			t.Errorf("ParseFormat(%q) failed: %v", f, err)
+		}
+		if got != f {
+			t.Errorf("expected %q, got %q", f, got)
+		}
+	}
+
+	if _, err := cli.ParseFormat("xml"); err == nil {
+		t.Error("expected error for unknown format, got nil")
+	}
+}
+
+func TestRenderUnknownType(t *testing.T) {
+	if _, err := cli.Render(cli.FormatTable, 42); err == nil {
+		t.Error("expected error rendering unsupported type as table, got nil")
+	}
+	if _, err := cli.Render(cli.FormatJSON, 42); err != nil {
+		t.Errorf("expected JSON to encode any value, got %v", err)
+	}
+}
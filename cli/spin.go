@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SpinStatus runs fn behind a spinner like Spin, showing the status
+// line fn reports after the title. Ctrl-C cancels fn's context rather
+// than exiting, so fn can clean up; SpinStatus returns fn's error.
+func SpinStatus(title string, fn func(ctx context.Context, status func(string)) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if output != FormatTable {
+		return fn(ctx, func(string) {})
+	}
+
+	m := statusModel{
+		spinner: spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("#F780E2")))),
+		title:   title,
+	}
+	p := tea.NewProgram(m, tea.WithOutput(os.Stderr), tea.WithInput(nil), tea.WithoutSignalHandler())
+	go func() {
+		err := fn(ctx, func(s string) { p.Send(statusMsg(s)) })
+		p.Send(statusDoneMsg{err})
+	}()
+	final, err := p.Run()
+	if err != nil {
+		return err
+	}
+	return final.(statusModel).err
+}
+
+type statusMsg string
+
+type statusDoneMsg struct{ err error }
+
+// statusModel is a spinner with a title and a status line that can be
+// updated while it runs.
+type statusModel struct {
+	spinner spinner.Model
+	title   string
+	status  string
+	width   int
+	done    bool
+	err     error
+}
+
+func (m statusModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case statusMsg:
+		m.status = string(msg)
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+	case statusDoneMsg:
+		m.done = true
+		m.err = msg.err
+		return m, tea.Quit
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+func (m statusModel) View() string {
+	if m.done {
+		return ""
+	}
+	view := m.spinner.View() + m.title
+	if m.status != "" {
+		view += " " + Dim.Render(m.status)
+	}
+	if m.width > 0 {
+		// A wrapped line would break redrawing in place.
+		view = lipgloss.NewStyle().MaxWidth(m.width).Render(view)
+	}
+	return view
+}
@@ -54,8 +54,8 @@ func RenderTable(lr *protocol.ListResult) string {
 
 	// Build table
 	var b strings.Builder
-	header := fmt.Sprintf("%-*s  %-*s  %-5s  %-5s  %-3s",
-		nameWidth, "NAME", portWidth, "PORT", "LOCAL", "IP", "DNS")
+	header := fmt.Sprintf("%-*s  %-*s  %6s  %9s  %-5s  %-5s  %-3s",
+		nameWidth, "NAME", portWidth, "PORT", "CPU", "MEM", "LOCAL", "IP", "DNS")
 	b.WriteString(Bold.Render(header))
 	for _, e := range lr.Processes {
 		localURL := fmt.Sprintf("http://localhost:%d", e.Port)
@@ -72,8 +72,9 @@ func RenderTable(lr *protocol.ListResult) string {
 		ipPad := 5 + len(ipLink) - len("ip")
 
 		b.WriteString("\n")
-		b.WriteString(fmt.Sprintf("%-*s  %-*d  %-*s  %-*s  %s",
+		b.WriteString(fmt.Sprintf("%-*s  %-*d  %6s  %9s  %-*s  %-*s  %s",
 			nameWidth, e.Name, portWidth, e.Port,
+			FormatCPU(e.Usage), FormatMem(e.Usage),
 			localPad, localLink,
 			ipPad, ipLink,
 			dnsLink))
@@ -102,8 +103,8 @@ func RenderConfigTable(configs []config.ProcessConfig) string {
 		if len(p) > portWidth {
 			portWidth = len(p)
 		}
-		if len(c.Command) > cmdWidth {
-			cmdWidth = len(c.Command)
+		if len(c.CommandLine()) > cmdWidth {
+			cmdWidth = len(c.CommandLine())
 		}
 		if len(c.Directory) > dirWidth {
 			dirWidth = len(c.Directory)
@@ -127,7 +128,7 @@ func RenderConfigTable(configs []config.ProcessConfig) string {
 	b.WriteString(Bold.Render(header))
 
 	for _, c := range configs {
-		cmd := c.Command
+		cmd := c.CommandLine()
 		if len(cmd) > maxCmdWidth {
 			cmd = cmd[:maxCmdWidth-3] + "..."
 		}
@@ -144,6 +145,37 @@ func RenderConfigTable(configs []config.ProcessConfig) string {
 	return b.String()
 }
 
+// RenderSettingsTable renders settings as a table of keys, values and
+// where each value comes from. Unset values show as a dash.
+func RenderSettingsTable(values []config.SettingValue) string {
+	keyWidth, valueWidth := len("KEY"), len("VALUE")
+	for _, v := range values {
+		keyWidth = max(keyWidth, len(v.Key))
+		valueWidth = max(valueWidth, len(v.Value))
+	}
+	valueWidth = min(valueWidth, 40)
+
+	var b strings.Builder
+	b.WriteString(Bold.Render(fmt.Sprintf("%-*s  %-*s  %s", keyWidth, "KEY", valueWidth, "VALUE", "SOURCE")))
+	for _, v := range values {
+		value := v.Value
+		if len(value) > valueWidth {
+			value = value[:valueWidth-3] + "..."
+		}
+		padded := fmt.Sprintf("%-*s", valueWidth, value)
+		if value == "" {
+			padded = Dim.Render("-") + strings.Repeat(" ", valueWidth-1)
+		}
+		source := v.Source
+		if source == config.SourceDefault {
+			source = Dim.Render(source)
+		}
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("%-*s  %s  %s", keyWidth, v.Key, padded, source))
+	}
+	return b.String()
+}
+
 // RenderServeResult renders a ServeResult as a styled success message
 // with clickable links for local, IP, and DNS URLs.
 func RenderServeResult(sr *protocol.ServeResult) string {
@@ -170,24 +202,180 @@ func RenderServeResult(sr *protocol.ServeResult) string {
 		b.WriteString(Cyan.Render("dns") + "    " + Hyperlink(dnsURL, dnsURL))
 	}
 
+	if sr.ReadyLine != "" {
+		b.WriteString("\n  ")
+		b.WriteString(Cyan.Render("ready") + "  " + Dim.Render(sr.ReadyLine))
+	}
+
+	return b.String()
+}
+
+// RenderLogs renders stdout and stderr log lines under section headers,
+// with stderr lines in red.
+func RenderLogs(lr *protocol.LogsResult) string {
+	if lr == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(Cyan.Render("─── stdout ───"))
+	b.WriteString("\n")
+	for _, line := range lr.Stdout {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(Cyan.Render("─── stderr ───"))
+	b.WriteString("\n")
+	for _, line := range lr.Stderr {
+		b.WriteString(Red.Render(line))
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// RenderDiagnostics renders why a process failed to start: how it
+// exited, the effective command, directory and environment, and the last
+// lines of its logs with stderr in red.
+func RenderDiagnostics(d *protocol.Diagnostics) string {
+	if d == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	field := func(label, value string) {
+		b.WriteString("  " + Cyan.Render(fmt.Sprintf("%-8s", label)) + " " + value + "\n")
+	}
+	if d.Exit != "" {
+		field("exit", d.Exit)
+	}
+	if d.PortInUse {
+		owner := "another program"
+		if d.PortOwner != "" {
+			owner = d.PortOwner
+		}
+		field("port", Red.Render("in use by "+owner))
+	}
+	field("command", d.Command)
+	field("dir", d.Dir)
+	for i, kv := range d.Env {
+		label := ""
+		if i == 0 {
+			label = "env"
+		}
+		field(label, Dim.Render(kv))
+	}
+
+	for _, l := range []struct {
+		name  string
+		lines []string
+		style lipgloss.Style
+	}{{"stderr", d.Stderr, Red}, {"stdout", d.Stdout, lipgloss.NewStyle()}} {
+		if len(l.lines) == 0 {
+			continue
+		}
+		b.WriteString("\n" + Cyan.Render("─── "+l.name+" ───") + "\n")
+		for _, line := range l.lines {
+			b.WriteString(l.style.Render(line) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// DiagnosticsSummary condenses diagnostics into one line for a status
+// bar: how the process exited, a taken port and its last line of stderr,
+// or stdout if stderr is empty.
+func DiagnosticsSummary(d *protocol.Diagnostics) string {
+	if d == nil {
+		return ""
+	}
+	var parts []string
+	if d.Exit != "" {
+		parts = append(parts, d.Exit)
+	}
+	if d.PortInUse {
+		parts = append(parts, "port in use by another program")
+	}
+	if n := len(d.Stderr); n > 0 {
+		parts = append(parts, d.Stderr[n-1])
+	} else if n := len(d.Stdout); n > 0 {
+		parts = append(parts, d.Stdout[n-1])
+	}
+	return strings.Join(parts, " · ")
+}
+
+// RenderPortResult renders who is listening on a port, one block per
+// listening process.
+func RenderPortResult(pr *protocol.PortResult) string {
+	if pr == nil {
+		return ""
+	}
+	if !pr.InUse {
+		return Info(fmt.Sprintf("port %d is free", pr.Port))
+	}
+	if len(pr.Owners) == 0 {
+		return Info(fmt.Sprintf("port %d is in use by a process of another user", pr.Port))
+	}
+
+	var b strings.Builder
+	b.WriteString(Info(fmt.Sprintf("port %d is in use", pr.Port)))
+	for _, o := range pr.Owners {
+		var kind string
+		switch o.Kind {
+		case "devserve":
+			kind = fmt.Sprintf("devserve process '%s'", o.Process)
+		case "orphan":
+			kind = Red.Render(fmt.Sprintf("orphan of devserve process '%s'", o.Process))
+		default:
+			kind = "unrelated program"
+		}
+		b.WriteString("\n")
+		for _, f := range [][2]string{{"pid", fmt.Sprint(o.Pid)}, {"kind", kind}, {"command", o.Command}, {"dir", o.Dir}} {
+			b.WriteString("\n  " + Cyan.Render(fmt.Sprintf("%-8s", f[0])) + " " + f[1])
+		}
+	}
 	return b.String()
 }
 
+// RenderDaemonLogs renders daemon log lines under a section header.
+func RenderDaemonLogs(dl *protocol.DaemonLogsResult) string {
+	if dl == nil || len(dl.Lines) == 0 {
+		return Info("no daemon logs found")
+	}
+
+	var b strings.Builder
+	b.WriteString(Cyan.Render("─── daemon ───"))
+	b.WriteString("\n")
+	for _, line := range dl.Lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
 // Hyperlink returns an OSC 8 hyperlink that renders as a clickable label in
 // supported terminals.
 func Hyperlink(url, label string) string {
 	return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, label)
 }
 
-// Spin runs fn while displaying a spinner with the given title.
+// Spin runs fn while displaying a spinner with the given title. The spinner
+// is only shown for table output so it never mixes into scripted output.
 func Spin(title string, fn func()) {
+	if output != FormatTable {
+		fn()
+		return
+	}
 	spinner.New().Title(title).Action(fn).Run()
 }
 
 // HelpTemplate returns a styled cobra help template.
 func HelpTemplate() string {
 	title := Bold.Render("{{.Name}}")
-	return title + `{{if .Short}} - {{.Short}}{{end}}
+	return title + `{{if .Long}}
+
+{{.Long | trimTrailingWhitespaces}}{{else if .Short}} - {{.Short}}{{end}}
 
 ` + Cyan.Render("Usage:") + `{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
@@ -156,3 +156,42 @@ func TestHelpTemplate(t *testing.T) {
 		t.Errorf("expected help template to contain %q", "Commands:")
 	}
 }
+
+func TestRenderDiagnostics(t *testing.T) {
+	d := &protocol.Diagnostics{
+		Exit:      "exited with status 1",
+		PortInUse: true,
+		Command:   "sh -c 'exec npm run dev'",
+		Dir:       "/home/me/web",
+		Env:       []string{"PATH=/usr/bin", "HOME=/home/me"},
+		Stdout:    []string{"> next dev"},
+		Stderr:    []string{"Error: listen EADDRINUSE"},
+	}
+	out := cli.RenderDiagnostics(d)
+
+	for _, want := range []string{"exited with status 1", "in use by another program", "exec npm run dev", "/home/me/web", "PATH=/usr/bin", "HOME=/home/me", "stderr", "EADDRINUSE", "stdout", "> next dev"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if strings.Index(out, "EADDRINUSE") > strings.Index(out, "> next dev") {
+		t.Errorf("expected stderr before stdout, got %q", out)
+	}
+}
+
+func TestDiagnosticsSummary(t *testing.T) {
+	cases := []struct {
+		d    *protocol.Diagnostics
+		want string
+	}{
+		{&protocol.Diagnostics{Exit: "exited with status 1", Stderr: []string{"a", "Cannot find module 'next'"}}, "exited with status 1 · Cannot find module 'next'"},
+		{&protocol.Diagnostics{PortInUse: true, Stdout: []string{"starting"}}, "port in use by another program · starting"},
+		{&protocol.Diagnostics{}, ""},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := cli.DiagnosticsSummary(c.d); got != c.want {
+			t.Errorf("DiagnosticsSummary(%+v) = %q, want %q", c.d, got, c.want)
+		}
+	}
+}
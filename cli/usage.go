@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"github.com/jaiir320/devserve/protocol"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sparkBlocks are the glyphs used by Sparkline, lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders the last width values as a row of block glyphs scaled
+// to the largest value shown.
+func Sparkline(values []float64, width int) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if max > 0 && v > 0 {
+			i = int(v / max * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// FormatBytes formats a byte count with a binary unit suffix.
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatCPU formats a CPU percentage, or "-" when no sample exists.
+func FormatCPU(u *protocol.Usage) string {
+	if u == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", u.CPUPercent)
+}
+
+// FormatMem formats resident memory, or "-" when no sample exists.
+func FormatMem(u *protocol.Usage) string {
+	if u == nil {
+		return "-"
+	}
+	return FormatBytes(u.RSSBytes)
+}
+
+// CPUHistory extracts the CPU percentages from a usage history.
+func CPUHistory(history []protocol.Usage) []float64 {
+	out := make([]float64, len(history))
+	for i, u := range history {
+		out[i] = u.CPUPercent
+	}
+	return out
+}
+
+// MemHistory extracts resident memory from a usage history.
+func MemHistory(history []protocol.Usage) []float64 {
+	out := make([]float64, len(history))
+	for i, u := range history {
+		out[i] = float64(u.RSSBytes)
+	}
+	return out
+}
+
+// topSparkWidth is the number of samples shown in the top view's sparkline.
+const topSparkWidth = 20
+
+// RenderTop renders a process list as a resource usage table sorted by
+// CPU usage, heaviest first.
+func RenderTop(lr *protocol.ListResult) string {
+	if lr == nil || len(lr.Processes) == 0 {
+		return Dim.Render("No active processes")
+	}
+
+	entries := append([]protocol.ListEntry(nil), lr.Processes...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return cpuOf(entries[i]) > cpuOf(entries[j])
+	})
+
+	nameWidth := 4 // "NAME"
+	for _, e := range entries {
+		if len(e.Name) > nameWidth {
+			nameWidth = len(e.Name)
+		}
+	}
+
+	var b strings.Builder
+	header := fmt.Sprintf("%-*s  %-5s  %6s  %9s  %7s  %5s  %s",
+		nameWidth, "NAME", "PORT", "CPU", "MEM", "THREADS", "FDS", "CPU HISTORY")
+	b.WriteString(Bold.Render(header))
+	for _, e := range entries {
+		threads, fds := "-", "-"
+		if e.Usage != nil {
+			threads = fmt.Sprintf("%d", e.Usage.Threads)
+			fds = fmt.Sprintf("%d", e.Usage.FDs)
+		}
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("%-*s  %-5d  %6s  %9s  %7s  %5s  %s",
+			nameWidth, e.Name, e.Port,
+			FormatCPU(e.Usage), FormatMem(e.Usage), threads, fds,
+			Green.Render(Sparkline(CPUHistory(e.History), topSparkWidth))))
+	}
+	return b.String()
+}
+
+func cpuOf(e protocol.ListEntry) float64 {
+	if e.Usage == nil {
+		return 0
+	}
+	return e.Usage.CPUPercent
+}
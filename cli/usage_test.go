@@ -0,0 +1,82 @@
+package cli_test
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/protocol"
+	"strings"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	got := cli.Sparkline([]float64{0, 50, 100}, 10)
+	if got != "▁▄█" {
+		t.Errorf("expected %q, got %q", "▁▄█", got)
+	}
+
+	// Only the most recent width values are shown.
+	got = cli.Sparkline([]float64{100, 0, 0}, 2)
+	if got != "▁▁" {
+		t.Errorf("expected %q, got %q", "▁▁", got)
+	}
+
+	if got := cli.Sparkline(nil, 10); got != "" {
+		t.Errorf("expected empty sparkline for no values, got %q", got)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[uint64]string{
+		512:               "512B",
+		1024:              "1.0KiB",
+		1536:              "1.5KiB",
+		200 * 1024 * 1024: "200.0MiB",
+		3 << 30:           "3.0GiB",
+	}
+	for n, want := range cases {
+		if got := cli.FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d): expected %q, got %q", n, want, got)
+		}
+	}
+}
+
+func TestFormatUsageMissing(t *testing.T) {
+	if got := cli.FormatCPU(nil); got != "-" {
+		t.Errorf("expected '-' for missing CPU, got %q", got)
+	}
+	if got := cli.FormatMem(nil); got != "-" {
+		t.Errorf("expected '-' for missing memory, got %q", got)
+	}
+}
+
+func TestRenderTopSortsByCPU(t *testing.T) {
+	lr := &protocol.ListResult{
+		Processes: []protocol.ListEntry{
+			{Name: "idle", Port: 3000, Usage: &protocol.Usage{CPUPercent: 1}},
+			{Name: "busy", Port: 4000, Usage: &protocol.Usage{CPUPercent: 90, RSSBytes: 1 << 20, Threads: 8, FDs: 30}},
+			{Name: "new", Port: 5000},
+		},
+	}
+
+	out := cli.RenderTop(lr)
+	busy := strings.Index(out, "busy")
+	idle := strings.Index(out, "idle")
+	fresh := strings.Index(out, "new")
+	if busy < 0 || idle < 0 || fresh < 0 {
+		t.Fatalf("expected all processes in output, got %q", out)
+	}
+	if !(busy < idle && idle < fresh) {
+		t.Errorf("expected rows ordered busy, idle, new; got %q", out)
+	}
+	for _, want := range []string{"90.0%", "1.0MiB", "THREADS", "FDS"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestRenderTopEmpty(t *testing.T) {
+	out := cli.RenderTop(&protocol.ListResult{})
+	if !strings.Contains(out, "No active processes") {
+		t.Errorf("expected empty message, got %q", out)
+	}
+}
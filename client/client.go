@@ -3,6 +3,7 @@ package client
 import (
 	"github.com/jaiir320/devserve/config"
 	"github.com/jaiir320/devserve/protocol"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,20 +29,59 @@ func Send(req *protocol.Request) (*protocol.Response, error) {
 	return protocol.ReadResponse(conn)
 }
 
+// sendContext sends a request and waits for its final response, passing
+// progress updates to progress. Cancelling ctx closes the connection.
+func sendContext(ctx context.Context, req *protocol.Request, progress func(protocol.Progress)) (*protocol.Response, error) {
+	conn, err := net.Dial("unix", config.Socket)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDaemonNotRunning, err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	err = protocol.SendRequest(conn, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	resp, err := protocol.ReadFinalResponse(conn, progress)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return resp, err
+}
+
 // Serve starts a new process with the given configuration.
 // It auto-starts the daemon if it's not running.
-func Serve(name string, port int, command, dir string) (*protocol.ServeResult, error) {
+func Serve(cfg config.ProcessConfig) (*protocol.ServeResult, error) {
+	return ServeContext(context.Background(), cfg, ServeOptions{})
+}
+
+// ServeOptions are settings for a single serve request rather than part
+// of the process's config.
+type ServeOptions struct {
+	Progress     func(protocol.Progress) // called while the process starts
+	KillExisting bool                    // stop whatever is listening on the port first
+}
+
+// ServeContext is Serve with options. Cancelling ctx disconnects from the
+// daemon, which then aborts the start and cleans up the process.
+func ServeContext(ctx context.Context, cfg config.ProcessConfig, opts ServeOptions) (*protocol.ServeResult, error) {
+	args := serveArgs(cfg)
+	if opts.Progress != nil {
+		args["progress"] = true
+	}
+	if opts.KillExisting {
+		args["kill_existing"] = true
+	}
+	progress := opts.Progress
 	req := &protocol.Request{
 		Action: "serve",
-		Args: map[string]any{
-			"name":    name,
-			"port":    port,
-			"command": command,
-			"cwd":     dir,
-		},
+		Args:   args,
 	}
 
-	resp, err := Send(req)
+	resp, err := sendContext(ctx, req, progress)
 	if err != nil {
 		if errors.Is(err, ErrDaemonNotRunning) {
 			// Auto-start the daemon
@@ -49,7 +89,7 @@ func Serve(name string, port int, command, dir string) (*protocol.ServeResult, e
 				return nil, fmt.Errorf("failed to auto-start daemon: %w", startErr)
 			}
 			// Retry the request
-			resp, err = Send(req)
+			resp, err = sendContext(ctx, req, progress)
 		}
 		if err != nil {
 			return nil, err
@@ -57,7 +97,7 @@ func Serve(name string, port int, command, dir string) (*protocol.ServeResult, e
 	}
 
 	if !resp.OK {
-		return nil, errors.New(resp.Error)
+		return nil, resp.Err()
 	}
 
 	var result protocol.ServeResult
@@ -68,6 +108,99 @@ func Serve(name string, port int, command, dir string) (*protocol.ServeResult, e
 	return &result, nil
 }
 
+// serveArgs converts a process config into serve request arguments,
+// leaving out unset optional settings.
+func serveArgs(cfg config.ProcessConfig) map[string]any {
+	args := map[string]any{
+		"name": cfg.Name,
+		"port": cfg.Port,
+		"cwd":  cfg.Directory,
+	}
+	if len(cfg.Args) > 0 {
+		args["args"] = cfg.Args
+	} else {
+		args["command"] = cfg.Command
+	}
+	if cfg.Shell != "" {
+		args["shell"] = cfg.Shell
+	}
+	if cfg.TTY {
+		args["tty"] = true
+	}
+	if cfg.StripANSI {
+		args["strip_ansi"] = true
+	}
+	if cfg.MemoryMax != "" {
+		args["memory_max"] = cfg.MemoryMax
+	}
+	if cfg.CPUQuota != "" {
+		args["cpu_quota"] = cfg.CPUQuota
+	}
+	if cfg.PidsMax != 0 {
+		args["pids_max"] = cfg.PidsMax
+	}
+	if cfg.StopSignal != "" {
+		args["stop_signal"] = cfg.StopSignal
+	}
+	if cfg.StopTimeout != "" {
+		args["stop_timeout"] = cfg.StopTimeout
+	}
+	if cfg.PreStop != "" {
+		args["pre_stop"] = cfg.PreStop
+	}
+	if cfg.PreStart != "" {
+		args["pre_start"] = cfg.PreStart
+	}
+	if cfg.PostReady != "" {
+		args["post_ready"] = cfg.PostReady
+	}
+	if cfg.PostStop != "" {
+		args["post_stop"] = cfg.PostStop
+	}
+	if cfg.ReadyWhenLogMatches != "" {
+		args["ready_when_log_matches"] = cfg.ReadyWhenLogMatches
+	}
+	if cfg.StartTimeout != "" {
+		args["start_timeout"] = cfg.StartTimeout
+	}
+	if cfg.Host != "" {
+		args["host"] = cfg.Host
+	}
+	if len(cfg.Env) > 0 {
+		args["env"] = cfg.Env
+	}
+	if len(cfg.Tags) > 0 {
+		args["tags"] = cfg.Tags
+	}
+	return args
+}
+
+// Port reports which processes are listening on port, checking host, or
+// loopback when host is "".
+func Port(port int, host string) (*protocol.PortResult, error) {
+	req := &protocol.Request{
+		Action: "port",
+		Args:   map[string]any{"port": port},
+	}
+	if host != "" {
+		req.Args["host"] = host
+	}
+
+	resp, err := Send(req)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.OK {
+		return nil, resp.Err()
+	}
+
+	var result protocol.PortResult
+	if err := json.Unmarshal([]byte(resp.Data), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse port response: %w", err)
+	}
+	return &result, nil
+}
+
 // Stop stops a running process.
 func Stop(name string) error {
 	req := &protocol.Request{
@@ -83,7 +216,112 @@ func Stop(name string) error {
 	}
 
 	if !resp.OK {
-		return errors.New(resp.Error)
+		return resp.Err()
+	}
+
+	return nil
+}
+
+// SendInput writes text to a process's terminal or stdin, followed by an
+// Enter key press when enter is set.
+func SendInput(name, text string, enter bool) error {
+	req := &protocol.Request{
+		Action: "send",
+		Args: map[string]any{
+			"name":  name,
+			"text":  text,
+			"enter": enter,
+		},
+	}
+
+	resp, err := Send(req)
+	if err != nil {
+		return err
+	}
+
+	if !resp.OK {
+		return resp.Err()
+	}
+
+	return nil
+}
+
+// Attach connects to a process's input and output. On success the
+// returned connection carries raw bytes both ways: reads return the
+// process's output, writes go to its terminal or stdin. Closing the
+// connection detaches.
+func Attach(name string) (net.Conn, error) {
+	conn, err := net.Dial("unix", config.Socket)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDaemonNotRunning, err)
+	}
+
+	req := &protocol.Request{
+		Action: "attach",
+		Args: map[string]any{
+			"name": name,
+		},
+	}
+	if err := protocol.SendRequest(conn, req); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	resp, err := protocol.ReadResponseUnbuffered(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !resp.OK {
+		conn.Close()
+		return nil, resp.Err()
+	}
+	return conn, nil
+}
+
+// Signal sends a signal, given by name or number, to a process or, with
+// group set, to its whole process group. It returns the daemon's
+// confirmation message.
+func Signal(name, signal string, group bool) (string, error) {
+	req := &protocol.Request{
+		Action: "signal",
+		Args: map[string]any{
+			"name":   name,
+			"signal": signal,
+			"group":  group,
+		},
+	}
+
+	resp, err := Send(req)
+	if err != nil {
+		return "", err
+	}
+
+	if !resp.OK {
+		return "", resp.Err()
+	}
+
+	return resp.Data, nil
+}
+
+// Resize sets the terminal size of a process running with a TTY.
+func Resize(name string, rows, cols int) error {
+	req := &protocol.Request{
+		Action: "resize",
+		Args: map[string]any{
+			"name": name,
+			"rows": rows,
+			"cols": cols,
+		},
+	}
+
+	resp, err := Send(req)
+	if err != nil {
+		return err
+	}
+
+	if !resp.OK {
+		return resp.Err()
 	}
 
 	return nil
@@ -91,9 +329,22 @@ func Stop(name string) error {
 
 // List returns all running processes and Tailscale info.
 func List() (*protocol.ListResult, error) {
+	return list(false)
+}
+
+// ListWithHistory is like List but also returns each process's resource
+// usage history.
+func ListWithHistory() (*protocol.ListResult, error) {
+	return list(true)
+}
+
+func list(history bool) (*protocol.ListResult, error) {
 	req := &protocol.Request{
 		Action: "list",
 	}
+	if history {
+		req.Args = map[string]any{"history": true}
+	}
 
 	resp, err := Send(req)
 	if err != nil {
@@ -101,7 +352,7 @@ func List() (*protocol.ListResult, error) {
 	}
 
 	if !resp.OK {
-		return nil, errors.New(resp.Error)
+		return nil, resp.Err()
 	}
 
 	var result protocol.ListResult
@@ -127,7 +378,7 @@ func Get(name string) (*protocol.ProcessInfo, error) {
 	}
 
 	if !resp.OK {
-		return nil, errors.New(resp.Error)
+		return nil, resp.Err()
 	}
 
 	var result protocol.ProcessInfo
@@ -154,7 +405,7 @@ func Logs(name string, lines int) (*protocol.LogsResult, error) {
 	}
 
 	if !resp.OK {
-		return nil, errors.New(resp.Error)
+		return nil, resp.Err()
 	}
 
 	var result protocol.LogsResult
@@ -177,7 +428,7 @@ func Ping() error {
 	}
 
 	if !resp.OK {
-		return errors.New(resp.Error)
+		return resp.Err()
 	}
 
 	return nil
@@ -195,7 +446,7 @@ func Shutdown() (string, error) {
 	}
 
 	if !resp.OK {
-		return "", errors.New(resp.Error)
+		return "", resp.Err()
 	}
 
 	return resp.Data, nil
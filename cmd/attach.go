@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// detachKeys end an attach session: ctrl-p followed by ctrl-q.
+var detachKeys = []byte{0x10, 0x11}
+
+var attachCmd = &cobra.Command{
+	Use:   "attach [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Attach to a running process's terminal or stdin",
+	Long: `Attach to a running process: its output is streamed live and your
+keystrokes are sent to its terminal (for processes started with --tty) or
+stdin. Several clients can attach to the same process at once.
+
+Detach with ctrl-p ctrl-q; the process keeps running.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAttach(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+}
+
+func runAttach(name string) error {
+	conn, err := client.Attach(name)
+	if err != nil {
+		return fmt.Errorf("failed to attach: %w", err)
+	}
+	defer conn.Close()
+
+	stdin := os.Stdin.Fd()
+	if term.IsTerminal(stdin) {
+		state, err := term.MakeRaw(stdin)
+		if err != nil {
+			return fmt.Errorf("failed to set up terminal: %w", err)
+		}
+		defer term.Restore(stdin, state)
+		fmt.Fprintf(os.Stderr, "%s\r\n", cli.Info(fmt.Sprintf("attached to '%s', detach with ctrl-p ctrl-q", name)))
+
+		stopResize := forwardResize(name)
+		defer stopResize()
+	}
+
+	detached := make(chan struct{})
+	go copyInput(conn, os.Stdin, detached)
+	io.Copy(os.Stdout, conn)
+
+	select {
+	case <-detached:
+		fmt.Fprintf(os.Stderr, "\r\n%s\r\n", cli.Info(fmt.Sprintf("detached from '%s'", name)))
+	default:
+		fmt.Fprintf(os.Stderr, "\r\n%s\r\n", cli.Info(fmt.Sprintf("'%s' exited", name)))
+	}
+	return nil
+}
+
+// copyInput forwards stdin to the connection until the detach keys are
+// pressed, which closes detached and the connection. At end of input,
+// output keeps streaming; the daemon treats a closed connection as a
+// detach, so the write side is left open.
+func copyInput(conn net.Conn, r io.Reader, detached chan<- struct{}) {
+	buf := make([]byte, 1024)
+	matched := 0
+	for {
+		n, err := r.Read(buf)
+		var out bytes.Buffer
+		for _, c := range buf[:n] {
+			if c == detachKeys[matched] {
+				matched++
+				if matched == len(detachKeys) {
+					close(detached)
+					conn.Close()
+					return
+				}
+				continue
+			}
+			// A partial match turned out to be input.
+			out.Write(detachKeys[:matched])
+			matched = 0
+			if c == detachKeys[0] {
+				matched = 1
+				continue
+			}
+			out.WriteByte(c)
+		}
+		if out.Len() > 0 {
+			if _, werr := conn.Write(out.Bytes()); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// forwardResize keeps the process's terminal the size of ours. Processes
+// without a TTY reject resizes, which is ignored.
+func forwardResize(name string) (stop func()) {
+	resize := func() {
+		if cols, rows, err := term.GetSize(os.Stdout.Fd()); err == nil {
+			client.Resize(name, rows, cols)
+		}
+	}
+	resize()
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-winch:
+				resize()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(winch)
+		close(done)
+	}
+}
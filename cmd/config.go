@@ -4,14 +4,43 @@ import (
 	"github.com/jaiir320/devserve/cli"
 	"github.com/jaiir320/devserve/client"
 	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/protocol"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var configCmd = &cobra.Command{
 	Use:   "config",
-	Short: "Manage saved process configurations",
+	Short: "Manage saved process configurations and settings",
+	Long: `Manage saved process configurations and settings.
+
+Saved processes and their defaults are kept in
+~/.config/devserve/config.json, devserve's own settings in
+~/.config/devserve/settings.toml. Use get, set and unset to change either.
+
+Settings are resolved in this order, later ones winning:
+  1. built-in values
+  2. the settings file
+  3. environment variables: DEVSERVE_ and the key in upper case with dots
+     as underscores, e.g. DEVSERVE_TIMEOUTS_START=1m for timeouts.start
+
+A process's settings are resolved in this order, later ones winning:
+  1. built-in values, such as the timeouts.* settings
+  2. defaults.* keys, stored in the config file
+  3. DEVSERVE_DEFAULTS_* environment variables, e.g. DEVSERVE_DEFAULTS_SHELL
+  4. the process's own saved config, or flags to devserve serve
+
+The daemon reads the settings when it starts; stop it with devserve daemon
+stop to apply changes.`,
 }
 
 var configListCmd = &cobra.Command{
@@ -22,8 +51,7 @@ var configListCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to load configs: %w", err)
 		}
-		fmt.Println(cli.RenderConfigTable(configs))
-		return nil
+		return cli.Print(configs)
 	},
 }
 
@@ -46,15 +74,166 @@ var configDeleteCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to delete config: %w", err)
 		}
-		fmt.Println(cli.Success(fmt.Sprintf("config '%s' deleted", name)))
-		return nil
+		return cli.Print(&protocol.MessageResult{
+			Name:    name,
+			Message: fmt.Sprintf("config '%s' deleted", name),
+		})
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Check a config file for problems",
+	Long: `Check a config file, by default the saved configurations, for problems:
+unknown fields, missing or invalid settings, duplicate names and ports,
+and directories that do not exist. The file is not changed, even when it
+is in the old layout that devserve migrates on first use.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := config.ConfigFile
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return runConfigValidate(path)
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Show settings, or the value of one",
+	Long: `Show every setting with its value and where it comes from (default,
+file, config or env), or print the value of one.
+
+Settings:
+` + config.SettingsHelp(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			values, err := config.Settings(config.ConfigFile)
+			if err != nil {
+				return err
+			}
+			return cli.Print(values)
+		}
+		value, err := config.GetSetting(config.ConfigFile, args[0])
+		if err != nil {
+			return err
+		}
+		return cli.Print(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Args:  cobra.ExactArgs(2),
+	Short: "Change a setting",
+	Long: `Change a setting in the settings file, or a process default
+(defaults.*) in the config file. Environment variables still override it.
+
+  devserve config set timeouts.start 1m
+  devserve config set defaults.shell bash
+  devserve config set defaults.env.NODE_ENV development
+
+Settings:
+` + config.SettingsHelp(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+		if err := config.SetSetting(config.ConfigFile, key, value); err != nil {
+			return err
+		}
+		return cli.Print(&protocol.MessageResult{Message: fmt.Sprintf("%s set to %s", key, value)})
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset [key]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Return a setting to its default",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		if err := config.UnsetSetting(config.ConfigFile, key); err != nil {
+			return err
+		}
+		return cli.Print(&protocol.MessageResult{Message: fmt.Sprintf("%s unset", key)})
+	},
+}
+
+var configExportFlags struct {
+	file        string
+	root        string
+	running     bool
+	redact      []string
+	keepSecrets bool
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export [name...]",
+	Short: "Write configs to share with another machine",
+	Long: `Write saved configs, or all of them, as a file that devserve config
+import reads on another machine or a teammate's.
+
+Directories under the project root, by default the current directory,
+are written relative to it. The config file's defaults are applied to each
+process so the export stands on its own. Environment values whose names
+look secret (tokens, keys, passwords...) and passwords in URLs are
+replaced with ` + config.RedactedValue + `; --redact names more variables, and
+--keep-secrets exports every value as it is.
+
+  devserve config export --file devserve.json
+  devserve config export web api --running > team.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigExport(args)
+	},
+}
+
+var configImportFlags struct {
+	root     string
+	strategy string
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Save the configs in an exported file",
+	Long: `Save the configs in a file written by devserve config export, or -
+for standard input.
+
+Relative directories are resolved against --root, by default the
+directory the file is in, or the current directory for standard input.
+Redacted environment values are taken from the saved config of the same
+name where it has them, and left unset otherwise.
+
+--strategy says what to do with names already saved:
+  fail        import nothing (the default)
+  skip        keep the saved config
+  merge       update the saved config with the fields the import sets
+  overwrite   replace the saved config
+
+To save the processes a project's Procfile, package.json or compose file
+describes, use devserve import instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigImport(args[0])
 	},
 }
 
 func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
 	configCmd.AddCommand(configListCmd)
 	configCmd.AddCommand(configSaveCmd)
 	configCmd.AddCommand(configDeleteCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+
+	configExportCmd.Flags().StringVarP(&configExportFlags.file, "file", "f", "", "write to a file instead of standard output")
+	configExportCmd.Flags().StringVar(&configExportFlags.root, "root", "", "project root that directories are made relative to (default: the current directory)")
+	configExportCmd.Flags().BoolVar(&configExportFlags.running, "running", false, "export the running processes instead of the saved configs")
+	configExportCmd.Flags().StringArrayVar(&configExportFlags.redact, "redact", nil, "redact an environment variable's value; repeatable")
+	configExportCmd.Flags().BoolVar(&configExportFlags.keepSecrets, "keep-secrets", false, "export environment values without redacting them")
+	configImportCmd.Flags().StringVar(&configImportFlags.root, "root", "", "directory relative directories are resolved against (default: the file's directory)")
+	configImportCmd.Flags().StringVar(&configImportFlags.strategy, "strategy", string(config.ImportFail), "what to do with names already saved: fail, skip, merge or overwrite")
 	rootCmd.AddCommand(configCmd)
 }
 
@@ -65,18 +244,212 @@ func runConfigSave(name string) error {
 		return fmt.Errorf("failed to query process: %w", err)
 	}
 
-	// Extract process details
-	cfg := config.ProcessConfig{
-		Name:      name,
-		Port:      info.Port,
-		Command:   info.Command,
-		Directory: info.Dir,
+	if err := config.SaveProcessInfo(config.ConfigFile, info); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	if err := config.SaveConfig(config.ConfigFile, cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	return cli.Print(&protocol.MessageResult{
+		Name:    name,
+		Message: fmt.Sprintf("config '%s' saved", name),
+	})
+}
+
+func runConfigValidate(path string) error {
+	doc, legacy, err := config.ReadDocument(path)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println(cli.Success(fmt.Sprintf("config '%s' saved", name)))
-	return nil
+	if problems := config.ValidateDocument(doc); len(problems) > 0 {
+		lines := make([]string, len(problems))
+		for i, p := range problems {
+			lines[i] = "  " + p.Error()
+		}
+		return protocol.WithKind(fmt.Errorf("%s has %d %s:\n%s", path, len(problems), plural(len(problems), "problem", "problems"), strings.Join(lines, "\n")), protocol.ErrInvalid)
+	}
+
+	msg := fmt.Sprintf("%s is valid (%d %s)", path, len(doc.Processes), plural(len(doc.Processes), "process", "processes"))
+	if legacy {
+		msg += fmt.Sprintf("; it uses the version 1 layout, which devserve migrates to version %d on first use", config.ConfigVersion)
+	}
+	return cli.Print(&protocol.MessageResult{Message: msg})
+}
+
+func runConfigExport(names []string) error {
+	root := configExportFlags.root
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	var configs []config.ProcessConfig
+	var defaults config.Defaults
+	if configExportFlags.running {
+		// Running processes were started with the defaults applied.
+		if configs, err = runningConfigs(); err != nil {
+			return err
+		}
+	} else {
+		doc, err := config.LoadDocument(config.ConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to load configs: %w", err)
+		}
+		configs, defaults = doc.Processes, doc.Defaults
+	}
+	if len(names) > 0 {
+		selected := make([]config.ProcessConfig, 0, len(names))
+		for _, name := range names {
+			i := slices.IndexFunc(configs, func(c config.ProcessConfig) bool { return c.Name == name })
+			if i < 0 {
+				return protocol.WithKind(fmt.Errorf("config '%s' not found", name), protocol.ErrNotFound)
+			}
+			selected = append(selected, configs[i])
+		}
+		configs = selected
+	}
+
+	doc, report := config.Export(configs, defaults, config.ExportOptions{
+		Root:        root,
+		Redact:      configExportFlags.redact,
+		KeepSecrets: configExportFlags.keepSecrets,
+	})
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false) // keep <redacted> readable
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to marshal configs: %w", err)
+	}
+	data := buf.Bytes()
+
+	var notes []string
+	if len(report.Redacted) > 0 {
+		notes = append(notes, "redacted "+strings.Join(report.Redacted, ", "))
+	}
+	if len(report.Outside) > 0 {
+		notes = append(notes, fmt.Sprintf("kept absolute directories outside %s: %s", root, strings.Join(report.Outside, ", ")))
+	}
+
+	if configExportFlags.file == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return err
+		}
+		for _, note := range notes {
+			fmt.Fprintln(os.Stderr, cli.Info(note))
+		}
+		return nil
+	}
+	perm := os.FileMode(0644)
+	if configExportFlags.keepSecrets {
+		perm = config.ConfigFilePermissions
+	}
+	if err := os.WriteFile(configExportFlags.file, data, perm); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	msg := fmt.Sprintf("exported %d %s to %s", len(doc.Processes), plural(len(doc.Processes), "process", "processes"), configExportFlags.file)
+	return cli.Print(&protocol.MessageResult{Message: strings.Join(append([]string{msg}, notes...), "; ")})
+}
+
+// runningConfigs returns the configs of the processes running in the
+// daemon.
+func runningConfigs() ([]config.ProcessConfig, error) {
+	list, err := client.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	var configs []config.ProcessConfig
+	for _, entry := range list.Processes {
+		if entry.ExitReason != "" {
+			continue
+		}
+		info, err := client.Get(entry.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query process: %w", err)
+		}
+		configs = append(configs, config.FromProcessInfo(info))
+	}
+	return configs, nil
+}
+
+func runConfigImport(file string) error {
+	strategy, err := config.ParseImportStrategy(configImportFlags.strategy)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	root := configImportFlags.root
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+		file = "standard input"
+	} else {
+		data, err = os.ReadFile(file)
+		if root == "" {
+			root = filepath.Dir(file)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to read import: %w", err)
+		if errors.Is(err, os.ErrNotExist) {
+			return protocol.WithKind(err, protocol.ErrNotFound)
+		}
+		return err
+	}
+	if root == "" {
+		root = "."
+	}
+	if root, err = filepath.Abs(root); err != nil {
+		return err
+	}
+
+	doc, err := config.ParseExport(file, data)
+	if err != nil {
+		return err
+	}
+	report, err := config.ImportConfigs(config.ConfigFile, doc, root, strategy)
+	if errors.Is(err, protocol.ErrConflict) {
+		return fmt.Errorf("%w; pass --strategy skip, merge or overwrite to import anyway", err)
+	}
+	if err != nil {
+		return err
+	}
+	return cli.Print(&protocol.MessageResult{Message: importSummary(report)})
+}
+
+// importSummary describes what config import did.
+func importSummary(r *config.ImportReport) string {
+	var parts []string
+	for _, p := range []struct {
+		verb  string
+		names []string
+	}{
+		{"added", r.Added},
+		{"merged", r.Merged},
+		{"replaced", r.Replaced},
+		{"skipped", r.Skipped},
+	} {
+		if len(p.names) > 0 {
+			parts = append(parts, p.verb+" "+strings.Join(p.names, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "nothing to import")
+	}
+	if len(r.Missing) > 0 {
+		parts = append(parts, "left redacted variables unset: "+strings.Join(r.Missing, ", "))
+	}
+	if len(r.NoDir) > 0 {
+		parts = append(parts, "no directory yet for "+strings.Join(r.NoDir, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
 }
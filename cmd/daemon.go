@@ -5,6 +5,7 @@ import (
 	"github.com/jaiir320/devserve/client"
 	"github.com/jaiir320/devserve/config"
 	"github.com/jaiir320/devserve/daemon"
+	"github.com/jaiir320/devserve/protocol"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -36,8 +37,9 @@ var daemonCmdStart = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		fmt.Println(cli.Success("daemon started") + " " + cli.Info("logs: "+filepath.Join(config.DaemonDir, config.DaemonLogFile)))
-		return nil
+		return cli.Print(&protocol.MessageResult{
+			Message: "daemon started, logs: " + filepath.Join(config.DaemonDir, config.DaemonLogFile),
+		})
 	},
 }
 
@@ -56,8 +58,7 @@ var daemonCmdStop = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		fmt.Println(cli.Success(msg))
-		return nil
+		return cli.Print(&protocol.MessageResult{Message: msg})
 	},
 }
 
@@ -69,35 +70,26 @@ var daemonCmdLogs = &cobra.Command{
 		lines, _ := cmd.Flags().GetInt("lines")
 		path := filepath.Join(config.DaemonDir, config.DaemonLogFile)
 
+		result := &protocol.DaemonLogsResult{Lines: []string{}}
 		data, err := os.ReadFile(path)
 		if err != nil {
 			if os.IsNotExist(err) {
-				fmt.Println(cli.Info("no daemon logs found"))
-				return nil
+				return cli.Print(result)
 			}
 			return fmt.Errorf("failed to read daemon log: %w", err)
 		}
 
 		content := strings.TrimRight(string(data), "\n")
 		if content == "" {
-			fmt.Println(cli.Info("no daemon logs found"))
-			return nil
+			return cli.Print(result)
 		}
 
 		logLines := strings.Split(content, "\n")
 		if len(logLines) > lines {
 			logLines = logLines[len(logLines)-lines:]
 		}
-
-		var b strings.Builder
-		b.WriteString(cli.Cyan.Render("─── daemon ───"))
-		b.WriteString("\n")
-		for _, line := range logLines {
-			b.WriteString(line)
-			b.WriteString("\n")
-		}
-		fmt.Print(b.String())
-		return nil
+		result.Lines = logLines
+		return cli.Print(result)
 	},
 }
 
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/importer"
+	"github.com/jaiir320/devserve/protocol"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [dir]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Save processes found in a Procfile, package.json or compose file",
+	Long: `Find the processes a project already describes and save them as
+devserve configs. The directory, by default the current one, is searched
+for:
+
+  Procfile, Procfile.dev    one process per entry
+  package.json              scripts that run a server: dev, start, serve,
+                            preview, storybook, dev:* and serve:*
+  compose.yaml and others   services that publish a port, run with
+                            docker compose up
+
+Ports are taken from PORT=N assignments and --port or -p flags, the
+defaults of tools like vite and next, or a compose service's ports. You
+pick the processes to save and give the ports that could not be found.
+Names already saved are left alone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		yes, _ := cmd.Flags().GetBool("yes")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		return runImport(dir, yes, dryRun)
+	},
+}
+
+func init() {
+	importCmd.Flags().BoolP("yes", "y", false, "save every process with a free port without asking")
+	importCmd.Flags().Bool("dry-run", false, "show the processes found without saving them")
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(dir string, yes, dryRun bool) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	candidates, err := importer.Detect(dir)
+	if errors.Is(err, importer.ErrNothingFound) {
+		return protocol.WithKind(fmt.Errorf("%s: %w", dir, err), protocol.ErrNotFound)
+	}
+	if err != nil {
+		return protocol.WithKind(fmt.Errorf("failed to import: %w", err), protocol.ErrInvalid)
+	}
+	existing, err := config.LoadConfigs(config.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configs: %w", err)
+	}
+
+	var skipped []string
+	saved := make(map[string]bool, len(existing))
+	for _, c := range existing {
+		saved[c.Name] = true
+	}
+	proposed := make(map[string]string)
+	var proposals []importer.Candidate
+	for _, c := range candidates {
+		if saved[c.Config.Name] {
+			skipped = append(skipped, fmt.Sprintf("%s (already saved)", c.Config.Name))
+			continue
+		}
+		if source, ok := proposed[c.Config.Name]; ok {
+			skipped = append(skipped, fmt.Sprintf("%s from %s (also in %s)", c.Config.Name, c.Source, source))
+			continue
+		}
+		proposed[c.Config.Name] = c.Source
+		proposals = append(proposals, c)
+	}
+
+	if dryRun {
+		configs := make([]config.ProcessConfig, len(proposals))
+		for i, c := range proposals {
+			configs[i] = c.Config
+		}
+		return cli.Print(configs)
+	}
+
+	var chosen []importer.Candidate
+	if yes {
+		chosen, skipped = chooseImports(proposals, existing, skipped)
+	} else {
+		if !term.IsTerminal(os.Stdin.Fd()) {
+			return protocol.WithKind(errors.New("import asks which processes to save; run it in a terminal or pass --yes"), protocol.ErrInvalid)
+		}
+		if chosen, err = confirmImports(proposals, existing); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, len(chosen))
+	configs := make([]config.ProcessConfig, len(chosen))
+	for i, c := range chosen {
+		names[i] = c.Config.Name
+		configs[i] = c.Config
+	}
+	if len(configs) > 0 {
+		if err := config.AddConfigs(config.ConfigFile, configs); err != nil {
+			return fmt.Errorf("failed to save configs: %w", err)
+		}
+	}
+
+	msg := "nothing imported"
+	if len(names) > 0 {
+		msg = fmt.Sprintf("imported %d %s: %s", len(names), plural(len(names), "process", "processes"), strings.Join(names, ", "))
+	}
+	if len(skipped) > 0 {
+		msg += "; skipped " + strings.Join(skipped, ", ")
+	}
+	return cli.Print(&protocol.MessageResult{Message: msg})
+}
+
+// portOwners maps the ports of saved configs to their names.
+func portOwners(existing []config.ProcessConfig) map[int]string {
+	owners := make(map[int]string, len(existing))
+	for _, c := range existing {
+		if c.Port != 0 {
+			owners[c.Port] = c.Name
+		}
+	}
+	return owners
+}
+
+// chooseImports picks the proposals --yes saves: those with a port that
+// no saved config or earlier proposal uses. The rest are added to skipped.
+func chooseImports(proposals []importer.Candidate, existing []config.ProcessConfig, skipped []string) ([]importer.Candidate, []string) {
+	owners := portOwners(existing)
+	var chosen []importer.Candidate
+	for _, c := range proposals {
+		port := c.Config.Port
+		switch owner, taken := owners[port]; {
+		case port == 0:
+			skipped = append(skipped, fmt.Sprintf("%s (no port found)", c.Config.Name))
+		case taken:
+			skipped = append(skipped, fmt.Sprintf("%s (port %d is used by '%s')", c.Config.Name, port, owner))
+		default:
+			owners[port] = c.Config.Name
+			chosen = append(chosen, c)
+		}
+	}
+	return chosen, skipped
+}
+
+// confirmImports asks which proposals to save, preselecting those that
+// --yes would, then asks for a port for each chosen one without a free
+// port.
+func confirmImports(proposals []importer.Candidate, existing []config.ProcessConfig) ([]importer.Candidate, error) {
+	if len(proposals) == 0 {
+		return nil, nil
+	}
+	preselected, _ := chooseImports(proposals, existing, nil)
+	isPreselected := make(map[string]bool, len(preselected))
+	for _, c := range preselected {
+		isPreselected[c.Config.Name] = true
+	}
+
+	options := make([]huh.Option[int], len(proposals))
+	for i, c := range proposals {
+		options[i] = huh.NewOption(importLabel(c), i).Selected(isPreselected[c.Config.Name])
+	}
+	var picked []int
+	err := huh.NewForm(huh.NewGroup(
+		huh.NewMultiSelect[int]().
+			Title("Processes to save").
+			Options(options...).
+			Value(&picked),
+	)).Run()
+	if err != nil {
+		return nil, importAborted(err)
+	}
+
+	// Ports are asked for the chosen processes with no port or one that is
+	// taken. Each must be free of the saved configs, the other chosen
+	// processes and the other answers.
+	owners := portOwners(existing)
+	chosen := make([]importer.Candidate, len(picked))
+	for i, index := range picked {
+		chosen[i] = proposals[index]
+		if isPreselected[chosen[i].Config.Name] {
+			owners[chosen[i].Config.Port] = chosen[i].Config.Name
+		}
+	}
+	answers := make(map[int]*string)
+	for i, c := range chosen {
+		if isPreselected[c.Config.Name] {
+			continue
+		}
+		if _, taken := owners[c.Config.Port]; c.Config.Port != 0 && !taken {
+			owners[c.Config.Port] = c.Config.Name
+			continue
+		}
+		answer := ""
+		answers[i] = &answer
+	}
+	var inputs []huh.Field
+	for i := range chosen {
+		answer, ok := answers[i]
+		if !ok {
+			continue
+		}
+		c := chosen[i]
+		title := fmt.Sprintf("Port for %s", c.Config.Name)
+		if c.Config.Port != 0 {
+			title += fmt.Sprintf(" (%d is used by '%s')", c.Config.Port, owners[c.Config.Port])
+		}
+		inputs = append(inputs, huh.NewInput().
+			Title(title).
+			Description(c.Config.CommandLine()).
+			Value(answer).
+			Validate(func(s string) error {
+				port, err := strconv.Atoi(strings.TrimSpace(s))
+				if err != nil {
+					return fmt.Errorf("port must be a number")
+				}
+				if err := config.ValidatePort(port); err != nil {
+					return err
+				}
+				if owner, taken := owners[port]; taken {
+					return fmt.Errorf("port %d is used by '%s'", port, owner)
+				}
+				for j, other := range answers {
+					if j != i && strings.TrimSpace(*other) == strconv.Itoa(port) {
+						return fmt.Errorf("port %d is given to '%s'", port, chosen[j].Config.Name)
+					}
+				}
+				return nil
+			}))
+	}
+	if len(inputs) > 0 {
+		if err := huh.NewForm(huh.NewGroup(inputs...)).Run(); err != nil {
+			return nil, importAborted(err)
+		}
+		for i, answer := range answers {
+			port, _ := strconv.Atoi(strings.TrimSpace(*answer))
+			chosen[i].SetPort(port)
+		}
+	}
+	return chosen, nil
+}
+
+// importLabel describes a proposal in the list of processes to save.
+func importLabel(c importer.Candidate) string {
+	port := "no port"
+	if c.Config.Port != 0 {
+		port = fmt.Sprintf("port %d from %s", c.Config.Port, c.PortFrom)
+	}
+	return fmt.Sprintf("%s: %s (%s, %s)", c.Config.Name, c.Config.CommandLine(), c.Source, port)
+}
+
+// importAborted reports a cancelled form as a cancelled import.
+func importAborted(err error) error {
+	if errors.Is(err, huh.ErrUserAborted) {
+		return errors.New("import cancelled, nothing saved")
+	}
+	return err
+}
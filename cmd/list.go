@@ -17,8 +17,7 @@ var listCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to list: %w", err)
 		}
-		fmt.Println(cli.RenderTable(lr))
-		return nil
+		return cli.Print(lr)
 	},
 }
 
@@ -4,7 +4,6 @@ import (
 	"github.com/jaiir320/devserve/cli"
 	"github.com/jaiir320/devserve/client"
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -19,25 +18,7 @@ var logsCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to get logs: %w", err)
 		}
-
-		// Render with styling
-		var b strings.Builder
-		b.WriteString(cli.Cyan.Render("─── stdout ───"))
-		b.WriteString("\n")
-		for _, line := range logsResult.Stdout {
-			b.WriteString(line)
-			b.WriteString("\n")
-		}
-		b.WriteString("\n")
-		b.WriteString(cli.Cyan.Render("─── stderr ───"))
-		b.WriteString("\n")
-		for _, line := range logsResult.Stderr {
-			b.WriteString(cli.Red.Render(line))
-			b.WriteString("\n")
-		}
-
-		fmt.Print(strings.TrimRight(b.String(), "\n"))
-		return nil
+		return cli.Print(logsResult)
 	},
 }
 
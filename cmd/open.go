@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/protocol"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Open a running process in the browser",
+	Long: `Open a running process in the browser with xdg-open: its tailnet URL
+when tailscale is running, otherwise its local one. --local and --dns pick
+one explicitly.
+
+--qr prints a QR code of the URL instead, to open it on a phone on your
+tailnet. The code is drawn for a dark terminal background.
+
+  devserve open web
+  devserve open web --local
+  devserve open web --qr`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		dns, _ := cmd.Flags().GetBool("dns")
+		local, _ := cmd.Flags().GetBool("local")
+		showQR, _ := cmd.Flags().GetBool("qr")
+
+		lr, err := client.List()
+		if err != nil {
+			return fmt.Errorf("failed to list processes: %w", err)
+		}
+		var entry *protocol.ListEntry
+		for i := range lr.Processes {
+			if lr.Processes[i].Name == name {
+				entry = &lr.Processes[i]
+			}
+		}
+		if entry == nil {
+			return protocol.WithKind(fmt.Errorf("process '%s' is not running", name), protocol.ErrNotFound)
+		}
+		url, err := cli.ProcessURL(entry.Port, lr.Hostname, dns, local)
+		if err != nil {
+			return err
+		}
+
+		if showQR {
+			if !cli.MachineReadable() {
+				code, err := cli.RenderQR(url)
+				if err != nil {
+					return err
+				}
+				fmt.Print(code)
+			}
+			return cli.Print(&protocol.MessageResult{Name: name, Message: url})
+		}
+		if err := cli.OpenBrowser(url); err != nil {
+			return fmt.Errorf("failed to open %s: %w", url, err)
+		}
+		return cli.Print(&protocol.MessageResult{Name: name, Message: "opened " + url})
+	},
+}
+
+func init() {
+	openCmd.Flags().Bool("dns", false, "open the tailnet URL")
+	openCmd.Flags().Bool("local", false, "open the localhost URL")
+	openCmd.Flags().Bool("qr", false, "print a QR code of the URL instead of opening it")
+	openCmd.MarkFlagsMutuallyExclusive("dns", "local")
+	rootCmd.AddCommand(openCmd)
+}
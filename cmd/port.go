@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/protocol"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var portCmd = &cobra.Command{
+	Use:   "port [port]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Show which process is listening on a port",
+	Long: `Show which process is listening on a port: its pid, command line and
+directory, and whether it is a devserve process, an orphan left behind by
+an earlier devserve run, or an unrelated program.
+
+Free the port with devserve serve --kill-existing.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, err := strconv.Atoi(args[0])
+		if err != nil {
+			return protocol.WithKind(fmt.Errorf("invalid port: %w", err), protocol.ErrInvalid)
+		}
+		host, _ := cmd.Flags().GetString("host")
+		result, err := client.Port(port, host)
+		if err != nil {
+			return fmt.Errorf("failed to look up port %d: %w", port, err)
+		}
+		return cli.Print(result)
+	},
+}
+
+func init() {
+	portCmd.Flags().String("host", "", "address to check, e.g. ::1 or a LAN address (default 127.0.0.1 and ::1)")
+	rootCmd.AddCommand(portCmd)
+}
@@ -2,31 +2,115 @@ package cmd
 
 import (
 	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/protocol"
 	"github.com/jaiir320/devserve/tui"
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
+var outputFlag string
+
 var rootCmd = &cobra.Command{
 	Use:   "devserve",
 	Short: "Serve your local projects with Tailscale",
 	Long:  `Serve your local dev servers across your Tailscale network with devserve.`,
 	Args:  cobra.NoArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		f, err := cli.ParseFormat(outputFlag)
+		if err != nil {
+			return protocol.WithKind(err, protocol.ErrInvalid)
+		}
+		cli.SetFormat(f)
+		if err := config.LoadSettings(); err != nil {
+			// config is how invalid settings get fixed, so it runs with
+			// the valid ones and a warning.
+			if !isConfigCommand(cmd) {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, cli.Error(err.Error()))
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return tui.Run()
 	},
 }
 
+// isConfigCommand reports whether cmd is config or one of its subcommands.
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// Exit codes returned by the CLI, mapped from error kinds.
+const (
+	exitError            = 1 // unclassified failure
+	exitUsage            = 2 // invalid flags or arguments
+	exitDaemonNotRunning = 3
+	exitNotFound         = 4 // process or config does not exist
+	exitConflict         = 5 // name or port already in use
+)
+
+// errorKind returns the kind reported in machine-readable error output.
+func errorKind(err error) string {
+	if errors.Is(err, client.ErrDaemonNotRunning) {
+		return "daemon_not_running"
+	}
+	return protocol.ErrorKind(err)
+}
+
+// exitCode maps an error returned by a command to the process exit code.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, client.ErrDaemonNotRunning):
+		return exitDaemonNotRunning
+	case errors.Is(err, protocol.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, protocol.ErrConflict):
+		return exitConflict
+	case errors.Is(err, protocol.ErrInvalid):
+		return exitUsage
+	}
+	return exitError
+}
+
 func Execute() {
 	rootCmd.SilenceErrors = true
 	rootCmd.SilenceUsage = true
 	rootCmd.SetHelpTemplate(cli.HelpTemplate())
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", string(cli.FormatTable), "output format: table, plain, json or yaml")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return protocol.WithKind(err, protocol.ErrInvalid)
+	})
 
 	err := rootCmd.Execute()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, cli.Error(err.Error()))
-		os.Exit(1)
+		var diag *protocol.Diagnostics
+		var de *protocol.DiagnosticsError
+		if errors.As(err, &de) {
+			diag = &de.Diagnostics
+		}
+		if cli.OutputFormat() == cli.FormatTable {
+			fmt.Fprintln(os.Stderr, cli.Error(err.Error()))
+			if diag != nil {
+				fmt.Fprintln(os.Stderr, cli.RenderDiagnostics(diag))
+			}
+		} else {
+			cli.Write(os.Stderr, cli.OutputFormat(), &protocol.ErrorResult{
+				Error:       err.Error(),
+				Kind:        errorKind(err),
+				Diagnostics: diag,
+			})
+		}
+		os.Exit(exitCode(err))
 	}
 }
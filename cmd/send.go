@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/protocol"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send [name] [text]",
+	Args:  cobra.ExactArgs(2),
+	Short: "Send input to a running process",
+	Long: `Send text to a running process's terminal or stdin, e.g. a key press
+for a dev server's interactive shortcuts:
+
+  devserve send web r
+  devserve send api yes --enter`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enter, _ := cmd.Flags().GetBool("enter")
+		if err := client.SendInput(args[0], args[1], enter); err != nil {
+			return fmt.Errorf("failed to send input: %w", err)
+		}
+		return cli.Print(&protocol.MessageResult{
+			Name:    args[0],
+			Message: fmt.Sprintf("sent input to '%s'", args[0]),
+		})
+	},
+}
+
+func init() {
+	sendCmd.Flags().BoolP("enter", "e", false, "press Enter after the text")
+	rootCmd.AddCommand(sendCmd)
+}
@@ -3,24 +3,54 @@ package cmd
 import (
 	"github.com/jaiir320/devserve/cli"
 	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/config"
 	"github.com/jaiir320/devserve/protocol"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var serveCmd = &cobra.Command{
-	Use:   "serve [name] [port] [command]",
-	Args:  cobra.ExactArgs(3),
+	Use:   "serve [name] [port] [command] | serve [name] [port] -- [args...]",
+	Args:  validateServeArgs,
 	Short: "Serve your dev server with tailscale",
+	Long: `Serve your dev server with tailscale.
+
+The command is run by a shell (sh, or --shell). Arguments after -- are run
+directly instead, without a shell:
+
+  devserve serve web 3000 "npm run dev"
+  devserve serve web 3000 -- npm run dev
+
+serve waits up to 15s (--start-timeout, or the timeouts.start setting)
+for the port to accept connections, showing the process's latest output meanwhile. Ctrl-C
+cancels the start and stops the process.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runServe(args)
+		return runServe(args, cmd.ArgsLenAtDash() >= 0)
 	},
 }
 
-func runServe(args []string) error {
+// validateServeArgs accepts a name, a port and either one command string or, after
+// --, the argv to run.
+func validateServeArgs(cmd *cobra.Command, args []string) error {
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		if dash != 2 || len(args) < 3 {
+			return protocol.WithKind(fmt.Errorf("expected [name] [port] -- [args...]"), protocol.ErrInvalid)
+		}
+		return nil
+	}
+	if err := cobra.ExactArgs(3)(cmd, args); err != nil {
+		return protocol.WithKind(err, protocol.ErrInvalid)
+	}
+	return nil
+}
+
+func runServe(args []string, argv bool) error {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get working directory: %w", err)
@@ -28,21 +58,134 @@ func runServe(args []string) error {
 
 	port, err := strconv.Atoi(args[1])
 	if err != nil {
-		return fmt.Errorf("invalid port: %w", err)
+		return protocol.WithKind(fmt.Errorf("invalid port: %w", err), protocol.ErrInvalid)
+	}
+	env, err := config.ParseEnv(serveFlags.env)
+	if err != nil {
+		return protocol.WithKind(err, protocol.ErrInvalid)
 	}
 
-	var result *protocol.ServeResult
-	cli.Spin("Starting process...", func() {
-		result, err = client.Serve(args[0], port, args[2], cwd)
-	})
+	cfg := config.ProcessConfig{
+		Name:      args[0],
+		Port:      port,
+		Directory: cwd,
+		Shell:     serveFlags.shell,
+		TTY:       serveFlags.tty,
+		StripANSI: serveFlags.stripANSI,
+		MemoryMax: serveFlags.memoryMax,
+		CPUQuota:  serveFlags.cpuQuota,
+		PidsMax:   serveFlags.pidsMax,
+
+		StopSignal:  serveFlags.stopSignal,
+		StopTimeout: serveFlags.stopTimeout,
+		PreStop:     serveFlags.preStop,
+
+		PreStart:  serveFlags.preStart,
+		PostReady: serveFlags.postReady,
+		PostStop:  serveFlags.postStop,
+
+		ReadyWhenLogMatches: serveFlags.readyWhen,
+		StartTimeout:        serveFlags.startTimeout,
+		Host:                serveFlags.host,
+		Env:                 env,
+		Tags:                serveFlags.tags,
+	}
+	if argv {
+		cfg.Args = args[2:]
+	} else {
+		cfg.Command = args[2]
+	}
+	defaults, err := config.LoadDefaults(config.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load process defaults: %w", err)
+	}
+	cfg = defaults.Apply(cfg)
+
+	result, err := serveWithProgress("Starting process...", cfg, serveFlags.killExisting)
 	if err != nil {
 		return fmt.Errorf("failed to serve: %w", err)
 	}
 
-	fmt.Println(cli.RenderServeResult(result))
-	return nil
+	return cli.Print(result)
+}
+
+// serveWithProgress starts cfg behind a spinner showing how long the
+// start has taken and the process's latest output. Ctrl-C cancels the
+// start. With killExisting, whatever listens on the port is stopped first.
+func serveWithProgress(title string, cfg config.ProcessConfig, killExisting bool) (*protocol.ServeResult, error) {
+	var result *protocol.ServeResult
+	err := cli.SpinStatus(title, func(ctx context.Context, status func(string)) error {
+		var err error
+		result, err = client.ServeContext(ctx, cfg, client.ServeOptions{
+			Progress:     func(pr protocol.Progress) { status(formatProgress(pr)) },
+			KillExisting: killExisting,
+		})
+		return err
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil, fmt.Errorf("start of '%s' cancelled", cfg.Name)
+	}
+	return result, err
+}
+
+// formatProgress renders start progress as "12s · waiting for port 3000 ·
+// <last output line>".
+func formatProgress(pr protocol.Progress) string {
+	parts := []string{fmt.Sprintf("%ds", int(pr.Elapsed))}
+	if pr.Stage != "" {
+		parts = append(parts, pr.Stage)
+	}
+	if pr.Line != "" {
+		parts = append(parts, pr.Line)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// serveFlags holds optional process settings given on the command line.
+var serveFlags struct {
+	shell     string
+	tty       bool
+	stripANSI bool
+
+	memoryMax string
+	cpuQuota  string
+	pidsMax   int
+
+	stopSignal  string
+	stopTimeout string
+	preStop     string
+
+	preStart  string
+	postReady string
+	postStop  string
+
+	readyWhen    string
+	startTimeout string
+	host         string
+	env          []string
+	tags         []string
+
+	killExisting bool
 }
 
 func init() {
+	serveCmd.Flags().StringVar(&serveFlags.shell, "shell", "", `shell for the command and hooks: bash (bash -lc), zsh (zsh -ic), "$SHELL" or a path (default sh)`)
+	serveCmd.Flags().BoolVar(&serveFlags.tty, "tty", false, "run the command on a pseudo-terminal")
+	serveCmd.Flags().BoolVar(&serveFlags.stripANSI, "strip-ansi", false, "strip terminal escape sequences from --tty output in the log")
+	serveCmd.Flags().StringVar(&serveFlags.memoryMax, "memory-max", "", "memory limit, e.g. 512M or 2G")
+	serveCmd.Flags().StringVar(&serveFlags.cpuQuota, "cpu-quota", "", "CPU limit as a percentage of one CPU, e.g. 150%")
+	serveCmd.Flags().IntVar(&serveFlags.pidsMax, "pids-max", 0, "maximum number of processes")
+	serveCmd.Flags().StringVar(&serveFlags.stopSignal, "stop-signal", "", "signal sent to stop the process (default SIGTERM)")
+	serveCmd.Flags().StringVar(&serveFlags.stopTimeout, "stop-timeout", "", "grace period before SIGKILL, e.g. 10s (default 5s)")
+	serveCmd.Flags().StringVar(&serveFlags.preStop, "pre-stop", "", "command run before the stop signal, e.g. to drain connections")
+	serveCmd.Flags().StringVar(&serveFlags.preStart, "pre-start", "", "command run before starting, e.g. npm install; failure aborts")
+	serveCmd.Flags().StringVar(&serveFlags.postReady, "post-ready", "", "command run once the port is ready, e.g. to seed data")
+	serveCmd.Flags().StringVar(&serveFlags.postStop, "post-stop", "", "command run after the process has stopped")
+	serveCmd.Flags().StringVar(&serveFlags.readyWhen, "ready-when-log-matches", "", `regular expression; also wait for an output line matching it, e.g. "compiled successfully"`)
+	serveCmd.Flags().StringVar(&serveFlags.startTimeout, "start-timeout", "", "how long to wait for the process to become ready, e.g. 2m (default 15s)")
+	serveCmd.Flags().StringArrayVarP(&serveFlags.env, "env", "e", nil, "set an environment variable, NAME=value; repeatable")
+	serveCmd.Flags().StringArrayVar(&serveFlags.tags, "tag", nil, "label the process for grouping in the TUI; repeatable")
+	serveCmd.Flags().StringVar(&serveFlags.host, "host", "", "address the server listens on, e.g. ::1 or a container IP (default 127.0.0.1 or ::1)")
+	serveCmd.Flags().BoolVar(&serveFlags.killExisting, "kill-existing", false, "stop whatever is listening on the port first (see devserve port)")
 	rootCmd.AddCommand(serveCmd)
 }
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/protocol"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var signalCmd = &cobra.Command{
+	Use:   "signal [name] [signal]",
+	Args:  cobra.ExactArgs(2),
+	Short: "Send a signal to a running process",
+	Long: `Send a signal to a running process, e.g. SIGHUP to reload its config or
+SIGUSR2 for a heap dump. The signal is given by name, with or without the
+SIG prefix, or by number. With --group it goes to the process's whole
+process tree instead of just the main process.
+
+  devserve signal web HUP
+  devserve signal api SIGUSR2 --group`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		group, _ := cmd.Flags().GetBool("group")
+		msg, err := client.Signal(args[0], args[1], group)
+		if err != nil {
+			return fmt.Errorf("failed to send signal: %w", err)
+		}
+		return cli.Print(&protocol.MessageResult{Name: args[0], Message: msg})
+	},
+}
+
+func init() {
+	signalCmd.Flags().BoolP("group", "g", false, "signal the whole process group")
+	rootCmd.AddCommand(signalCmd)
+}
@@ -2,11 +2,10 @@ package cmd
 
 import (
 	"github.com/jaiir320/devserve/cli"
-	"github.com/jaiir320/devserve/client"
 	"github.com/jaiir320/devserve/config"
 	"github.com/jaiir320/devserve/protocol"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -20,31 +19,32 @@ var startCmd = &cobra.Command{
 	},
 }
 
+var startKillExisting bool
+
 func init() {
+	startCmd.Flags().BoolVar(&startKillExisting, "kill-existing", false, "stop whatever is listening on the port first (see devserve port)")
 	rootCmd.AddCommand(startCmd)
 }
 
 func runStart(name string) error {
 	// Load the config
-	cfg, err := config.GetConfig(config.ConfigFile, name)
+	cfg, err := config.ResolveConfig(config.ConfigFile, name)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	var result *protocol.ServeResult
-	cli.Spin(fmt.Sprintf("Starting '%s'...", name), func() {
-		result, err = client.Serve(cfg.Name, cfg.Port, cfg.Command, cfg.Directory)
-	})
-
+	result, err := serveWithProgress(fmt.Sprintf("Starting '%s'...", name), *cfg, startKillExisting)
 	if err != nil {
-		// Check if it's "already running" error
-		if strings.Contains(err.Error(), "already in use") {
-			fmt.Println(cli.Info(fmt.Sprintf("process '%s' is already running", name)))
-			return nil
+		// A process of the same name is success; a port held by another
+		// program is not.
+		if errors.Is(err, protocol.ErrRunning) {
+			return cli.Print(&protocol.MessageResult{
+				Name:    name,
+				Message: fmt.Sprintf("process '%s' is already running", name),
+			})
 		}
 		return fmt.Errorf("failed to start: %w", err)
 	}
 
-	fmt.Println(cli.RenderServeResult(result))
-	return nil
+	return cli.Print(result)
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/jaiir320/devserve/cli"
 	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/protocol"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -20,8 +21,10 @@ var stopCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to stop: %w", err)
 		}
-		fmt.Println(cli.Success(fmt.Sprintf("process '%s' stopped", args[0])))
-		return nil
+		return cli.Print(&protocol.MessageResult{
+			Name:    args[0],
+			Message: fmt.Sprintf("process '%s' stopped", args[0]),
+		})
 	},
 }
 
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/tui"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Args:  cobra.NoArgs,
+	Short: "Show live CPU and memory usage of processes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Scripts get a single snapshot instead of the live view.
+		if cli.OutputFormat() != cli.FormatTable {
+			lr, err := client.ListWithHistory()
+			if err != nil {
+				return fmt.Errorf("failed to list: %w", err)
+			}
+			return cli.Print(lr)
+		}
+		if err := client.Ping(); err != nil {
+			return err
+		}
+		return tui.RunTop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+}
@@ -22,30 +22,94 @@ func daemonDir() string {
 	return "/tmp/devserve"
 }
 
-// Paths
+// Paths. DaemonDir and Socket can be changed in the settings file; see
+// LoadSettings.
 var (
-	DaemonDir  = daemonDir()
-	Socket     = socketPath()
-	ConfigDir  = filepath.Join(os.Getenv("HOME"), ".config", "devserve")
-	ConfigFile = filepath.Join(ConfigDir, "config.json")
+	DaemonDir    = daemonDir()
+	Socket       = socketPath()
+	ConfigDir    = filepath.Join(os.Getenv("HOME"), ".config", "devserve")
+	ConfigFile   = filepath.Join(ConfigDir, "config.json")
+	SettingsFile = filepath.Join(ConfigDir, "settings.toml")
 )
 
-const (
+// Log file names, changeable in the settings file
+var (
 	DaemonLogFile    = "out.log"
 	ProcessLogDir    = ".devserve"
 	ProcessStdoutLog = "out.log"
 	ProcessStderrLog = "err.log"
 )
 
+// Tunnel is the provider that exposes processes beyond this machine:
+// "tailscale", or "none" for local URLs only.
+var Tunnel = "tailscale"
+
+const (
+	HookLogDir = "hooks" // under ProcessLogDir, one <hook>.log per hook
+
+	// ProcessEnvVar is set to the process name in every process devserve
+	// starts, so its descendants can be recognised after a daemon restart.
+	ProcessEnvVar = "DEVSERVE_PROCESS"
+)
+
+// Timeouts changeable in the settings file
+var (
+	PortWaitTimeout  = 15 * time.Second // default start timeout, overridable per process
+	StopGracePeriod  = 5 * time.Second  // default, overridable per process
+	PreStopTimeout   = 30 * time.Second
+	PreStartTimeout  = 5 * time.Minute // long enough for npm install
+	PostReadyTimeout = time.Minute
+	PostStopTimeout  = time.Minute
+	ShutdownTimeout  = 15 * time.Second
+)
+
+// How often a stopped process's port is checked until it is released.
+// Changeable in the settings file.
+var PortReleasePollInterval = 100 * time.Millisecond
+
 // Timeouts
 const (
-	PortWaitTimeout  = 15 * time.Second
-	StopGracePeriod  = 5 * time.Second
 	PortDialTimeout  = 500 * time.Millisecond
-	PortPollInterval = 500 * time.Millisecond
 	DaemonStartDelay = 100 * time.Millisecond
-	ShutdownTimeout  = 15 * time.Second
+
+	TreeKillTimeout    = 2 * time.Second        // waiting for SIGKILLed descendants
+	TTYDrainTimeout    = 500 * time.Millisecond // reading a stopped process's remaining pty output
+	PortReleaseTimeout = 3 * time.Second        // waiting for a stopped process's port
+	InputWriteTimeout  = 2 * time.Second        // sending input to a process that is not reading it
 )
 
-// Permissions
-const DirPermissions = os.FileMode(0755)
+// Lines of hook output included in a hook failure error
+const HookErrorLines = 20
+
+// Lines of each process log included when it fails to start
+const StartErrorLines = 20
+
+// Resource usage sampling. UsageSampleInterval is changeable in the
+// settings file.
+var UsageSampleInterval = 2 * time.Second
+
+const UsageHistorySize = 60 // samples kept per process (2 minutes at the default interval)
+
+// How often attached viewers of a process without a TTY check its logs
+const AttachPollInterval = 100 * time.Millisecond
+
+// How often a starting process is checked for readiness, and how often
+// clients that asked for progress are sent it
+var ReadyPollInterval = 100 * time.Millisecond // changeable in the settings file
+
+const StartProgressInterval = 250 * time.Millisecond
+
+// The TUI log viewer: how many lines of each log it shows, and how often
+// it refreshes them while following
+const (
+	LogViewerLines    = 1000
+	LogFollowInterval = time.Second
+)
+
+// Permissions. The config file is private to the user: it can hold
+// environment variables such as API keys.
+const (
+	DirPermissions          = os.FileMode(0755)
+	ConfigFilePermissions   = os.FileMode(0600)
+	SettingsFilePermissions = os.FileMode(0644)
+)
@@ -0,0 +1,403 @@
+package config
+
+import (
+	"github.com/jaiir320/devserve/protocol"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ConfigVersion is the config file layout this build reads and writes.
+// Version 1 was a bare JSON array of processes.
+const ConfigVersion = 2
+
+// Document is the config file.
+type Document struct {
+	Version   int             `json:"version"`
+	Processes []ProcessConfig `json:"processes"`
+	Defaults  Defaults        `json:"defaults,omitzero"`
+}
+
+// Defaults are settings applied to every saved process that does not
+// set them itself.
+type Defaults struct {
+	Shell        string            `json:"shell,omitempty"`
+	StopSignal   string            `json:"stop_signal,omitempty"`
+	StopTimeout  string            `json:"stop_timeout,omitempty"`
+	StartTimeout string            `json:"start_timeout,omitempty"`
+	MemoryMax    string            `json:"memory_max,omitempty"`
+	CPUQuota     string            `json:"cpu_quota,omitempty"`
+	PidsMax      int               `json:"pids_max,omitempty"`
+	Env          map[string]string `json:"env,omitempty"` // merged under the process's own
+}
+
+// IsZero reports whether no defaults are set.
+func (d Defaults) IsZero() bool {
+	return d.Shell == "" && d.StopSignal == "" && d.StopTimeout == "" && d.StartTimeout == "" &&
+		d.MemoryMax == "" && d.CPUQuota == "" && d.PidsMax == 0 && len(d.Env) == 0
+}
+
+// Apply returns cfg with the defaults filled in where it has no setting.
+func (d Defaults) Apply(cfg ProcessConfig) ProcessConfig {
+	fill := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	fill(&cfg.Shell, d.Shell)
+	fill(&cfg.StopSignal, d.StopSignal)
+	fill(&cfg.StopTimeout, d.StopTimeout)
+	fill(&cfg.StartTimeout, d.StartTimeout)
+	fill(&cfg.MemoryMax, d.MemoryMax)
+	fill(&cfg.CPUQuota, d.CPUQuota)
+	if cfg.PidsMax == 0 {
+		cfg.PidsMax = d.PidsMax
+	}
+	if len(d.Env) > 0 {
+		env := maps.Clone(d.Env)
+		maps.Copy(env, cfg.Env)
+		cfg.Env = env
+	}
+	return cfg
+}
+
+// Strip is the inverse of Apply: it clears the settings of cfg that only
+// repeat the defaults, so saving a process started with them applied
+// does not copy them into its config.
+func (d Defaults) Strip(cfg ProcessConfig) ProcessConfig {
+	unset := func(v *string, def string) {
+		if *v == def {
+			*v = ""
+		}
+	}
+	unset(&cfg.Shell, d.Shell)
+	unset(&cfg.StopSignal, d.StopSignal)
+	unset(&cfg.StopTimeout, d.StopTimeout)
+	unset(&cfg.StartTimeout, d.StartTimeout)
+	unset(&cfg.MemoryMax, d.MemoryMax)
+	unset(&cfg.CPUQuota, d.CPUQuota)
+	if cfg.PidsMax == d.PidsMax {
+		cfg.PidsMax = 0
+	}
+	if len(cfg.Env) > 0 && len(d.Env) > 0 {
+		env := make(map[string]string)
+		for name, value := range cfg.Env {
+			if def, ok := d.Env[name]; !ok || def != value {
+				env[name] = value
+			}
+		}
+		cfg.Env = env
+		if len(env) == 0 {
+			cfg.Env = nil
+		}
+	}
+	return cfg
+}
+
+// BackupPath returns where the original of a config file migrated from
+// version 1 is kept. Should a second version 1 file be migrated, say after
+// running an older devserve, its backup gets a number: config.json.v1.bak.2.
+func BackupPath(configPath string) string {
+	return configPath + ".v1.bak"
+}
+
+// writeBackup writes data to the first backup path that is not taken.
+func writeBackup(configPath string, data []byte) error {
+	path := BackupPath(configPath)
+	for n := 2; ; n++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, ConfigFilePermissions)
+		if errors.Is(err, fs.ErrExist) {
+			path = fmt.Sprintf("%s.%d", BackupPath(configPath), n)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
+
+// LoadDocument reads the config file. A version 1 file is migrated to
+// the current layout, keeping the original at BackupPath. A missing file
+// is an empty document.
+func LoadDocument(configPath string) (*Document, error) {
+	doc, data, err := readDocument(configPath)
+	if err != nil || data == nil {
+		return doc, err
+	}
+	err = withLock(configPath, func() error {
+		doc, err = loadDocumentLocked(configPath)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// loadDocumentLocked is LoadDocument for a caller holding the config lock.
+// Migrating under the lock means a file is migrated, and backed up, once.
+func loadDocumentLocked(configPath string) (*Document, error) {
+	doc, data, err := readDocument(configPath)
+	if err != nil || data == nil {
+		return doc, err
+	}
+	if err := migrate(configPath, data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ReadDocument reads the config file like LoadDocument but never writes
+// it. It reports whether the file is in the version 1 layout and would be
+// migrated.
+func ReadDocument(configPath string) (doc *Document, legacy bool, err error) {
+	doc, data, err := readDocument(configPath)
+	return doc, data != nil, err
+}
+
+// readDocument reads and strictly decodes the config file. It returns the
+// file's contents when it is in the version 1 layout and needs migrating.
+func readDocument(configPath string) (*Document, []byte, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Document{Version: ConfigVersion, Processes: []ProcessConfig{}}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return parseDocument(configPath, data)
+}
+
+// parseDocument strictly decodes data, read from configPath. It returns
+// data as well when it is in the version 1 layout.
+func parseDocument(configPath string, data []byte) (*Document, []byte, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var processes []ProcessConfig
+		if err := decodeStrict(data, &processes); err != nil {
+			return nil, nil, configError(configPath, err)
+		}
+		return &Document{Version: ConfigVersion, Processes: processes}, data, nil
+	}
+
+	var doc Document
+	if err := decodeStrict(data, &doc); err != nil {
+		return nil, nil, configError(configPath, err)
+	}
+	switch {
+	case doc.Version == 0:
+		return nil, nil, configError(configPath, fmt.Errorf(`missing "version": expected %d`, ConfigVersion))
+	case doc.Version > ConfigVersion:
+		return nil, nil, configError(configPath, fmt.Errorf("version %d is newer than this devserve supports (%d); upgrade devserve", doc.Version, ConfigVersion))
+	case doc.Version < ConfigVersion:
+		return nil, nil, configError(configPath, fmt.Errorf("unknown version %d", doc.Version))
+	}
+	if doc.Processes == nil {
+		doc.Processes = []ProcessConfig{}
+	}
+	return &doc, nil, nil
+}
+
+// migrate writes doc over a version 1 config file, first copying the
+// original, data, to its backup.
+func migrate(configPath string, data []byte, doc *Document) error {
+	if err := writeBackup(configPath, data); err != nil {
+		return fmt.Errorf("failed to back up config file before migrating it: %w", err)
+	}
+	if err := writeDocument(configPath, doc); err != nil {
+		return fmt.Errorf("failed to migrate config file: %w", err)
+	}
+	return nil
+}
+
+// configError tags a problem with the config file as invalid and names
+// the file.
+func configError(configPath string, err error) error {
+	return protocol.WithKind(fmt.Errorf("invalid config file %s: %w", configPath, err), protocol.ErrInvalid)
+}
+
+// decodeStrict decodes JSON into v, rejecting unknown fields and trailing
+// data. Errors say where in the file the problem is.
+func decodeStrict(data []byte, v any) error {
+	if fields := UnknownFields(data); len(fields) > 0 {
+		return errors.New(strings.Join(fields, "; "))
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return describeJSONError(data, err)
+	}
+	if dec.More() {
+		return fmt.Errorf("%s: unexpected data after the config", position(data, dec.InputOffset()))
+	}
+	return nil
+}
+
+// describeJSONError rewrites a decoding error with its line and column
+// and, for type errors, the field and the type expected.
+func describeJSONError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// Offset is just past the offending character.
+		return fmt.Errorf("%s: %s", position(data, syntaxErr.Offset-1), syntaxErr)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		where := ""
+		// "processes.0.port" is "port" of the first process; a version 1
+		// file's fields start at the index.
+		parts := strings.SplitN(strings.TrimPrefix(field, "processes."), ".", 2)
+		if i, err := strconv.Atoi(parts[0]); err == nil && len(parts) == 2 {
+			where, field = fmt.Sprintf("process %d: ", i+1), parts[1]
+		}
+		return fmt.Errorf("%s: %s%q must be %s, not %s", position(data, typeErr.Offset), where, field, describeType(typeErr.Type), typeErr.Value)
+	}
+	return err
+}
+
+// describeType names a Go type the way a config file author thinks of it.
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int64, reflect.Uint16:
+		return "a whole number"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// position returns "line L, column C" for a byte offset into data.
+func position(data []byte, offset int64) string {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d", line, col)
+}
+
+// UnknownFields returns a description of every field in a config file
+// that devserve does not know, with the closest known field when one is
+// likely meant. Files that are not valid JSON have none.
+func UnknownFields(data []byte) []string {
+	var problems []string
+	check := func(where string, obj map[string]json.RawMessage, t reflect.Type) {
+		known := jsonFields(t)
+		for _, key := range slices.Sorted(maps.Keys(obj)) {
+			if slices.Contains(known, key) {
+				continue
+			}
+			msg := fmt.Sprintf("%sunknown field %q", where, key)
+			if guess := closest(key, known); guess != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", guess)
+			}
+			problems = append(problems, msg)
+		}
+	}
+	checkProcesses := func(raw json.RawMessage) {
+		var processes []map[string]json.RawMessage
+		if json.Unmarshal(raw, &processes) != nil {
+			return
+		}
+		for i, p := range processes {
+			check(processWhere(i, p), p, reflect.TypeFor[ProcessConfig]())
+		}
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		checkProcesses(data)
+		return problems
+	}
+	var top map[string]json.RawMessage
+	if json.Unmarshal(data, &top) != nil {
+		return nil
+	}
+	check("", top, reflect.TypeFor[Document]())
+	if raw, ok := top["processes"]; ok {
+		checkProcesses(raw)
+	}
+	if raw, ok := top["defaults"]; ok {
+		var defaults map[string]json.RawMessage
+		if json.Unmarshal(raw, &defaults) == nil {
+			check("defaults: ", defaults, reflect.TypeFor[Defaults]())
+		}
+	}
+	return problems
+}
+
+// processWhere describes a process in the config file for an error
+// message, by name when it has one.
+func processWhere(i int, p map[string]json.RawMessage) string {
+	var name string
+	if json.Unmarshal(p["name"], &name) == nil && name != "" {
+		return fmt.Sprintf("process %d (%q): ", i+1, name)
+	}
+	return fmt.Sprintf("process %d: ", i+1)
+}
+
+// jsonFields returns the JSON field names of a struct type.
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for f := range t.Fields() {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// closest returns the known field nearest to key by edit distance, if it
+// is close enough to be a typo or a different spelling.
+func closest(key string, known []string) string {
+	best, bestDist := "", 3
+	norm := func(s string) string { return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s)) }
+	for _, k := range known {
+		if norm(k) == norm(key) {
+			return k // "stopSignal" for "stop_signal"
+		}
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"github.com/jaiir320/devserve/protocol"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDocumentMigratesVersion1(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	v1 := `[{"name": "web", "port": 3000, "command": "npm run dev", "directory": "/srv/web"}]`
+	writeFile(t, configPath, v1)
+
+	doc, err := LoadDocument(configPath)
+	if err != nil {
+		t.Fatalf("LoadDocument failed: %v", err)
+	}
+	if doc.Version != ConfigVersion || len(doc.Processes) != 1 || doc.Processes[0].Name != "web" {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	backup, err := os.ReadFile(BackupPath(configPath))
+	if err != nil {
+		t.Fatalf("no backup: %v", err)
+	}
+	if string(backup) != v1 {
+		t.Errorf("backup = %q, want the original file", backup)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written struct {
+		Version   int               `json:"version"`
+		Processes []json.RawMessage `json:"processes"`
+	}
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("migrated file is not a document: %v\n%s", err, data)
+	}
+	if written.Version != ConfigVersion || len(written.Processes) != 1 {
+		t.Errorf("migrated file = %s", data)
+	}
+
+	// A second version 1 file does not overwrite the first backup.
+	writeFile(t, configPath, `[]`)
+	if _, err := LoadDocument(configPath); err != nil {
+		t.Fatalf("LoadDocument failed: %v", err)
+	}
+	if backup, _ := os.ReadFile(BackupPath(configPath)); string(backup) != v1 {
+		t.Errorf("first backup overwritten with %q", backup)
+	}
+	if backup, _ := os.ReadFile(BackupPath(configPath) + ".2"); string(backup) != `[]` {
+		t.Errorf("second backup = %q, want %q", backup, `[]`)
+	}
+}
+
+func TestReadDocumentDoesNotMigrate(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, configPath, `[]`)
+
+	_, legacy, err := ReadDocument(configPath)
+	if err != nil {
+		t.Fatalf("ReadDocument failed: %v", err)
+	}
+	if !legacy {
+		t.Error("expected a version 1 file to be reported as legacy")
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != `[]` {
+		t.Errorf("file changed to %q", data)
+	}
+	if _, err := os.Stat(BackupPath(configPath)); !os.IsNotExist(err) {
+		t.Errorf("expected no backup, got %v", err)
+	}
+}
+
+func TestLoadDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "unknown process field",
+			data: `{"version": 2, "processes": [{"name": "web", "port": 3000, "comand": "x"}]}`,
+			want: `process 1 ("web"): unknown field "comand" (did you mean "command"?)`,
+		},
+		{
+			name: "different spelling",
+			data: `{"version": 2, "processes": [{"name": "web", "stopSignal": "SIGINT"}]}`,
+			want: `unknown field "stopSignal" (did you mean "stop_signal"?)`,
+		},
+		{
+			name: "unknown top-level field",
+			data: `{"version": 2, "processes": [], "defualts": {}}`,
+			want: `unknown field "defualts" (did you mean "defaults"?)`,
+		},
+		{
+			name: "unknown default",
+			data: `{"version": 2, "processes": [], "defaults": {"colour": "red"}}`,
+			want: `defaults: unknown field "colour"`,
+		},
+		{
+			name: "unknown field in version 1",
+			data: `[{"name": "web", "prot": 3000}]`,
+			want: `unknown field "prot" (did you mean "port"?)`,
+		},
+		{
+			name: "wrong type",
+			data: "{\n  \"version\": 2,\n  \"processes\": [{\"name\": \"web\", \"port\": \"3000\"}]\n}",
+			want: `line 3, column 47: process 1: "port" must be a whole number, not string`,
+		},
+		{
+			name: "syntax error",
+			data: "{\n  \"version\": 2,\n}",
+			want: "line 3, column 1:",
+		},
+		{
+			name: "missing version",
+			data: `{"processes": []}`,
+			want: `missing "version"`,
+		},
+		{
+			name: "newer version",
+			data: `{"version": 3, "processes": []}`,
+			want: "upgrade devserve",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.json")
+			writeFile(t, configPath, tt.data)
+
+			_, err := LoadDocument(configPath)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+			if !errors.Is(err, protocol.ErrInvalid) {
+				t.Errorf("expected ErrInvalid, got %v", err)
+			}
+			if data, _ := os.ReadFile(configPath); string(data) != tt.data {
+				t.Errorf("invalid file was rewritten: %q", data)
+			}
+		})
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, configPath, `{
+  "version": 2,
+  "processes": [
+    {"name": "web", "port": 3000, "command": "npm run dev", "directory": "/srv/web", "stop_signal": "SIGTERM", "env": {"PORT": "3000"}}
+  ],
+  "defaults": {"shell": "bash", "stop_signal": "SIGINT", "env": {"NODE_ENV": "development", "PORT": "80"}}
+}`)
+
+	cfg, err := ResolveConfig(configPath, "web")
+	if err != nil {
+		t.Fatalf("ResolveConfig failed: %v", err)
+	}
+	if cfg.Shell != "bash" {
+		t.Errorf("Shell = %q, want the default", cfg.Shell)
+	}
+	if cfg.StopSignal != "SIGTERM" {
+		t.Errorf("StopSignal = %q, want the process's own", cfg.StopSignal)
+	}
+	if cfg.Env["NODE_ENV"] != "development" || cfg.Env["PORT"] != "3000" {
+		t.Errorf("Env = %v, want the defaults under the process's own", cfg.Env)
+	}
+
+	// Saving a process started with the defaults does not copy them.
+	doc, err := LoadDocument(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripped := doc.Defaults.Strip(*cfg)
+	if stripped.Shell != "" || stripped.StopSignal != "SIGTERM" || len(stripped.Env) != 1 || stripped.Env["PORT"] != "3000" {
+		t.Errorf("Strip = %+v", stripped)
+	}
+
+	// Editing the processes keeps the defaults, and deleting the last
+	// one keeps the file.
+	if err := SaveConfig(configPath, ProcessConfig{Name: "api", Port: 4000, Command: "go run .", Directory: "/srv/api"}); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	for _, name := range []string{"web", "api"} {
+		if err := DeleteConfig(configPath, name); err != nil {
+			t.Fatalf("DeleteConfig failed: %v", err)
+		}
+	}
+	doc, err = LoadDocument(configPath)
+	if err != nil {
+		t.Fatalf("LoadDocument failed: %v", err)
+	}
+	if len(doc.Processes) != 0 || doc.Defaults.Shell != "bash" {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var memorySuffixes = []string{"K", "M", "G", "T"}
+
+// ParseMemory parses a memory_max setting: a byte count with an optional
+// K, M, G or T suffix (powers of 1024).
+func ParseMemory(s string) (int64, error) {
+	num := strings.ToUpper(strings.TrimSpace(s))
+	num = strings.TrimSuffix(strings.TrimSuffix(num, "B"), "I")
+	mult := int64(1)
+	for i, suffix := range memorySuffixes {
+		if rest, ok := strings.CutSuffix(num, suffix); ok {
+			num = rest
+			mult = int64(1) << (10 * (i + 1))
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory_max %q: expected a size like 512M or 2G", s)
+	}
+	return n * mult, nil
+}
+
+// FormatMemory formats a byte count for memory_max, the inverse of
+// ParseMemory.
+func FormatMemory(n int64) string {
+	for i := len(memorySuffixes) - 1; i >= 0; i-- {
+		unit := int64(1) << (10 * (i + 1))
+		if n%unit == 0 {
+			return strconv.FormatInt(n/unit, 10) + memorySuffixes[i]
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// minCPUQuota is the smallest cpu_quota, in percent of one CPU: the
+// kernel refuses a cpu.max quota under 1ms per 100ms period.
+const minCPUQuota = 1
+
+// ParseCPUQuota parses a cpu_quota setting, a percentage of one CPU
+// ("150%") or a number of CPUs ("1.5"), into a percentage.
+func ParseCPUQuota(s string) (float64, error) {
+	trimmed := strings.TrimSpace(s)
+	percent, isPercent := strings.CutSuffix(trimmed, "%")
+	v, err := strconv.ParseFloat(percent, 64)
+	if err != nil || v <= 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid cpu_quota %q: expected a percentage like 150%% or a CPU count like 1.5", s)
+	}
+	if !isPercent {
+		v *= 100
+	}
+	if v < minCPUQuota {
+		return 0, fmt.Errorf("invalid cpu_quota %q: must be at least %d%% of a CPU", s, minCPUQuota)
+	}
+	return v, nil
+}
+
+// ParseSignal accepts a signal name with or without the SIG prefix, in
+// any case ("SIGHUP", "usr2"), or a signal number.
+func ParseSignal(s string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n <= 0 || n > 64 {
+			return 0, fmt.Errorf("signal number out of range")
+		}
+		return syscall.Signal(n), nil
+	}
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig := unix.SignalNum(name)
+	if sig == 0 {
+		return 0, fmt.Errorf("unknown signal")
+	}
+	return sig, nil
+}
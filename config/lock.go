@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockPath returns the lock file guarding a config file. The config file
+// itself cannot be locked: saving replaces it with a new file.
+func lockPath(configPath string) string {
+	return configPath + ".lock"
+}
+
+// withLock runs fn holding an exclusive lock on the config file, so the
+// read-modify-write of a save in the TUI, a CLI command or the daemon
+// cannot interleave with another's. The lock is advisory and released
+// when fn returns or the process dies. It is not reentrant: fn must not
+// call anything that takes it again.
+func withLock(configPath string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(configPath), DirPermissions); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	f, err := os.OpenFile(lockPath(configPath), os.O_RDWR|os.O_CREATE, ConfigFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to open config lock: %w", err)
+	}
+	defer f.Close()
+
+	for {
+		err = unix.Flock(int(f.Fd()), unix.LOCK_EX)
+		if err != unix.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock config file: %w", err)
+	}
+	defer unix.Flock(int(f.Fd()), unix.LOCK_UN)
+
+	return fn()
+}
+
+// writeFileAtomic replaces path with data: it is written to a temporary
+// file in the same directory, synced and renamed over path, so readers
+// see the old contents or the new, never a partial write. A symlink at
+// path, as a dotfiles repository leaves, is followed rather than
+// replaced, and the directory is synced so the rename survives a crash.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	path, err := resolveLink(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// resolveLink returns the file path names once symlinks are followed. A
+// link whose target does not exist yet resolves to that target, so the
+// first save creates it.
+func resolveLink(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return resolved, err
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		// Not a link, or nothing there yet.
+		return path, nil
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return resolveLink(target)
+}
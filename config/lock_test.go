@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// saveMany saves n configs named prefix-0 ... prefix-n-1.
+func saveMany(configPath, prefix string, n int) error {
+	for i := range n {
+		cfg := ProcessConfig{Name: fmt.Sprintf("%s-%d", prefix, i), Port: 3000 + i, Command: "true", Directory: "/tmp"}
+		if err := SaveConfig(configPath, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireAllSaved checks that configPath holds every config saved by
+// saveMany for each prefix.
+func requireAllSaved(t *testing.T, configPath string, prefixes []string, n int) {
+	t.Helper()
+	configs, err := LoadConfigs(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfigs failed: %v", err)
+	}
+	saved := make(map[string]bool)
+	for _, c := range configs {
+		saved[c.Name] = true
+	}
+	for _, prefix := range prefixes {
+		for i := range n {
+			if name := fmt.Sprintf("%s-%d", prefix, i); !saved[name] {
+				t.Errorf("config %s was lost", name)
+			}
+		}
+	}
+	if want := len(prefixes) * n; len(configs) != want {
+		t.Errorf("expected %d configs, got %d", want, len(configs))
+	}
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	const writers, perWriter = 8, 10
+
+	var prefixes []string
+	var wg sync.WaitGroup
+	errs := make(chan error, writers+1)
+	for w := range writers {
+		prefix := "w" + strconv.Itoa(w)
+		prefixes = append(prefixes, prefix)
+		wg.Go(func() {
+			if err := saveMany(configPath, prefix, perWriter); err != nil {
+				errs <- err
+			}
+		})
+	}
+
+	// Readers never see a partly written file.
+	done := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := LoadConfigs(configPath); err != nil {
+				errs <- fmt.Errorf("read during saves: %w", err)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+	<-readerDone
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	requireAllSaved(t, configPath, prefixes, perWriter)
+}
+
+// TestSaveHelperProcess is run by TestConcurrentSavesAcrossProcesses in
+// child processes; on its own it does nothing.
+func TestSaveHelperProcess(t *testing.T) {
+	configPath := os.Getenv("DEVSERVE_TEST_CONFIG")
+	if configPath == "" {
+		return
+	}
+	n, _ := strconv.Atoi(os.Getenv("DEVSERVE_TEST_COUNT"))
+	if err := saveMany(configPath, os.Getenv("DEVSERVE_TEST_PREFIX"), n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentSavesAcrossProcesses(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	const procs, perProc = 4, 10
+
+	var prefixes []string
+	var cmds []*exec.Cmd
+	for p := range procs {
+		prefix := "p" + strconv.Itoa(p)
+		prefixes = append(prefixes, prefix)
+		cmd := exec.Command(os.Args[0], "-test.run=^TestSaveHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			"DEVSERVE_TEST_CONFIG="+configPath,
+			"DEVSERVE_TEST_PREFIX="+prefix,
+			"DEVSERVE_TEST_COUNT="+strconv.Itoa(perProc),
+		)
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	// This process saves too.
+	if err := saveMany(configPath, "parent", perProc); err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper process failed: %v", err)
+		}
+	}
+	requireAllSaved(t, configPath, append(prefixes, "parent"), perProc)
+}
+
+func TestSaveFileMode(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	// Older versions wrote the file executable and world readable.
+	writeFile(t, configPath, `[]`)
+	if err := os.Chmod(configPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveConfig(configPath, ProcessConfig{Name: "web", Port: 3000, Command: "true", Directory: "/tmp"}); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	for _, path := range []string{configPath, BackupPath(configPath)} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != ConfigFilePermissions {
+			t.Errorf("%s has mode %v, want %v", filepath.Base(path), info.Mode().Perm(), ConfigFilePermissions)
+		}
+	}
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"config.json", "config.json.lock", "config.json.v1.bak"}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("directory holds %v, want %v", names, want)
+	}
+}
+
+func TestSaveThroughSymlink(t *testing.T) {
+	// A dotfiles repository links the config file into place.
+	dotfiles := t.TempDir()
+	target := filepath.Join(dotfiles, "devserve.json")
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.Symlink(target, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"web", "api"} {
+		if err := SaveConfig(configPath, ProcessConfig{Name: name, Port: 3000 + len(name), Command: "true", Directory: "/tmp"}); err != nil {
+			t.Fatalf("SaveConfig failed: %v", err)
+		}
+	}
+
+	info, err := os.Lstat(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected %s to stay a symlink, got mode %v", configPath, info.Mode())
+	}
+	configs, err := LoadConfigs(target)
+	if err != nil || len(configs) != 2 {
+		t.Errorf("expected both configs in the link's target, got %+v, %v", configs, err)
+	}
+}
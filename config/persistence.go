@@ -1,10 +1,12 @@
 package config
 
 import (
+	"github.com/jaiir320/devserve/protocol"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // ProcessConfig represents a saved process configuration
@@ -13,59 +15,228 @@ type ProcessConfig struct {
 	Port      int    `json:"port"`
 	Command   string `json:"command"`
 	Directory string `json:"directory"`
+
+	// Args runs the process directly, without a shell, instead of Command.
+	Args []string `json:"args,omitempty"`
+	// Shell runs Command and hooks: "bash" (bash -lc), "zsh" (zsh -ic),
+	// "$SHELL" or a path. Defaults to sh.
+	Shell string `json:"shell,omitempty"`
+
+	// TTY runs the process on a pseudo-terminal; its output, escape
+	// sequences included unless StripANSI is set, goes to the stdout log.
+	TTY       bool `json:"tty,omitempty"`
+	StripANSI bool `json:"strip_ansi,omitempty"`
+
+	// Resource limits, enforced with cgroups v2 when available.
+	MemoryMax string `json:"memory_max,omitempty"` // e.g. "512M", "2G"
+	CPUQuota  string `json:"cpu_quota,omitempty"`  // e.g. "150%" or "1.5" CPUs
+	PidsMax   int    `json:"pids_max,omitempty"`
+
+	// Stop behaviour; defaults to SIGTERM with a 5s grace period.
+	StopSignal  string `json:"stop_signal,omitempty"`  // e.g. "SIGINT"
+	StopTimeout string `json:"stop_timeout,omitempty"` // e.g. "10s"
+	PreStop     string `json:"pre_stop,omitempty"`     // command run before the stop signal
+
+	// Lifecycle hooks, run in Directory with logs under .devserve/hooks/.
+	PreStart  string `json:"pre_start,omitempty"`  // e.g. "npm install"; failure aborts the start
+	PostReady string `json:"post_ready,omitempty"` // e.g. "npm run seed"
+	PostStop  string `json:"post_stop,omitempty"`
+
+	// ReadyWhenLogMatches is a regular expression; the process is ready
+	// once a line of its output matches, as well as its port accepting
+	// connections. For servers that bind their port before they're done
+	// compiling.
+	ReadyWhenLogMatches string `json:"ready_when_log_matches,omitempty"`
+	// StartTimeout is how long to wait for the process to become ready,
+	// e.g. "2m" for slow first builds. Defaults to 15s.
+	StartTimeout string `json:"start_timeout,omitempty"`
+	// Host is where the server listens, e.g. "::1" or a container IP.
+	// Defaults to trying both 127.0.0.1 and ::1.
+	Host string `json:"host,omitempty"`
+
+	// Env sets environment variables for the command and its hooks, on
+	// top of the daemon's environment.
+	Env map[string]string `json:"env,omitempty"`
+
+	// Tags label the process for grouping in the TUI, e.g. "frontend".
+	Tags []string `json:"tags,omitempty"`
 }
 
-// LoadConfigs loads all saved process configurations from the config file
-func LoadConfigs(configPath string) ([]ProcessConfig, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []ProcessConfig{}, nil
+// CommandLine returns the command for display: Command, or Args quoted
+// as a shell would need them.
+func (c ProcessConfig) CommandLine() string {
+	if len(c.Args) > 0 {
+		return JoinArgs(c.Args)
+	}
+	return c.Command
+}
+
+// JoinArgs joins argv into a single shell-quoted string.
+func JoinArgs(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,") == "" {
+			quoted[i] = arg
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
 		}
-		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	return strings.Join(quoted, " ")
+}
 
-	var configs []ProcessConfig
-	if err := json.Unmarshal(data, &configs); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+// FromProcessInfo builds a config from a running process's details.
+func FromProcessInfo(info *protocol.ProcessInfo) ProcessConfig {
+	command := info.Command
+	if len(info.Args) > 0 {
+		command = "" // only a display form of Args
 	}
+	return ProcessConfig{
+		Name:      info.Name,
+		Port:      info.Port,
+		Command:   command,
+		Directory: info.Dir,
+		Args:      info.Args,
+		Shell:     info.Shell,
+		TTY:       info.TTY,
+		StripANSI: info.StripANSI,
+		MemoryMax: info.MemoryMax,
+		CPUQuota:  info.CPUQuota,
+		PidsMax:   info.PidsMax,
+
+		StopSignal:  info.StopSignal,
+		StopTimeout: info.StopTimeout,
+		PreStop:     info.PreStop,
+
+		PreStart:  info.PreStart,
+		PostReady: info.PostReady,
+		PostStop:  info.PostStop,
 
-	return configs, nil
+		ReadyWhenLogMatches: info.ReadyWhenLogMatches,
+		StartTimeout:        info.StartTimeout,
+		Host:                info.Host,
+		Env:                 info.Env,
+		Tags:                info.Tags,
+	}
 }
 
-// SaveConfig saves a process configuration to the config file
-func SaveConfig(configPath string, config ProcessConfig) error {
-	configs, err := LoadConfigs(configPath)
+// LoadConfigs loads all saved process configurations from the config file
+func LoadConfigs(configPath string) ([]ProcessConfig, error) {
+	doc, err := LoadDocument(configPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return doc.Processes, nil
+}
+
+// SaveConfig saves a process configuration to the config file
+func SaveConfig(configPath string, config ProcessConfig) error {
+	return updateDocument(configPath, func(doc *Document) error {
+		doc.Processes = upsert(doc.Processes, config)
+		return nil
+	})
+}
 
-	// Check if config already exists, replace it
-	found := false
+// upsert replaces the config with cfg's name, or appends cfg.
+func upsert(configs []ProcessConfig, cfg ProcessConfig) []ProcessConfig {
 	for i, c := range configs {
-		if c.Name == config.Name {
-			configs[i] = config
-			found = true
-			break
+		if c.Name == cfg.Name {
+			configs[i] = cfg
+			return configs
 		}
 	}
+	return append(configs, cfg)
+}
 
-	if !found {
-		configs = append(configs, config)
-	}
+// SaveProcessInfo saves the config of a running process, leaving out the
+// settings it only has from the process defaults.
+func SaveProcessInfo(configPath string, info *protocol.ProcessInfo) error {
+	return updateDocument(configPath, func(doc *Document) error {
+		defaults, err := defaultsWithEnv(doc.Defaults)
+		if err != nil {
+			return err
+		}
+		doc.Processes = upsert(doc.Processes, defaults.Strip(FromProcessInfo(info)))
+		return nil
+	})
+}
 
+// ReplaceConfig replaces the config named oldName with cfg, keeping its
+// place in the file, or adds cfg when oldName is "". It fails if another
+// config already has cfg's name.
+func ReplaceConfig(configPath string, oldName string, cfg ProcessConfig) error {
+	return updateDocument(configPath, func(doc *Document) error {
+		index := -1
+		for i, c := range doc.Processes {
+			switch {
+			case c.Name == oldName && oldName != "":
+				index = i
+			case c.Name == cfg.Name:
+				return protocol.WithKind(fmt.Errorf("config '%s' already exists", cfg.Name), protocol.ErrConflict)
+			}
+		}
+
+		switch {
+		case index >= 0:
+			doc.Processes[index] = cfg
+		case oldName != "":
+			return protocol.WithKind(fmt.Errorf("config '%s' not found", oldName), protocol.ErrNotFound)
+		default:
+			doc.Processes = append(doc.Processes, cfg)
+		}
+		return nil
+	})
+}
+
+// AddConfigs adds cfgs to the config file in one save. It fails, adding
+// none of them, if any name is already taken.
+func AddConfigs(configPath string, cfgs []ProcessConfig) error {
+	return updateDocument(configPath, func(doc *Document) error {
+		for _, cfg := range cfgs {
+			if _, err := doc.find(cfg.Name); err == nil {
+				return protocol.WithKind(fmt.Errorf("config '%s' already exists", cfg.Name), protocol.ErrConflict)
+			}
+			doc.Processes = append(doc.Processes, cfg)
+		}
+		return nil
+	})
+}
+
+// updateDocument loads the config file, lets fn change it and writes it
+// back, holding the config lock throughout so concurrent saves from the
+// TUI and the CLI don't lose each other's changes. Nothing is written if
+// fn fails.
+func updateDocument(configPath string, fn func(doc *Document) error) error {
+	return withLock(configPath, func() error {
+		doc, err := loadDocumentLocked(configPath)
+		if err != nil {
+			return err
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+		return writeDocument(configPath, doc)
+	})
+}
+
+// writeDocument atomically writes doc to the config file in the current
+// layout, creating its directory. The caller holds the config lock.
+func writeDocument(configPath string, doc *Document) error {
 	// Ensure directory exists
 	dir := filepath.Dir(configPath)
 	if err := os.MkdirAll(dir, DirPermissions); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(configs, "", "  ")
+	doc.Version = ConfigVersion
+	if doc.Processes == nil {
+		doc.Processes = []ProcessConfig{}
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, DirPermissions); err != nil {
+	if err := writeFileAtomic(configPath, data, ConfigFilePermissions); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -74,58 +245,75 @@ func SaveConfig(configPath string, config ProcessConfig) error {
 
 // GetConfig retrieves a single process configuration by name
 func GetConfig(configPath string, name string) (*ProcessConfig, error) {
-	configs, err := LoadConfigs(configPath)
+	doc, err := LoadDocument(configPath)
 	if err != nil {
 		return nil, err
 	}
+	return doc.find(name)
+}
 
-	for _, c := range configs {
+// ResolveConfig is GetConfig with the process defaults applied, see
+// LoadDefaults: the settings the process is started with.
+func ResolveConfig(configPath string, name string) (*ProcessConfig, error) {
+	doc, err := LoadDocument(configPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := doc.find(name)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := defaultsWithEnv(doc.Defaults)
+	if err != nil {
+		return nil, err
+	}
+	resolved := defaults.Apply(*cfg)
+	return &resolved, nil
+}
+
+// find returns the process named name.
+func (d *Document) find(name string) (*ProcessConfig, error) {
+	for _, c := range d.Processes {
 		if c.Name == name {
 			return &c, nil
 		}
 	}
 
-	return nil, fmt.Errorf("config '%s' not found", name)
+	return nil, protocol.WithKind(fmt.Errorf("config '%s' not found", name), protocol.ErrNotFound)
 }
 
 // DeleteConfig removes a process configuration from the config file
 func DeleteConfig(configPath string, name string) error {
-	configs, err := LoadConfigs(configPath)
-	if err != nil {
-		return err
-	}
-
-	// Find and remove the config with the given name
-	found := false
-	newConfigs := make([]ProcessConfig, 0, len(configs))
-	for _, c := range configs {
-		if c.Name != name {
-			newConfigs = append(newConfigs, c)
-		} else {
-			found = true
+	return withLock(configPath, func() error {
+		doc, err := loadDocumentLocked(configPath)
+		if err != nil {
+			return err
 		}
-	}
 
-	if !found {
-		return fmt.Errorf("config '%s' not found", name)
-	}
-
-	// If no configs remain, delete the file
-	if len(newConfigs) == 0 {
-		if err := os.Remove(configPath); err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("failed to remove config file: %w", err)
+		// Find and remove the config with the given name
+		found := false
+		newConfigs := make([]ProcessConfig, 0, len(doc.Processes))
+		for _, c := range doc.Processes {
+			if c.Name != name {
+				newConfigs = append(newConfigs, c)
+			} else {
+				found = true
+			}
 		}
-		return nil
-	}
 
-	data, err := json.MarshalIndent(newConfigs, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
+		if !found {
+			return protocol.WithKind(fmt.Errorf("config '%s' not found", name), protocol.ErrNotFound)
+		}
 
-	if err := os.WriteFile(configPath, data, DirPermissions); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
-	}
+		// If nothing remains, delete the file
+		if len(newConfigs) == 0 && doc.Defaults.IsZero() {
+			if err := os.Remove(configPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove config file: %w", err)
+			}
+			return nil
+		}
 
-	return nil
+		doc.Processes = newConfigs
+		return writeDocument(configPath, doc)
+	})
 }
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"github.com/jaiir320/devserve/protocol"
+	"errors"
 	"path/filepath"
 	"testing"
 )
@@ -100,6 +102,74 @@ func TestDeleteConfig(t *testing.T) {
 	}
 }
 
+func TestReplaceConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	config1 := ProcessConfig{Name: "app1", Port: 3000, Command: "npm start", Directory: "/app1"}
+	config2 := ProcessConfig{Name: "app2", Port: 4000, Command: "npm run dev", Directory: "/app2"}
+	for _, c := range []ProcessConfig{config1, config2} {
+		if err := ReplaceConfig(configPath, "", c); err != nil {
+			t.Fatalf("ReplaceConfig failed: %v", err)
+		}
+	}
+
+	// Renaming keeps the config's place in the file.
+	renamed := config1
+	renamed.Name = "web"
+	renamed.Env = map[string]string{"NODE_ENV": "development"}
+	if err := ReplaceConfig(configPath, "app1", renamed); err != nil {
+		t.Fatalf("ReplaceConfig failed: %v", err)
+	}
+	configs, err := LoadConfigs(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfigs failed: %v", err)
+	}
+	if len(configs) != 2 || configs[0].Name != "web" || configs[1].Name != "app2" {
+		t.Fatalf("expected [web app2], got %+v", configs)
+	}
+	if configs[0].Env["NODE_ENV"] != "development" {
+		t.Errorf("expected env to be saved, got %v", configs[0].Env)
+	}
+
+	// Editing a config without renaming it is not a conflict.
+	renamed.Port = 3001
+	if err := ReplaceConfig(configPath, "web", renamed); err != nil {
+		t.Errorf("ReplaceConfig failed: %v", err)
+	}
+
+	if err := ReplaceConfig(configPath, "web", config2); !errors.Is(err, protocol.ErrConflict) {
+		t.Errorf("expected a conflict renaming onto app2, got %v", err)
+	}
+	if err := ReplaceConfig(configPath, "", config2); !errors.Is(err, protocol.ErrConflict) {
+		t.Errorf("expected a conflict adding app2 again, got %v", err)
+	}
+	if err := ReplaceConfig(configPath, "missing", config1); !errors.Is(err, protocol.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestAddConfigs(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	web := ProcessConfig{Name: "web", Port: 3000, Command: "npm run dev", Directory: "/web"}
+	api := ProcessConfig{Name: "api", Port: 4000, Command: "go run .", Directory: "/api"}
+	if err := AddConfigs(configPath, []ProcessConfig{web, api}); err != nil {
+		t.Fatalf("AddConfigs failed: %v", err)
+	}
+
+	// A taken name fails the whole batch.
+	docs := ProcessConfig{Name: "docs", Port: 5000, Command: "mkdocs serve", Directory: "/docs"}
+	if err := AddConfigs(configPath, []ProcessConfig{docs, web}); !errors.Is(err, protocol.ErrConflict) {
+		t.Errorf("expected a conflict adding web again, got %v", err)
+	}
+	configs, err := LoadConfigs(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfigs failed: %v", err)
+	}
+	if len(configs) != 2 || configs[0].Name != "web" || configs[1].Name != "api" {
+		t.Errorf("expected [web api], got %+v", configs)
+	}
+}
+
 func TestGetConfig(t *testing.T) {
 	tempDir := t.TempDir()
 	configPath := filepath.Join(tempDir, "config.json")
@@ -141,3 +211,16 @@ func TestGetConfig(t *testing.T) {
 		t.Error("expected nil for non-existent config")
 	}
 }
+
+func TestCommandLine(t *testing.T) {
+	cfg := ProcessConfig{Command: "npm run dev"}
+	if got := cfg.CommandLine(); got != "npm run dev" {
+		t.Errorf("expected command, got %q", got)
+	}
+
+	cfg = ProcessConfig{Args: []string{"node", "server.js", "--title", "my app", "it's", ""}}
+	want := `node server.js --title 'my app' 'it'\''s' ''`
+	if got := cfg.CommandLine(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
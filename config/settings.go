@@ -0,0 +1,566 @@
+package config
+
+import (
+	"github.com/jaiir320/devserve/protocol"
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Where a setting's value comes from, lowest precedence first
+const (
+	SourceDefault = "default" // built in
+	SourceFile    = "file"    // the settings file
+	SourceConfig  = "config"  // the config file's defaults, for defaults.* keys
+	SourceEnv     = "env"     // a DEVSERVE_* environment variable
+)
+
+// SettingValue is a setting's value and where it came from.
+type SettingValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Env    string `json:"env,omitempty"` // the variable overriding it
+	Help   string `json:"help"`
+}
+
+// setting is one of devserve's tunables: a key in the settings file,
+// such as "timeouts.start" for start under [timeouts], backed by one of
+// the variables in this package.
+type setting struct {
+	key     string
+	help    string
+	get     func() string
+	parse   func(string) error // validates a value
+	assign  func(string)       // sets a value parse accepted
+	builtin string
+	source  string
+}
+
+// settingEnv returns the environment variable overriding key: DEVSERVE_
+// and the key in upper case with dots as underscores. No key maps to
+// ProcessEnvVar, which devserve sets itself.
+func settingEnv(key string) string {
+	return "DEVSERVE_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func durationSetting(key, help string, v *time.Duration) *setting {
+	return &setting{
+		key:  key,
+		help: help,
+		get:  func() string { return formatDuration(*v) },
+		parse: func(s string) error {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("expected a duration like \"10s\", got %q", s)
+			}
+			return nil
+		},
+		assign: func(s string) { *v, _ = time.ParseDuration(s) },
+	}
+}
+
+func stringSetting(key, help string, v *string, parse func(string) error) *setting {
+	return &setting{
+		key:    key,
+		help:   help,
+		get:    func() string { return *v },
+		parse:  parse,
+		assign: func(s string) { *v = s },
+	}
+}
+
+// absolutePath accepts an absolute path.
+func absolutePath(s string) error {
+	if !filepath.IsAbs(s) {
+		return fmt.Errorf("expected an absolute path, got %q", s)
+	}
+	return nil
+}
+
+// relativePath accepts a path within the process's directory.
+func relativePath(s string) error {
+	if s == "" || filepath.IsAbs(s) || !filepath.IsLocal(s) {
+		return fmt.Errorf("expected a path relative to the process's directory, got %q", s)
+	}
+	return nil
+}
+
+// fileName accepts a file name without a directory.
+func fileName(s string) error {
+	if s == "" || s == "." || s == ".." || strings.ContainsRune(s, filepath.Separator) {
+		return fmt.Errorf("expected a file name, got %q", s)
+	}
+	return nil
+}
+
+func oneOf(choices ...string) func(string) error {
+	return func(s string) error {
+		if !slices.Contains(choices, s) {
+			return fmt.Errorf("expected one of %s, got %q", strings.Join(choices, ", "), s)
+		}
+		return nil
+	}
+}
+
+// settings are the tunables, in the order devserve config get lists them.
+var settings = []*setting{
+	stringSetting("tunnel", "tunnel provider: tailscale, or none for local URLs only", &Tunnel, oneOf("tailscale", "none")),
+	stringSetting("paths.socket", "the daemon's socket", &Socket, absolutePath),
+	stringSetting("paths.daemon_dir", "directory of the daemon's log", &DaemonDir, absolutePath),
+	stringSetting("logs.daemon", "the daemon's log file, in paths.daemon_dir", &DaemonLogFile, fileName),
+	stringSetting("logs.dir", "directory of each process's logs, relative to its directory", &ProcessLogDir, relativePath),
+	stringSetting("logs.stdout", "file name of each process's stdout log", &ProcessStdoutLog, fileName),
+	stringSetting("logs.stderr", "file name of each process's stderr log", &ProcessStderrLog, fileName),
+	durationSetting("timeouts.start", "how long to wait for a process to become ready, unless it sets start_timeout", &PortWaitTimeout),
+	durationSetting("timeouts.stop_grace_period", "how long a stopping process gets before SIGKILL, unless it sets stop_timeout", &StopGracePeriod),
+	durationSetting("timeouts.pre_stop", "how long a pre-stop command may run", &PreStopTimeout),
+	durationSetting("timeouts.pre_start", "how long a pre-start command may run", &PreStartTimeout),
+	durationSetting("timeouts.post_ready", "how long a post-ready command may run", &PostReadyTimeout),
+	durationSetting("timeouts.post_stop", "how long a post-stop command may run", &PostStopTimeout),
+	durationSetting("timeouts.shutdown", "how long daemon shutdown waits for processes beyond their stop grace period", &ShutdownTimeout),
+	durationSetting("intervals.port_release_poll", "how often a stopped process's port is checked until it is released", &PortReleasePollInterval),
+	durationSetting("intervals.ready_poll", "how often a starting process is checked for readiness", &ReadyPollInterval),
+	durationSetting("intervals.usage_sample", "how often CPU and memory usage is sampled and the TUI refreshes", &UsageSampleInterval),
+}
+
+func init() {
+	for _, s := range settings {
+		s.builtin = s.get()
+		s.source = SourceDefault
+	}
+}
+
+// lookupSetting returns the setting for key, or an error suggesting the
+// key probably meant.
+func lookupSetting(key string) (*setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return nil, unknownSetting(key)
+}
+
+// unknownSetting returns the error for a key that is not a setting.
+func unknownSetting(key string) error {
+	keys := make([]string, 0, len(settings)+len(defaultFields))
+	for _, s := range settings {
+		keys = append(keys, s.key)
+	}
+	for _, f := range defaultFields {
+		keys = append(keys, "defaults."+f.key)
+	}
+	msg := fmt.Sprintf("unknown setting %q", key)
+	if guess := closest(key, keys); guess != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", guess)
+	}
+	return protocol.WithKind(errors.New(msg), protocol.ErrInvalid)
+}
+
+// LoadSettings applies the settings file and then DEVSERVE_* environment
+// variables over the built-in values of the tunables in this package. It
+// runs once, before anything uses them. Invalid values are skipped,
+// leaving the tunable as it was, and reported together.
+func LoadSettings() error {
+	values, err := readSettingsFile(SettingsFile)
+	if err != nil {
+		return err
+	}
+	var problems []error
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if strings.HasPrefix(key, "defaults.") {
+			problems = append(problems, protocol.WithKind(fmt.Errorf("invalid settings file %s: %s: process defaults are kept in the config file; set them with devserve config set", SettingsFile, key), protocol.ErrInvalid))
+			continue
+		}
+		s, err := lookupSetting(key)
+		if err != nil {
+			problems = append(problems, protocol.WithKind(fmt.Errorf("invalid settings file %s: %w", SettingsFile, err), protocol.ErrInvalid))
+			continue
+		}
+		if err := s.parse(values[key]); err != nil {
+			problems = append(problems, protocol.WithKind(fmt.Errorf("invalid settings file %s: %s: %w", SettingsFile, key, err), protocol.ErrInvalid))
+			continue
+		}
+		s.assign(values[key])
+		s.source = SourceFile
+	}
+
+	for _, s := range settings {
+		value, ok := os.LookupEnv(settingEnv(s.key))
+		if !ok {
+			continue
+		}
+		if err := s.parse(value); err != nil {
+			problems = append(problems, protocol.WithKind(fmt.Errorf("invalid %s: %w", settingEnv(s.key), err), protocol.ErrInvalid))
+			continue
+		}
+		s.assign(value)
+		s.source = SourceEnv
+	}
+	return errors.Join(problems...)
+}
+
+// readSettingsFile returns the settings in the file by key. A missing
+// file has none.
+func readSettingsFile(path string) (map[string]string, error) {
+	table, err := readSettingsTable(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := flattenSettings("", table, values); err != nil {
+		return nil, protocol.WithKind(fmt.Errorf("invalid settings file %s: %w", path, err), protocol.ErrInvalid)
+	}
+	return values, nil
+}
+
+// readSettingsTable decodes the settings file as TOML.
+func readSettingsTable(path string) (map[string]any, error) {
+	table := make(map[string]any)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return table, nil
+		}
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	if _, err := toml.Decode(string(data), &table); err != nil {
+		return nil, protocol.WithKind(fmt.Errorf("invalid settings file %s: %w", path, err), protocol.ErrInvalid)
+	}
+	return table, nil
+}
+
+// flattenSettings adds the values in table to values by dotted key.
+// Values are kept as written: strings as they are, others formatted.
+func flattenSettings(prefix string, table map[string]any, values map[string]string) error {
+	for key, v := range table {
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flattenSettings(prefix+key+".", v, values); err != nil {
+				return err
+			}
+		case string:
+			values[prefix+key] = v
+		case []any, []map[string]any:
+			return fmt.Errorf("%s%s: expected a single value", prefix, key)
+		default:
+			values[prefix+key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// updateSettingsFile lets fn change the settings file's table and writes
+// it back, holding its lock like updateDocument does the config file's.
+func updateSettingsFile(fn func(table map[string]any)) error {
+	return withLock(SettingsFile, func() error {
+		table, err := readSettingsTable(SettingsFile)
+		if err != nil {
+			return err
+		}
+		fn(table)
+
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(table); err != nil {
+			return fmt.Errorf("failed to encode settings: %w", err)
+		}
+		if err := writeFileAtomic(SettingsFile, buf.Bytes(), SettingsFilePermissions); err != nil {
+			return fmt.Errorf("failed to write settings file: %w", err)
+		}
+		return nil
+	})
+}
+
+// defaultField is a process default under "defaults." in devserve config
+// get and set. Unlike the settings, these are stored in the config file,
+// next to the processes they apply to. There is no restart policy among
+// them: devserve never restarts a process on its own, so there is no
+// policy to default.
+type defaultField struct {
+	key  string
+	help string
+	ptr  func(d *Defaults) *string
+}
+
+var defaultFields = []defaultField{
+	{"shell", "shell for commands and hooks", func(d *Defaults) *string { return &d.Shell }},
+	{"stop_signal", "signal sent to stop a process", func(d *Defaults) *string { return &d.StopSignal }},
+	{"stop_timeout", "grace period before SIGKILL", func(d *Defaults) *string { return &d.StopTimeout }},
+	{"start_timeout", "how long to wait for a process to become ready", func(d *Defaults) *string { return &d.StartTimeout }},
+	{"memory_max", "memory limit, e.g. 2G", func(d *Defaults) *string { return &d.MemoryMax }},
+	{"cpu_quota", "CPU limit, e.g. 150%", func(d *Defaults) *string { return &d.CPUQuota }},
+	{"pids_max", "maximum number of processes", nil},
+	{"env", "environment variables, as defaults.env.NAME", nil},
+}
+
+// setDefault sets the process default key, without its "defaults."
+// prefix, in d. An empty value unsets it.
+func setDefault(d *Defaults, key, value string) error {
+	if name, ok := strings.CutPrefix(key, "env."); ok {
+		if !validEnvName(name) {
+			return protocol.WithKind(fmt.Errorf("invalid environment variable name %q", name), protocol.ErrInvalid)
+		}
+		if value == "" {
+			delete(d.Env, name)
+			return nil
+		}
+		if d.Env == nil {
+			d.Env = make(map[string]string)
+		}
+		d.Env[name] = value
+		return nil
+	}
+
+	for _, f := range defaultFields {
+		if f.key != key || f.key == "env" {
+			continue
+		}
+		if f.key == "pids_max" {
+			n, err := strconv.Atoi(value)
+			if value == "" {
+				n, err = 0, nil
+			}
+			if err != nil || n < 0 {
+				return protocol.WithKind(fmt.Errorf("defaults.pids_max: expected a whole number, got %q", value), protocol.ErrInvalid)
+			}
+			d.PidsMax = n
+			return nil
+		}
+		// Checked as the config file's defaults are, so a value set
+		// here cannot make the file invalid.
+		var check Defaults
+		*f.ptr(&check) = value
+		if problems := validateSettings(check.Apply(ProcessConfig{})); len(problems) > 0 {
+			return protocol.WithKind(fmt.Errorf("defaults: %w", problems[0]), protocol.ErrInvalid)
+		}
+		*f.ptr(d) = value
+		return nil
+	}
+	return unknownSetting("defaults." + key)
+}
+
+// getDefault returns the process default key, without its "defaults."
+// prefix, from d.
+func getDefault(d Defaults, key string) (string, error) {
+	if name, ok := strings.CutPrefix(key, "env."); ok {
+		return d.Env[name], nil
+	}
+	for _, f := range defaultFields {
+		switch {
+		case f.key != key:
+		case key == "pids_max":
+			if d.PidsMax == 0 {
+				return "", nil
+			}
+			return strconv.Itoa(d.PidsMax), nil
+		case key == "env":
+			return formatEnvList(d.Env), nil
+		default:
+			return *f.ptr(&d), nil
+		}
+	}
+	return "", unknownSetting("defaults." + key)
+}
+
+// formatEnvList formats env as NAME=value pairs separated by spaces.
+func formatEnvList(env map[string]string) string {
+	pairs := make([]string, 0, len(env))
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		pairs = append(pairs, name+"="+env[name])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// LoadDefaults returns the process defaults: the config file's, with
+// DEVSERVE_DEFAULTS_* environment variables overriding them.
+func LoadDefaults(configPath string) (Defaults, error) {
+	doc, err := LoadDocument(configPath)
+	if err != nil {
+		return Defaults{}, err
+	}
+	return defaultsWithEnv(doc.Defaults)
+}
+
+// defaultsWithEnv applies the DEVSERVE_DEFAULTS_* environment variables
+// over d.
+func defaultsWithEnv(d Defaults) (Defaults, error) {
+	d.Env = maps.Clone(d.Env)
+	for _, f := range defaultFields {
+		if f.key == "env" {
+			continue
+		}
+		env := settingEnv("defaults." + f.key)
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			if err := setDefault(&d, f.key, value); err != nil {
+				return d, protocol.WithKind(fmt.Errorf("invalid %s: %w", env, err), protocol.ErrInvalid)
+			}
+		}
+	}
+	return d, nil
+}
+
+// Settings returns every setting with its value and source, the process
+// defaults in configPath included.
+func Settings(configPath string) ([]SettingValue, error) {
+	values := make([]SettingValue, 0, len(settings)+len(defaultFields))
+	for _, s := range settings {
+		values = append(values, SettingValue{Key: s.key, Value: s.get(), Source: s.source, Env: settingEnv(s.key), Help: s.help})
+	}
+
+	doc, err := LoadDocument(configPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range defaultFields {
+		v, err := defaultValue(doc.Defaults, f.key)
+		if err != nil {
+			return nil, err
+		}
+		v.Help = f.help
+		values = append(values, *v)
+	}
+	return values, nil
+}
+
+// GetSetting returns a single setting, or process default in configPath.
+func GetSetting(configPath, key string) (*SettingValue, error) {
+	if field, ok := strings.CutPrefix(key, "defaults."); ok {
+		doc, err := LoadDocument(configPath)
+		if err != nil {
+			return nil, err
+		}
+		return defaultValue(doc.Defaults, field)
+	}
+	s, err := lookupSetting(key)
+	if err != nil {
+		return nil, err
+	}
+	return &SettingValue{Key: s.key, Value: s.get(), Source: s.source, Env: settingEnv(s.key), Help: s.help}, nil
+}
+
+// defaultValue returns the process default key, without its "defaults."
+// prefix, with environment overrides applied.
+func defaultValue(d Defaults, key string) (*SettingValue, error) {
+	value, err := getDefault(d, key)
+	if err != nil {
+		return nil, err
+	}
+	v := &SettingValue{Key: "defaults." + key, Value: value, Source: SourceDefault}
+	if value != "" {
+		v.Source = SourceConfig
+	}
+	if key != "env" && !strings.HasPrefix(key, "env.") {
+		v.Env = settingEnv(v.Key)
+		if env, ok := os.LookupEnv(v.Env); ok && env != "" {
+			v.Value, v.Source = env, SourceEnv
+		}
+	}
+	return v, nil
+}
+
+// SetSetting validates value and stores it for key: in the settings file,
+// or for process defaults in the config file at configPath.
+func SetSetting(configPath, key, value string) error {
+	if field, ok := strings.CutPrefix(key, "defaults."); ok {
+		if field == "env" {
+			return protocol.WithKind(fmt.Errorf("set environment variables one at a time, as defaults.env.NAME"), protocol.ErrInvalid)
+		}
+		return updateDocument(configPath, func(doc *Document) error {
+			return setDefault(&doc.Defaults, field, value)
+		})
+	}
+
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := s.parse(value); err != nil {
+		return protocol.WithKind(fmt.Errorf("%s: %w", key, err), protocol.ErrInvalid)
+	}
+	return updateSettingsFile(func(table map[string]any) {
+		path := strings.Split(key, ".")
+		for _, name := range path[:len(path)-1] {
+			sub, ok := table[name].(map[string]any)
+			if !ok {
+				sub = make(map[string]any)
+				table[name] = sub
+			}
+			table = sub
+		}
+		table[path[len(path)-1]] = value
+	})
+}
+
+// UnsetSetting removes key from the settings file, or the process
+// default from the config file, so the value below it applies again.
+func UnsetSetting(configPath, key string) error {
+	if field, ok := strings.CutPrefix(key, "defaults."); ok {
+		return updateDocument(configPath, func(doc *Document) error {
+			if field == "env" {
+				doc.Defaults.Env = nil
+				return nil
+			}
+			return setDefault(&doc.Defaults, field, "")
+		})
+	}
+
+	if _, err := lookupSetting(key); err != nil {
+		return err
+	}
+	return updateSettingsFile(func(table map[string]any) {
+		removeSetting(table, strings.Split(key, "."))
+	})
+}
+
+// removeSetting deletes the key at path from table, and any table left
+// empty by doing so.
+func removeSetting(table map[string]any, path []string) {
+	if len(path) == 1 {
+		delete(table, path[0])
+		return
+	}
+	sub, ok := table[path[0]].(map[string]any)
+	if !ok {
+		return
+	}
+	removeSetting(sub, path[1:])
+	if len(sub) == 0 {
+		delete(table, path[0])
+	}
+}
+
+// SettingsHelp lists the settings and process defaults with a line of
+// help each, for devserve config --help.
+func SettingsHelp() string {
+	var b strings.Builder
+	for _, s := range settings {
+		fmt.Fprintf(&b, "  %-28s %s (default %s)\n", s.key, s.help, s.builtin)
+	}
+	for _, f := range defaultFields {
+		fmt.Fprintf(&b, "  %-28s %s\n", "defaults."+f.key, f.help)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// formatDuration formats d without the zero units time.Duration.String
+// adds: "5m" rather than "5m0s".
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"github.com/jaiir320/devserve/protocol"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// swapSettingsFile points SettingsFile into a temporary directory and
+// restores every setting to its built-in value afterwards.
+func swapSettingsFile(t *testing.T) string {
+	t.Helper()
+	original := SettingsFile
+	SettingsFile = filepath.Join(t.TempDir(), "settings.toml")
+	t.Cleanup(func() {
+		SettingsFile = original
+		for _, s := range settings {
+			s.assign(s.builtin)
+			s.source = SourceDefault
+		}
+	})
+	return SettingsFile
+}
+
+func TestLoadSettingsPrecedence(t *testing.T) {
+	path := swapSettingsFile(t)
+	writeFile(t, path, `
+tunnel = "none"
+
+[timeouts]
+start = "1m"
+stop_grace_period = "8s"
+`)
+	t.Setenv("DEVSERVE_TIMEOUTS_START", "2m")
+
+	if err := LoadSettings(); err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	if PortWaitTimeout != 2*time.Minute {
+		t.Errorf("PortWaitTimeout = %s, want the environment's 2m", PortWaitTimeout)
+	}
+	if StopGracePeriod != 8*time.Second {
+		t.Errorf("StopGracePeriod = %s, want the file's 8s", StopGracePeriod)
+	}
+	if Tunnel != "none" {
+		t.Errorf("Tunnel = %q, want the file's none", Tunnel)
+	}
+	if ShutdownTimeout != 15*time.Second {
+		t.Errorf("ShutdownTimeout = %s, want the built-in 15s", ShutdownTimeout)
+	}
+
+	for key, want := range map[string]string{
+		"timeouts.start":             SourceEnv,
+		"timeouts.stop_grace_period": SourceFile,
+		"timeouts.shutdown":          SourceDefault,
+	} {
+		v, err := GetSetting(filepath.Join(t.TempDir(), "config.json"), key)
+		if err != nil {
+			t.Fatalf("GetSetting(%s) failed: %v", key, err)
+		}
+		if v.Source != want {
+			t.Errorf("%s source = %q, want %q", key, v.Source, want)
+		}
+	}
+}
+
+func TestLoadSettingsErrors(t *testing.T) {
+	tests := []struct {
+		name, file, env, want string
+	}{
+		{"unknown key", "[timeouts]\nstrat = \"1m\"\n", "", `unknown setting "timeouts.strat" (did you mean "timeouts.start"?)`},
+		{"invalid value", "[timeouts]\nstart = \"soon\"\n", "", `timeouts.start: expected a duration like "10s", got "soon"`},
+		{"invalid choice", "tunnel = \"ngrok\"\n", "", `expected one of tailscale, none, got "ngrok"`},
+		{"process defaults", "[defaults]\nshell = \"bash\"\n", "", "process defaults are kept in the config file"},
+		{"invalid toml", "tunnel = \n", "", "invalid settings file"},
+		{"invalid environment", "", "x", `invalid DEVSERVE_TIMEOUTS_START: expected a duration`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := swapSettingsFile(t)
+			writeFile(t, path, tt.file)
+			if tt.env != "" {
+				t.Setenv("DEVSERVE_TIMEOUTS_START", tt.env)
+			}
+
+			err := LoadSettings()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.want)
+			}
+			if !errors.Is(err, protocol.ErrInvalid) {
+				t.Errorf("expected ErrInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadSettingsSkipsInvalid(t *testing.T) {
+	path := swapSettingsFile(t)
+	writeFile(t, path, "tunnel = \"none\"\n\n[timeouts]\nstart = \"soon\"\nstrat = \"1m\"\n")
+
+	err := LoadSettings()
+	for _, want := range []string{"timeouts.start", "timeouts.strat"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want it to report %s", err, want)
+		}
+	}
+	if Tunnel != "none" {
+		t.Errorf("expected the valid setting to be applied, got tunnel %q", Tunnel)
+	}
+}
+
+func TestSetAndUnsetSetting(t *testing.T) {
+	path := swapSettingsFile(t)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	if err := SetSetting(configPath, "timeouts.start", "1m"); err != nil {
+		t.Fatalf("SetSetting failed: %v", err)
+	}
+	if err := SetSetting(configPath, "tunnel", "none"); err != nil {
+		t.Fatalf("SetSetting failed: %v", err)
+	}
+	if err := SetSetting(configPath, "timeouts.start", "never"); !errors.Is(err, protocol.ErrInvalid) {
+		t.Errorf("expected an invalid value to be refused, got %v", err)
+	}
+	if err := SetSetting(configPath, "timeout.start", "1m"); err == nil || !strings.Contains(err.Error(), `did you mean "timeouts.start"?`) {
+		t.Errorf("expected an unknown key to be refused with a suggestion, got %v", err)
+	}
+
+	values, err := readSettingsFile(path)
+	if err != nil {
+		t.Fatalf("readSettingsFile failed: %v", err)
+	}
+	if values["timeouts.start"] != "1m" || values["tunnel"] != "none" || len(values) != 2 {
+		t.Errorf("settings file holds %v", values)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != SettingsFilePermissions {
+		t.Errorf("settings file mode = %v, want %v", info.Mode().Perm(), SettingsFilePermissions)
+	}
+
+	if err := UnsetSetting(configPath, "timeouts.start"); err != nil {
+		t.Fatalf("UnsetSetting failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != `tunnel = "none"` {
+		t.Errorf("settings file after unset = %q, want the empty [timeouts] table removed", data)
+	}
+}
+
+func TestProcessDefaultSettings(t *testing.T) {
+	swapSettingsFile(t)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := SaveConfig(configPath, ProcessConfig{Name: "web", Port: 3000, Command: "npm run dev", Directory: "/srv/web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range map[string]string{
+		"defaults.shell":        "bash",
+		"defaults.stop_timeout": "10s",
+		"defaults.pids_max":     "64",
+		"defaults.env.NODE_ENV": "development",
+	} {
+		if err := SetSetting(configPath, key, value); err != nil {
+			t.Fatalf("SetSetting(%s) failed: %v", key, err)
+		}
+	}
+	for key, value := range map[string]string{
+		"defaults.pids_max":     "lots",
+		"defaults.stop_timeout": "soon",
+		"defaults.stop_signal":  "SIGNOPE",
+		"defaults.memory_max":   "lots",
+		"defaults.cpu_quota":    "fast",
+		"defaults.shell":        "bash -l",
+	} {
+		if err := SetSetting(configPath, key, value); !errors.Is(err, protocol.ErrInvalid) {
+			t.Errorf("SetSetting(%s, %q): expected it to be refused, got %v", key, value, err)
+		}
+	}
+
+	doc, err := LoadDocument(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Defaults{Shell: "bash", StopTimeout: "10s", PidsMax: 64, Env: map[string]string{"NODE_ENV": "development"}}
+	if doc.Defaults.Shell != want.Shell || doc.Defaults.StopTimeout != want.StopTimeout || doc.Defaults.PidsMax != want.PidsMax || doc.Defaults.Env["NODE_ENV"] != "development" {
+		t.Errorf("Defaults = %+v, want %+v", doc.Defaults, want)
+	}
+	if len(doc.Processes) != 1 {
+		t.Errorf("processes lost: %+v", doc.Processes)
+	}
+
+	// The environment overrides the config file's defaults, and the
+	// process's own settings override both.
+	t.Setenv("DEVSERVE_DEFAULTS_SHELL", "zsh")
+	t.Setenv("DEVSERVE_DEFAULTS_STOP_TIMEOUT", "20s")
+	cfg, err := ResolveConfig(configPath, "web")
+	if err != nil {
+		t.Fatalf("ResolveConfig failed: %v", err)
+	}
+	if cfg.Shell != "zsh" || cfg.StopTimeout != "20s" || cfg.PidsMax != 64 {
+		t.Errorf("resolved config = %+v", cfg)
+	}
+	v, err := GetSetting(configPath, "defaults.shell")
+	if err != nil || v.Value != "zsh" || v.Source != SourceEnv {
+		t.Errorf("GetSetting(defaults.shell) = %+v, %v", v, err)
+	}
+
+	if err := UnsetSetting(configPath, "defaults.env.NODE_ENV"); err != nil {
+		t.Fatalf("UnsetSetting failed: %v", err)
+	}
+	if v, _ := GetSetting(configPath, "defaults.env.NODE_ENV"); v.Value != "" {
+		t.Errorf("defaults.env.NODE_ENV = %q after unset", v.Value)
+	}
+}
+
+func TestSettingEnvNames(t *testing.T) {
+	seen := make(map[string]string)
+	keys := []string{}
+	for _, s := range settings {
+		keys = append(keys, s.key)
+	}
+	for _, f := range defaultFields {
+		keys = append(keys, "defaults."+f.key)
+	}
+	for _, key := range keys {
+		env := settingEnv(key)
+		if env == ProcessEnvVar {
+			t.Errorf("%s is overridden by %s, which devserve sets in every process", key, env)
+		}
+		if other, ok := seen[env]; ok {
+			t.Errorf("%s and %s share %s", key, other, env)
+		}
+		seen[env] = key
+	}
+	if got := settingEnv("timeouts.stop_grace_period"); got != "DEVSERVE_TIMEOUTS_STOP_GRACE_PERIOD" {
+		t.Errorf("settingEnv = %q", got)
+	}
+}
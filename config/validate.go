@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ValidateName checks that name can identify a process: non-empty, and
+// without whitespace or slashes since it names log and cgroup entries.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.ContainsAny(name, " \t\n/") {
+		return fmt.Errorf("name %q must not contain spaces or slashes", name)
+	}
+	return nil
+}
+
+// ValidatePort checks that port is a usable TCP port.
+func ValidatePort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port %d out of range 1-65535", port)
+	}
+	return nil
+}
+
+// ValidateDirectory checks that dir is an absolute path to an existing
+// directory.
+func ValidateDirectory(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("directory is required")
+	}
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("directory %q must be an absolute path", dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return missingDirError(dir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+	return nil
+}
+
+// ValidateShell checks a shell setting: "$SHELL", or the name or
+// absolute path of a shell, without arguments since the command is
+// passed to it as one.
+func ValidateShell(shell string) error {
+	switch {
+	case shell == "" || shell == "$SHELL":
+		return nil
+	case strings.ContainsAny(shell, " \t\n"):
+		return fmt.Errorf("invalid shell %q: expected a shell name or path, without arguments", shell)
+	case strings.HasPrefix(shell, "$"):
+		return fmt.Errorf("invalid shell %q: only $SHELL is expanded", shell)
+	case strings.Contains(shell, "/") && !filepath.IsAbs(shell):
+		return fmt.Errorf("invalid shell %q: a path must be absolute", shell)
+	}
+	return nil
+}
+
+// missingDirError is ValidateDirectory's error for a directory that does
+// not exist; it matches fs.ErrNotExist.
+type missingDirError string
+
+func (e missingDirError) Error() string {
+	return fmt.Sprintf("directory %q does not exist", string(e))
+}
+
+func (e missingDirError) Unwrap() error { return fs.ErrNotExist }
+
+// ParseEnv parses NAME=value pairs, as given to --env or one per line in
+// the TUI. Blank entries are skipped.
+func ParseEnv(pairs []string) (map[string]string, error) {
+	var env map[string]string
+	for _, pair := range pairs {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !validEnvName(name) {
+			return nil, fmt.Errorf("invalid environment variable %q: expected NAME=value", pair)
+		}
+		if env == nil {
+			env = make(map[string]string)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// validEnvName reports whether name is a valid environment variable name.
+func validEnvName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateDocument checks every process in doc and the defaults, and
+// returns all the problems found rather than stopping at the first.
+func ValidateDocument(doc *Document) []error {
+	var problems []error
+	names := make(map[string]string)
+	ports := make(map[int]string)
+	for i, cfg := range doc.Processes {
+		where := fmt.Sprintf("process %d", i+1)
+		if cfg.Name != "" {
+			where = fmt.Sprintf("process %d (%q)", i+1, cfg.Name)
+		}
+		for _, err := range validateProcess(cfg) {
+			problems = append(problems, fmt.Errorf("%s: %w", where, err))
+		}
+		if other, ok := names[cfg.Name]; ok && cfg.Name != "" {
+			problems = append(problems, fmt.Errorf("%s: name is also used by %s", where, other))
+		} else {
+			names[cfg.Name] = where
+		}
+		if other, ok := ports[cfg.Port]; ok && cfg.Port != 0 {
+			problems = append(problems, fmt.Errorf("%s: port %d is also used by %s", where, cfg.Port, other))
+		} else {
+			ports[cfg.Port] = where
+		}
+	}
+	for _, err := range validateSettings(doc.Defaults.Apply(ProcessConfig{})) {
+		problems = append(problems, fmt.Errorf("defaults: %w", err))
+	}
+	return problems
+}
+
+// validateProcess checks a single process config.
+func validateProcess(cfg ProcessConfig) []error {
+	var problems []error
+	add := func(err error) {
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+	add(ValidateName(cfg.Name))
+	add(ValidatePort(cfg.Port))
+	switch {
+	case cfg.Command == "" && len(cfg.Args) == 0:
+		add(fmt.Errorf("command or args is required"))
+	case cfg.Command != "" && len(cfg.Args) > 0:
+		add(fmt.Errorf("set command or args, not both"))
+	}
+	add(ValidateDirectory(cfg.Directory))
+	if cfg.ReadyWhenLogMatches != "" {
+		if _, err := regexp.Compile(cfg.ReadyWhenLogMatches); err != nil {
+			add(fmt.Errorf("invalid ready_when_log_matches: %w", err))
+		}
+	}
+	return append(problems, validateSettings(cfg)...)
+}
+
+// validateSettings checks the settings a process shares with the defaults
+// that can be checked without starting it.
+func validateSettings(cfg ProcessConfig) []error {
+	var problems []error
+	add := func(err error) {
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if cfg.StopSignal != "" {
+		if _, err := ParseSignal(cfg.StopSignal); err != nil {
+			add(fmt.Errorf("invalid stop_signal %q: %w", cfg.StopSignal, err))
+		}
+	}
+	for _, d := range []struct{ field, value string }{
+		{"stop_timeout", cfg.StopTimeout},
+		{"start_timeout", cfg.StartTimeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d.value); err != nil || v <= 0 {
+			add(fmt.Errorf("invalid %s %q: expected a duration like \"10s\"", d.field, d.value))
+		}
+	}
+	if cfg.MemoryMax != "" {
+		_, err := ParseMemory(cfg.MemoryMax)
+		add(err)
+	}
+	if cfg.CPUQuota != "" {
+		_, err := ParseCPUQuota(cfg.CPUQuota)
+		add(err)
+	}
+	if cfg.PidsMax < 0 {
+		add(fmt.Errorf("pids_max %d must not be negative", cfg.PidsMax))
+	}
+	add(ValidateShell(cfg.Shell))
+	for _, name := range slices.Sorted(maps.Keys(cfg.Env)) {
+		if !validEnvName(name) {
+			add(fmt.Errorf("invalid environment variable name %q", name))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"web", "api-v2", "my_app.dev"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q): %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "my app", "a/b"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q): expected an error", name)
+		}
+	}
+}
+
+func TestValidatePort(t *testing.T) {
+	for _, port := range []int{1, 3000, 65535} {
+		if err := ValidatePort(port); err != nil {
+			t.Errorf("ValidatePort(%d): %v", port, err)
+		}
+	}
+	for _, port := range []int{0, -1, 65536} {
+		if err := ValidatePort(port); err == nil {
+			t.Errorf("ValidatePort(%d): expected an error", port)
+		}
+	}
+}
+
+func TestValidateDirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateDirectory(dir); err != nil {
+		t.Errorf("ValidateDirectory(%q): %v", dir, err)
+	}
+	for _, d := range []string{"", "relative/dir", filepath.Join(dir, "missing"), file} {
+		if err := ValidateDirectory(d); err == nil {
+			t.Errorf("ValidateDirectory(%q): expected an error", d)
+		}
+	}
+}
+
+func TestValidateShell(t *testing.T) {
+	for _, shell := range []string{"", "bash", "$SHELL", "/usr/bin/fish"} {
+		if err := ValidateShell(shell); err != nil {
+			t.Errorf("ValidateShell(%q): %v", shell, err)
+		}
+	}
+	for _, shell := range []string{"bash -l", "$ZSH", "bin/sh"} {
+		if err := ValidateShell(shell); err == nil {
+			t.Errorf("ValidateShell(%q): expected an error", shell)
+		}
+	}
+}
+
+func TestParseEnv(t *testing.T) {
+	env, err := ParseEnv([]string{"NODE_ENV=development", "", " DEBUG=app:*", "EMPTY=", "URL=http://x?a=b"})
+	if err != nil {
+		t.Fatalf("ParseEnv failed: %v", err)
+	}
+	want := map[string]string{"NODE_ENV": "development", "DEBUG": "app:*", "EMPTY": "", "URL": "http://x?a=b"}
+	if len(env) != len(want) {
+		t.Errorf("got %v, want %v", env, want)
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("%s: got %q, want %q", k, env[k], v)
+		}
+	}
+
+	if env, err := ParseEnv([]string{"", " "}); err != nil || env != nil {
+		t.Errorf("expected no env from blank entries, got %v, %v", env, err)
+	}
+	for _, pair := range []string{"NOVALUE", "=x", "1ABC=x", "MY-VAR=x"} {
+		if _, err := ParseEnv([]string{pair}); err == nil {
+			t.Errorf("ParseEnv(%q): expected an error", pair)
+		}
+	}
+}
+
+func TestValidateDocument(t *testing.T) {
+	dir := t.TempDir()
+	doc := &Document{
+		Version: ConfigVersion,
+		Processes: []ProcessConfig{
+			{Name: "web", Port: 3000, Command: "npm run dev", Directory: dir},
+			{Name: "web", Port: 3000, Args: []string{"go", "run", "."}, Directory: dir},
+			{Name: "bad name", Port: 70000, Directory: "relative", StopTimeout: "soon", ReadyWhenLogMatches: "(",
+				StopSignal: "SIGNOPE", MemoryMax: "lots", CPUQuota: "fast", Shell: "bash -l"},
+		},
+		Defaults: Defaults{StartTimeout: "-1s", CPUQuota: "0%", Env: map[string]string{"1BAD": "x"}},
+	}
+
+	var got []string
+	for _, err := range ValidateDocument(doc) {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		`process 2 ("web"): name is also used by process 1 ("web")`,
+		`process 2 ("web"): port 3000 is also used by process 1 ("web")`,
+		`process 3 ("bad name"): name "bad name" must not contain spaces or slashes`,
+		`process 3 ("bad name"): port 70000 out of range 1-65535`,
+		`process 3 ("bad name"): command or args is required`,
+		`process 3 ("bad name"): directory "relative" must be an absolute path`,
+		`process 3 ("bad name"): invalid ready_when_log_matches: error parsing regexp: missing closing ): ` + "`(`",
+		`process 3 ("bad name"): invalid stop_signal "SIGNOPE": unknown signal`,
+		`process 3 ("bad name"): invalid stop_timeout "soon": expected a duration like "10s"`,
+		`process 3 ("bad name"): invalid memory_max "lots": expected a size like 512M or 2G`,
+		`process 3 ("bad name"): invalid cpu_quota "fast": expected a percentage like 150% or a CPU count like 1.5`,
+		`process 3 ("bad name"): invalid shell "bash -l": expected a shell name or path, without arguments`,
+		`defaults: invalid start_timeout "-1s": expected a duration like "10s"`,
+		`defaults: invalid cpu_quota "0%": expected a percentage like 150% or a CPU count like 1.5`,
+		`defaults: invalid environment variable name "1BAD"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ValidateDocument =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	doc.Processes = doc.Processes[:1]
+	doc.Defaults = Defaults{}
+	if problems := ValidateDocument(doc); len(problems) != 0 {
+		t.Errorf("expected a valid document, got %v", problems)
+	}
+}
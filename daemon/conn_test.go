@@ -3,6 +3,8 @@ package daemon
 import (
 	"github.com/jaiir320/devserve/process"
 	"github.com/jaiir320/devserve/protocol"
+	"github.com/jaiir320/devserve/testutil"
+	"fmt"
 	"net"
 	"strings"
 	"testing"
@@ -125,3 +127,48 @@ func TestHandleConnShutdown(t *testing.T) {
 		t.Fatal("expected stop channel to be signaled, timed out")
 	}
 }
+
+func TestHandleConnServeCancelled(t *testing.T) {
+	resetState(t)
+
+	client, server := net.Pipe()
+	stop := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		handleConn(server, stop)
+		close(done)
+	}()
+
+	port := testutil.FreePort(t)
+	req := &protocol.Request{Action: "serve", Args: map[string]any{
+		"name":     "slow",
+		"port":     port,
+		"command":  "sleep 30",
+		"cwd":      t.TempDir(),
+		"progress": true,
+	}}
+	if err := protocol.SendRequest(client, req); err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	resp, err := protocol.ReadResponse(client)
+	if err != nil {
+		t.Fatalf("failed to read progress: %v", err)
+	}
+	if resp.Progress == nil {
+		t.Fatalf("expected a progress response, got %+v", resp)
+	}
+	if want := fmt.Sprintf("waiting for port %d", port); resp.Progress.Stage != want {
+		t.Errorf("expected stage %q, got %q", want, resp.Progress.Stage)
+	}
+
+	client.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve was not cancelled after the client disconnected")
+	}
+	if _, exists := processes["slow"]; exists {
+		t.Error("expected the cancelled process not to be registered")
+	}
+}
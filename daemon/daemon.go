@@ -5,6 +5,8 @@ import (
 	"github.com/jaiir320/devserve/config"
 	"github.com/jaiir320/devserve/process"
 	"github.com/jaiir320/devserve/protocol"
+	"github.com/jaiir320/devserve/reaper"
+	"github.com/jaiir320/devserve/tunnel"
 	"errors"
 	"fmt"
 	"log"
@@ -29,6 +31,18 @@ func Run() error {
 	log.SetOutput(os.Stdout)
 	log.SetFlags(log.Ldate | log.Ltime)
 	processes = make(map[string]*process.Process)
+	if err := tunnel.Use(config.Tunnel); err != nil {
+		return err
+	}
+	process.EnableCgroups()
+	reapCtx, stopReaping := context.WithCancel(context.Background())
+	defer stopReaping()
+	if err := process.BecomeSubreaper(); err != nil {
+		log.Printf("failed to become child subreaper: %s", err)
+	} else {
+		// Orphans reparented to us must be reaped or they stay zombies.
+		go reaper.Watch(reapCtx)
+	}
 	conn, err := net.Dial("unix", config.Socket)
 	if err == nil {
 		conn.Close()
@@ -44,6 +58,9 @@ func Run() error {
 	log.Println("daemon started")
 	stopChan := make(chan struct{}, 1)
 
+	sampleCtx, stopSampling := context.WithCancel(context.Background())
+	go sampleUsage(sampleCtx, config.UsageSampleInterval)
+
 	// Handle OS signals for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -70,8 +87,10 @@ func Run() error {
 		go handleConn(conn, stopChan)
 	}
 
+	stopSampling()
+
 	// Stop all running child processes before exiting
-	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
 	defer cancel()
 	failed := stopAllProcesses(ctx)
 	if len(failed) > 0 {
@@ -82,6 +101,26 @@ func Run() error {
 	return nil
 }
 
+// shutdownTimeout bounds stopAllProcesses. It is extended beyond
+// config.ShutdownTimeout when a process's pre-stop command, grace period
+// and post-stop hook need longer, so shutdown does not cut them short.
+func shutdownTimeout() time.Duration {
+	timeout := config.ShutdownTimeout
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, p := range processes {
+		// Leave room for the SIGKILL and tree cleanup after the grace period.
+		d := p.StopPolicy.MaxDuration() + config.ShutdownTimeout - config.StopGracePeriod
+		if p.Hooks.PostStop != "" {
+			d += config.PostStopTimeout
+		}
+		if d > timeout {
+			timeout = d
+		}
+	}
+	return timeout
+}
+
 // stopAllProcesses stops all running child processes with retry logic.
 // It respects the context deadline for the overall shutdown operation.
 func stopAllProcesses(ctx context.Context) []string {
@@ -123,9 +162,12 @@ func stopAllProcesses(ctx context.Context) []string {
 	for remaining > 0 {
 		select {
 		case r := <-results:
+			var stopErr *process.StopError
 			if r.err != nil {
 				log.Printf("failed to stop %s (port %d) attempt %d: %s", r.name, r.port, r.attempt, r.err)
-				if r.attempt < maxRetries {
+				// A StopError means the process was stopped with leftovers;
+				// stopping it again cannot help.
+				if r.attempt < maxRetries && !errors.As(r.err, &stopErr) {
 					p := snapshot[r.name]
 					go func(name string, p *process.Process, attempt int) {
 						select {
@@ -143,6 +185,11 @@ func stopAllProcesses(ctx context.Context) []string {
 				} else {
 					remaining--
 					failed = append(failed, fmt.Sprintf("%d", r.port))
+					if stopErr != nil {
+						mu.Lock()
+						delete(processes, r.name)
+						mu.Unlock()
+					}
 				}
 			} else {
 				remaining--
@@ -178,7 +225,7 @@ func handleConn(conn net.Conn, stop chan struct{}) {
 
 	if req.Action == "shutdown" {
 		log.Println("shutdown requested, stopping all processes")
-		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
 		defer cancel()
 		failed := stopAllProcesses(ctx)
 		if len(failed) > 0 {
@@ -191,12 +238,19 @@ func handleConn(conn net.Conn, stop chan struct{}) {
 		return
 	}
 
+	if req.Action == "attach" {
+		handleAttach(conn, req.Args)
+		return
+	}
+	if req.Action == "serve" {
+		handleServeConn(conn, req.Args)
+		return
+	}
+
 	var resp *protocol.Response
 	switch req.Action {
 	case "ping":
 		resp = handlePing(req.Args)
-	case "serve":
-		resp = handleServe(req.Args)
 	case "stop":
 		resp = handleStop(req.Args)
 	case "list":
@@ -205,8 +259,16 @@ func handleConn(conn net.Conn, stop chan struct{}) {
 		resp = handleLogs(req.Args)
 	case "get":
 		resp = handleGet(req.Args)
+	case "resize":
+		resp = handleResize(req.Args)
+	case "send":
+		resp = handleSend(req.Args)
+	case "signal":
+		resp = handleSignal(req.Args)
+	case "port":
+		resp = handlePort(req.Args)
 	default:
-		resp = protocol.ErrResponse(fmt.Errorf("unknown action '%s'", req.Action))
+		resp = invalidArg("unknown action '%s'", req.Action)
 	}
 
 	protocol.SendResponse(conn, resp)
@@ -5,68 +5,257 @@ import (
 	"github.com/jaiir320/devserve/process"
 	"github.com/jaiir320/devserve/protocol"
 	"github.com/jaiir320/devserve/tunnel"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// invalidArg returns an error response for a missing or malformed argument.
+func invalidArg(format string, a ...any) *protocol.Response {
+	return protocol.ErrResponse(protocol.WithKind(fmt.Errorf(format, a...), protocol.ErrInvalid))
+}
+
+// notFound returns an error response for an unknown process name.
+func notFound(name string) *protocol.Response {
+	return protocol.ErrResponse(protocol.WithKind(fmt.Errorf("process '%s' not found", name), protocol.ErrNotFound))
+}
+
+// intArg reads an optional integer argument. JSON numbers decode as
+// float64; strings are accepted too. ok reports whether the key was present.
+func intArg(args map[string]any, key string) (n int, ok bool, err error) {
+	v, ok := args[key]
+	if !ok {
+		return 0, false, nil
+	}
+	switch t := v.(type) {
+	case float64:
+		return int(t), true, nil
+	case string:
+		n, err := strconv.Atoi(t)
+		if err != nil {
+			return 0, true, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		return n, true, nil
+	}
+	return 0, true, fmt.Errorf("invalid %s type", key)
+}
+
+// stringArg reads an optional string argument, "" when absent.
+func stringArg(args map[string]any, key string) string {
+	s, _ := args[key].(string)
+	return s
+}
+
+// stringsArg reads an optional list-of-strings argument.
+func stringsArg(args map[string]any, key string) ([]string, error) {
+	v, ok := args[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s type", key)
+	}
+	out := make([]string, len(list))
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s type", key)
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// envArg reads an optional map of environment variables.
+func envArg(args map[string]any, key string) (map[string]string, error) {
+	v, ok := args[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s type", key)
+	}
+	pairs := make([]string, 0, len(m))
+	for name, value := range m {
+		s, ok := value.(string)
+		if !ok || strings.Contains(name, "=") {
+			return nil, fmt.Errorf("invalid %s value for %s", key, name)
+		}
+		pairs = append(pairs, name+"="+s)
+	}
+	return config.ParseEnv(pairs)
+}
+
+// durationArg returns the duration argument under key, or 0 if unset.
+func durationArg(args map[string]any, key string) (time.Duration, error) {
+	s := stringArg(args, key)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a duration like 2m", key, s)
+	}
+	return d, nil
+}
+
 func handlePing(args map[string]any) *protocol.Response {
 	return protocol.OkResponse("pong")
 }
 
 func handleServe(args map[string]any) *protocol.Response {
+	return serve(context.Background(), args, nil)
+}
+
+// handleServeConn runs a serve request, streaming progress while the
+// process starts if the client asked for it. The start is cancelled if the
+// client disconnects first.
+func handleServeConn(conn net.Conn, args map[string]any) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// The client sends nothing after its request, so a read only
+		// returns once it has gone away.
+		io.Copy(io.Discard, conn)
+		cancel()
+	}()
+
+	var progress func(protocol.Progress)
+	if want, _ := args["progress"].(bool); want {
+		progress = func(pr protocol.Progress) {
+			protocol.SendProgress(conn, pr)
+		}
+	}
+	protocol.SendResponse(conn, serve(ctx, args, progress))
+}
+
+func serve(ctx context.Context, args map[string]any, progress func(protocol.Progress)) *protocol.Response {
 	name, ok := args["name"].(string)
 	if !ok || name == "" {
-		return protocol.ErrResponse(fmt.Errorf("missing or invalid 'name' argument"))
+		return invalidArg("missing or invalid 'name' argument")
 	}
 
 	mu.RLock()
 	_, exists := processes[name]
 	mu.RUnlock()
 	if exists {
-		return protocol.ErrResponse(fmt.Errorf("process '%s' already in use", name))
+		return protocol.ErrResponse(protocol.WithKind(fmt.Errorf("process '%s' already in use", name), protocol.ErrRunning))
 	}
 
-	portVal, ok := args["port"]
+	port, ok, err := intArg(args, "port")
 	if !ok {
-		return protocol.ErrResponse(fmt.Errorf("missing or invalid 'port' argument"))
+		return invalidArg("missing or invalid 'port' argument")
 	}
-	// JSON numbers decode as float64
-	var port int
-	switch v := portVal.(type) {
-	case float64:
-		port = int(v)
-	case string:
-		var err error
-		port, err = strconv.Atoi(v)
-		if err != nil {
-			return protocol.ErrResponse(fmt.Errorf("invalid port: %w", err))
-		}
-	default:
-		return protocol.ErrResponse(fmt.Errorf("invalid port type"))
+	if err != nil {
+		return invalidArg("%w", err)
 	}
 
-	if err := process.CheckPortInUse(port); err != nil {
-		log.Printf("port %d in use: %s", port, err)
-		return protocol.ErrResponse(err)
+	host, err := process.ParseHost(stringArg(args, "host"))
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+	env, err := envArg(args, "env")
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+	tags, err := stringsArg(args, "tags")
+	if err != nil {
+		return invalidArg("%w", err)
 	}
 
-	command, ok := args["command"].(string)
-	if !ok || command == "" {
-		return protocol.ErrResponse(fmt.Errorf("missing or invalid 'command' argument"))
+	if _, inUse := process.DialPort(host, port); inUse {
+		killExisting, _ := args["kill_existing"].(bool)
+		if err := freePort(host, port, killExisting); err != nil {
+			log.Printf("port %d in use: %s", port, err)
+			return protocol.ErrResponse(protocol.WithKind(err, protocol.ErrConflict))
+		}
+	}
+
+	argv, err := stringsArg(args, "args")
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+	command, _ := args["command"].(string)
+	switch {
+	case len(argv) > 0 && command != "":
+		return invalidArg("'command' and 'args' are mutually exclusive")
+	case len(argv) > 0:
+		command = config.JoinArgs(argv)
+	case command == "":
+		return invalidArg("missing or invalid 'command' argument")
 	}
 
 	cwd, _ := args["cwd"].(string) // optional, empty string if not provided
 
+	memoryMax, _ := args["memory_max"].(string)
+	cpuQuota, _ := args["cpu_quota"].(string)
+	pidsMax, _, err := intArg(args, "pids_max")
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+	limits, err := process.ParseLimits(memoryMax, cpuQuota, pidsMax)
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+
+	stopSignal, _ := args["stop_signal"].(string)
+	stopTimeout, _ := args["stop_timeout"].(string)
+	preStop, _ := args["pre_stop"].(string)
+	stopPolicy, err := process.ParseStopPolicy(stopSignal, stopTimeout, preStop)
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+
+	startTimeout, err := durationArg(args, "start_timeout")
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+
+	var readyWhen *regexp.Regexp
+	if pattern := stringArg(args, "ready_when_log_matches"); pattern != "" {
+		if readyWhen, err = regexp.Compile(pattern); err != nil {
+			return invalidArg("invalid ready_when_log_matches %q: %w", pattern, err)
+		}
+	}
+
 	p, err := process.CreateProcess(name, port, cwd, command)
 	if err != nil {
 		log.Printf("failed to create process '%s': %s", name, err)
 		return protocol.ErrResponse(fmt.Errorf("failed to create process '%s': %w", name, err))
 	}
-
-	err = p.Start(command)
+	p.Limits = limits
+	p.Argv = argv
+	p.Shell = stringArg(args, "shell")
+	p.TTY, _ = args["tty"].(bool)
+	p.StripANSI, _ = args["strip_ansi"].(bool)
+	p.StopPolicy = stopPolicy
+	p.Hooks = process.Hooks{
+		PreStart:  stringArg(args, "pre_start"),
+		PostReady: stringArg(args, "post_ready"),
+		PostStop:  stringArg(args, "post_stop"),
+	}
+	p.ReadyWhen = readyWhen
+	p.StartTimeout = startTimeout
+	p.Host = host
+	p.Env = env
+	p.Tags = tags
+
+	err = startProcess(ctx, p, command, progress)
+	var startErr *process.StartError
+	if errors.As(err, &startErr) {
+		err = &protocol.DiagnosticsError{Err: err, Diagnostics: diagnostics(startErr)}
+	}
 	if err != nil {
 		log.Printf("failed to start process '%s': %s", name, err)
 		return protocol.ErrResponse(fmt.Errorf("failed to start process '%s': %w", name, err))
@@ -77,7 +266,7 @@ func handleServe(args map[string]any) *protocol.Response {
 	mu.Unlock()
 	log.Printf("started '%s' on port %d", name, port)
 
-	sr := protocol.ServeResult{Name: name, Port: port}
+	sr := protocol.ServeResult{Name: name, Port: port, ReadyLine: p.ReadyLine()}
 	if info, err := tunnel.GetTailscaleInfo(tunnel.DefaultRunner); err == nil {
 		sr.Hostname = info.Hostname
 		sr.IP = info.IP
@@ -92,20 +281,171 @@ func handleServe(args map[string]any) *protocol.Response {
 	return protocol.OkResponse(string(data))
 }
 
+// runningProcesses returns a snapshot of the running processes.
+func runningProcesses() []*process.Process {
+	mu.RLock()
+	defer mu.RUnlock()
+	running := make([]*process.Process, 0, len(processes))
+	for _, p := range processes {
+		running = append(running, p)
+	}
+	return running
+}
+
+// portOwners returns the classified processes listening on port.
+func portOwners(port int) ([]process.PortOwner, error) {
+	owners, err := process.FindPortOwners(port)
+	if err != nil {
+		return nil, err
+	}
+	process.ClassifyPortOwners(owners, runningProcesses())
+	return owners, nil
+}
+
+// freePort explains who holds an in-use port at host or, with kill set,
+// stops them: devserve processes as with stop, anything else with SIGTERM
+// and then SIGKILL.
+func freePort(host string, port int, kill bool) error {
+	owners, err := portOwners(port)
+	if err != nil {
+		log.Printf("failed to find owners of port %d: %s", port, err)
+	}
+	if len(owners) == 0 {
+		return fmt.Errorf("port %d is already in use", port)
+	}
+	if !kill {
+		descs := make([]string, len(owners))
+		for i, o := range owners {
+			descs[i] = o.String()
+		}
+		return fmt.Errorf("port %d is already in use by %s (stop it or use --kill-existing)", port, strings.Join(descs, ", "))
+	}
+
+	var pids []int
+	for _, o := range owners {
+		if o.Kind != process.OwnerDevserve {
+			log.Printf("killing %s to free port %d", o, port)
+			pids = append(pids, o.Pid)
+			continue
+		}
+		mu.RLock()
+		p, exists := processes[o.Process]
+		mu.RUnlock()
+		if !exists {
+			continue
+		}
+		log.Printf("stopping '%s' to free port %d", p.Name, port)
+		if err := p.Stop(); err != nil {
+			log.Printf("failed to stop '%s': %s", p.Name, err)
+		}
+		mu.Lock()
+		delete(processes, p.Name)
+		mu.Unlock()
+	}
+	if process.CheckPortInUse(host, port) == nil {
+		return nil
+	}
+	return process.KillPortOwners(host, port, pids, config.PortReleaseTimeout)
+}
+
+// startProcess starts p, reporting its progress every
+// config.StartProgressInterval until it is ready when progress is set.
+func startProcess(ctx context.Context, p *process.Process, command string, progress func(protocol.Progress)) error {
+	if progress == nil {
+		return p.StartContext(ctx, command)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- p.StartContext(ctx, command) }()
+
+	began := time.Now()
+	ticker := time.NewTicker(config.StartProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			sp := p.StartProgress()
+			progress(protocol.Progress{Elapsed: time.Since(began).Seconds(), Stage: sp.Stage, Line: sp.Line})
+		}
+	}
+}
+
+// diagnostics converts a start failure's details for the client.
+func diagnostics(e *process.StartError) protocol.Diagnostics {
+	return protocol.Diagnostics{
+		Exit:      e.Exit,
+		PortInUse: e.PortInUse,
+		PortOwner: e.PortOwner,
+		Command:   e.Command,
+		Dir:       e.Dir,
+		Env:       e.Env,
+		Stdout:    e.Stdout,
+		Stderr:    e.Stderr,
+	}
+}
+
+func handlePort(args map[string]any) *protocol.Response {
+	port, ok, err := intArg(args, "port")
+	if !ok {
+		return invalidArg("missing or invalid 'port' argument")
+	}
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+
+	host, err := process.ParseHost(stringArg(args, "host"))
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+
+	result := protocol.PortResult{Port: port, InUse: process.CheckPortInUse(host, port) != nil}
+	if result.InUse {
+		owners, err := portOwners(port)
+		if err != nil {
+			return protocol.ErrResponse(fmt.Errorf("failed to find owners of port %d: %w", port, err))
+		}
+		for _, o := range owners {
+			result.Owners = append(result.Owners, protocol.PortOwner{
+				Pid:     o.Pid,
+				Command: o.Command,
+				Dir:     o.Dir,
+				Kind:    o.Kind,
+				Process: o.Process,
+			})
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return protocol.ErrResponse(fmt.Errorf("failed to marshal port result: %w", err))
+	}
+	return protocol.OkResponse(string(data))
+}
+
 func handleStop(args map[string]any) *protocol.Response {
 	name, ok := args["name"].(string)
 	if !ok || name == "" {
-		return protocol.ErrResponse(fmt.Errorf("missing or invalid 'name' argument"))
+		return invalidArg("missing or invalid 'name' argument")
 	}
 
 	mu.RLock()
 	p, exists := processes[name]
 	mu.RUnlock()
 	if !exists {
-		return protocol.ErrResponse(fmt.Errorf("process '%s' not found", name))
+		return notFound(name)
 	}
 
 	err := p.Stop()
+	var stopErr *process.StopError
+	if errors.As(err, &stopErr) {
+		// The process itself is gone; only its leftovers are reported.
+		mu.Lock()
+		delete(processes, name)
+		mu.Unlock()
+		return protocol.ErrResponse(err)
+	}
 	if err != nil {
 		log.Printf("failed to stop process '%s': %s", name, err)
 		return protocol.ErrResponse(fmt.Errorf("failed to stop process '%s': %w", name, err))
@@ -124,15 +464,27 @@ func handleList(args map[string]any) *protocol.Response {
 		return protocol.ErrResponse(err)
 	}
 
+	history, _ := args["history"].(bool) // optional, history is large
+
 	mu.RLock()
 	entries := make([]protocol.ListEntry, 0, len(processes))
 	for _, v := range processes {
-		entries = append(entries, protocol.ListEntry{
+		entry := protocol.ListEntry{
 			Name:    v.Name,
 			Port:    v.Port,
 			Command: v.Command,
 			Dir:     v.Dir,
-		})
+			Tags:    v.Tags,
+			Started: v.StartedAt(),
+			Usage:   latestUsage(v),
+		}
+		if exited, reason := v.Exited(); exited {
+			entry.ExitReason = reason
+		}
+		if history {
+			entry.History = usageHistory(v)
+		}
+		entries = append(entries, entry)
 	}
 	mu.RUnlock()
 
@@ -153,14 +505,14 @@ func handleList(args map[string]any) *protocol.Response {
 func handleLogs(args map[string]any) *protocol.Response {
 	name, ok := args["name"].(string)
 	if !ok || name == "" {
-		return protocol.ErrResponse(fmt.Errorf("missing or invalid 'name' argument"))
+		return invalidArg("missing or invalid 'name' argument")
 	}
 
 	mu.RLock()
 	p, exists := processes[name]
 	mu.RUnlock()
 	if !exists {
-		return protocol.ErrResponse(fmt.Errorf("process '%s' not found", name))
+		return notFound(name)
 	}
 
 	lines := 50
@@ -201,22 +553,44 @@ func handleLogs(args map[string]any) *protocol.Response {
 func handleGet(args map[string]any) *protocol.Response {
 	name, ok := args["name"].(string)
 	if !ok || name == "" {
-		return protocol.ErrResponse(fmt.Errorf("missing or invalid 'name' argument"))
+		return invalidArg("missing or invalid 'name' argument")
 	}
 
 	mu.RLock()
 	p, exists := processes[name]
 	mu.RUnlock()
 	if !exists {
-		return protocol.ErrResponse(fmt.Errorf("process '%s' not found", name))
+		return notFound(name)
 	}
 
 	// Return process info as structured data
 	info := protocol.ProcessInfo{
-		Name:    p.Name,
-		Port:    p.Port,
-		Command: p.Command,
-		Dir:     p.Dir,
+		Name:      p.Name,
+		Port:      p.Port,
+		Command:   p.Command,
+		Dir:       p.Dir,
+		Args:      p.Argv,
+		Shell:     p.Shell,
+		TTY:       p.TTY,
+		StripANSI: p.StripANSI,
+		Cgroup:    p.Cgroup(),
+		Usage:     latestUsage(p),
+		History:   usageHistory(p),
+	}
+	info.MemoryMax, info.CPUQuota, info.PidsMax = p.Limits.Spec()
+	info.StopSignal, info.StopTimeout, info.PreStop = p.StopPolicy.Spec()
+	info.PreStart, info.PostReady, info.PostStop = p.Hooks.PreStart, p.Hooks.PostReady, p.Hooks.PostStop
+	if p.ReadyWhen != nil {
+		info.ReadyWhenLogMatches = p.ReadyWhen.String()
+	}
+	if p.StartTimeout > 0 {
+		info.StartTimeout = p.StartTimeout.String()
+	}
+	info.Host, info.Address = p.Host, p.Addr()
+	info.Env = p.Env
+	info.Tags = p.Tags
+	if exited, reason := p.Exited(); exited {
+		info.ExitReason = reason
 	}
 
 	data, err := json.Marshal(info)
@@ -226,3 +600,141 @@ func handleGet(args map[string]any) *protocol.Response {
 
 	return protocol.OkResponse(string(data))
 }
+
+func handleResize(args map[string]any) *protocol.Response {
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return invalidArg("missing or invalid 'name' argument")
+	}
+	rows, _, err := intArg(args, "rows")
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+	cols, _, err := intArg(args, "cols")
+	if err != nil {
+		return invalidArg("%w", err)
+	}
+
+	mu.RLock()
+	p, exists := processes[name]
+	mu.RUnlock()
+	if !exists {
+		return notFound(name)
+	}
+
+	if err := p.Resize(rows, cols); err != nil {
+		return invalidArg("%w", err)
+	}
+	return protocol.OkResponse(fmt.Sprintf("resized '%s' to %dx%d", name, cols, rows))
+}
+
+func handleSend(args map[string]any) *protocol.Response {
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return invalidArg("missing or invalid 'name' argument")
+	}
+	text, _ := args["text"].(string)
+	enter, _ := args["enter"].(bool)
+
+	mu.RLock()
+	p, exists := processes[name]
+	mu.RUnlock()
+	if !exists {
+		return notFound(name)
+	}
+
+	input := []byte(text)
+	if enter {
+		input = append(input, p.Enter()...)
+	}
+	if len(input) == 0 {
+		return invalidArg("nothing to send")
+	}
+	if _, err := p.WriteInput(input); err != nil {
+		return protocol.ErrResponse(fmt.Errorf("failed to send input to '%s': %w", name, err))
+	}
+	log.Printf("sent %d bytes of input to '%s'", len(input), name)
+	return protocol.OkResponse(fmt.Sprintf("sent input to '%s'", name))
+}
+
+func handleSignal(args map[string]any) *protocol.Response {
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return invalidArg("missing or invalid 'name' argument")
+	}
+	sigName, ok := args["signal"].(string)
+	if !ok || sigName == "" {
+		return invalidArg("missing or invalid 'signal' argument")
+	}
+	sig, err := config.ParseSignal(sigName)
+	if err != nil {
+		return invalidArg("invalid signal %q: %w", sigName, err)
+	}
+	group, _ := args["group"].(bool)
+
+	mu.RLock()
+	p, exists := processes[name]
+	mu.RUnlock()
+	if !exists {
+		return notFound(name)
+	}
+
+	if err := p.Signal(sig, group); err != nil {
+		return protocol.ErrResponse(err)
+	}
+	target := "process"
+	if group {
+		target = "process group"
+	}
+	return protocol.OkResponse(fmt.Sprintf("sent %s to '%s' %s", process.SignalName(sig), name, target))
+}
+
+// handleAttach streams a process's output to the connection and the
+// connection's input to the process until either side ends. After the
+// initial response the connection carries raw bytes.
+func handleAttach(conn net.Conn, args map[string]any) {
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		protocol.SendResponse(conn, invalidArg("missing or invalid 'name' argument"))
+		return
+	}
+
+	mu.RLock()
+	p, exists := processes[name]
+	mu.RUnlock()
+	if !exists {
+		protocol.SendResponse(conn, notFound(name))
+		return
+	}
+
+	output, detach := p.Attach()
+	defer detach()
+	if err := protocol.SendResponse(conn, protocol.OkResponse(fmt.Sprintf("attached to '%s'", name))); err != nil {
+		return
+	}
+	log.Printf("viewer attached to '%s'", name)
+
+	go func() {
+		// The viewer detaching closes the connection.
+		defer detach()
+		buf := make([]byte, 4096)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				if _, werr := p.WriteInput(buf[:n]); werr != nil {
+					log.Printf("failed to forward input to '%s': %s", name, werr)
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for chunk := range output {
+		if _, err := conn.Write(chunk); err != nil {
+			break
+		}
+	}
+	log.Printf("viewer detached from '%s'", name)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jaiir320/devserve/tunnel"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -98,6 +99,9 @@ func TestHandleServeDuplicateName(t *testing.T) {
 	if !strings.Contains(resp.Error, "already in use") {
 		t.Errorf("expected error to contain %q, got %q", "already in use", resp.Error)
 	}
+	if resp.Kind != protocol.KindRunning {
+		t.Errorf("expected kind %q, got %q", protocol.KindRunning, resp.Kind)
+	}
 }
 
 func TestHandleServePortTypes(t *testing.T) {
@@ -220,7 +224,7 @@ func TestHandleListPopulated(t *testing.T) {
 	t.Cleanup(func() { tunnel.SetRunner(origRunner) })
 
 	mu.Lock()
-	processes["web"] = &process.Process{Name: "web", Port: 3000}
+	processes["web"] = &process.Process{Name: "web", Port: 3000, Tags: []string{"frontend"}}
 	processes["api"] = &process.Process{Name: "api", Port: 4000}
 	mu.Unlock()
 
@@ -242,6 +246,9 @@ func TestHandleListPopulated(t *testing.T) {
 	found := map[string]int{}
 	for _, e := range lr.Processes {
 		found[e.Name] = e.Port
+		if e.Name == "web" && (len(e.Tags) != 1 || e.Tags[0] != "frontend") {
+			t.Errorf("expected web tagged frontend, got %v", e.Tags)
+		}
 	}
 	if found["web"] != 3000 {
 		t.Errorf("expected web on port 3000, got %d", found["web"])
@@ -402,3 +409,275 @@ func TestHandleGetValid(t *testing.T) {
 		t.Errorf("expected dir %q, got %q", dir, info["dir"])
 	}
 }
+
+func TestHandleServeInvalidLimits(t *testing.T) {
+	resetState(t)
+
+	port := testutil.FreePort(t)
+
+	resp := handleServe(map[string]any{
+		"name":       "app",
+		"port":       float64(port),
+		"command":    "echo hi",
+		"memory_max": "plenty",
+	})
+
+	if resp.OK {
+		t.Fatal("expected error response, got OK")
+	}
+	if !strings.Contains(resp.Error, "invalid memory_max") {
+		t.Errorf("expected error to contain %q, got %q", "invalid memory_max", resp.Error)
+	}
+	if resp.Kind != protocol.KindInvalid {
+		t.Errorf("expected kind %q, got %q", protocol.KindInvalid, resp.Kind)
+	}
+}
+
+func TestHandleServeInvalidArgv(t *testing.T) {
+	resetState(t)
+
+	port := testutil.FreePort(t)
+	cases := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"command": "npm start", "args": []any{"npm", "start"}}, "mutually exclusive"},
+		{map[string]any{"args": []any{"npm", float64(1)}}, "invalid args type"},
+		{map[string]any{"args": "npm start"}, "invalid args type"},
+	}
+	for _, c := range cases {
+		c.args["name"] = "app"
+		c.args["port"] = float64(port)
+		resp := handleServe(c.args)
+		if resp.OK {
+			t.Fatalf("expected error response for %v, got OK", c.args)
+		}
+		if !strings.Contains(resp.Error, c.want) {
+			t.Errorf("expected error to contain %q, got %q", c.want, resp.Error)
+		}
+		if resp.Kind != protocol.KindInvalid {
+			t.Errorf("expected kind %q, got %q", protocol.KindInvalid, resp.Kind)
+		}
+	}
+}
+
+func TestHandleResizeNotFound(t *testing.T) {
+	resetState(t)
+
+	resp := handleResize(map[string]any{"name": "nonexistent", "rows": float64(24), "cols": float64(80)})
+
+	if resp.OK {
+		t.Fatal("expected error response, got OK")
+	}
+	if resp.Kind != protocol.KindNotFound {
+		t.Errorf("expected kind %q, got %q", protocol.KindNotFound, resp.Kind)
+	}
+}
+
+func TestHandleSendNotFound(t *testing.T) {
+	resetState(t)
+
+	resp := handleSend(map[string]any{"name": "ghost", "text": "r"})
+
+	if resp.OK {
+		t.Fatal("expected error response, got OK")
+	}
+	if resp.Kind != protocol.KindNotFound {
+		t.Errorf("expected kind %q, got %q", protocol.KindNotFound, resp.Kind)
+	}
+}
+
+func TestHandleAttachNotFound(t *testing.T) {
+	resetState(t)
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go func() {
+		handleAttach(server, map[string]any{"name": "ghost"})
+		server.Close()
+	}()
+
+	resp, err := protocol.ReadResponseUnbuffered(client)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if resp.OK {
+		t.Fatal("expected error response, got OK")
+	}
+	if resp.Kind != protocol.KindNotFound {
+		t.Errorf("expected kind %q, got %q", protocol.KindNotFound, resp.Kind)
+	}
+}
+
+func TestHandleSignalNotFound(t *testing.T) {
+	resetState(t)
+
+	resp := handleSignal(map[string]any{"name": "ghost", "signal": "HUP"})
+
+	if resp.OK {
+		t.Fatal("expected error response, got OK")
+	}
+	if resp.Kind != protocol.KindNotFound {
+		t.Errorf("expected kind %q, got %q", protocol.KindNotFound, resp.Kind)
+	}
+}
+
+func TestHandleSignalInvalid(t *testing.T) {
+	resetState(t)
+
+	resp := handleSignal(map[string]any{"name": "web", "signal": "SIGNOPE"})
+
+	if resp.OK {
+		t.Fatal("expected error response, got OK")
+	}
+	if resp.Kind != protocol.KindInvalid {
+		t.Errorf("expected kind %q, got %q", protocol.KindInvalid, resp.Kind)
+	}
+}
+
+func TestHandleServeInvalidReadyPattern(t *testing.T) {
+	resetState(t)
+
+	resp := handleServe(map[string]any{
+		"name":                   "web",
+		"port":                   float64(testutil.FreePort(t)),
+		"command":                "true",
+		"ready_when_log_matches": "compiled (",
+	})
+
+	if resp.OK {
+		t.Fatal("expected error response, got OK")
+	}
+	if resp.Kind != protocol.KindInvalid {
+		t.Errorf("expected kind %q, got %q", protocol.KindInvalid, resp.Kind)
+	}
+}
+
+func TestHandleServeFailureDiagnostics(t *testing.T) {
+	resetState(t)
+
+	dir := t.TempDir()
+	resp := handleServe(map[string]any{
+		"name":    "web",
+		"port":    float64(testutil.FreePort(t)),
+		"command": "echo 'Cannot find module next' >&2; exit 1",
+		"cwd":     dir,
+	})
+
+	if resp.OK {
+		t.Fatal("expected error response, got OK")
+	}
+	d := resp.Diagnostics
+	if d == nil {
+		t.Fatalf("expected diagnostics with the error %q", resp.Error)
+	}
+	if d.Exit != "exited with status 1" {
+		t.Errorf("expected the exit status, got %q", d.Exit)
+	}
+	if d.Dir != dir {
+		t.Errorf("expected dir %q, got %q", dir, d.Dir)
+	}
+	if len(d.Stderr) != 1 || d.Stderr[0] != "Cannot find module next" {
+		t.Errorf("expected the last stderr lines, got %q", d.Stderr)
+	}
+}
+
+func TestHandleServePortOwnerInConflict(t *testing.T) {
+	resetState(t)
+
+	port := testutil.OccupiedPort(t)
+	resp := handleServe(map[string]any{"name": "app", "port": float64(port), "command": "echo hi"})
+
+	if resp.Kind != protocol.KindConflict {
+		t.Errorf("expected kind %q, got %q", protocol.KindConflict, resp.Kind)
+	}
+	if want := fmt.Sprintf("pid %d", os.Getpid()); !strings.Contains(resp.Error, want) {
+		t.Errorf("expected the owner %q in the error, got %q", want, resp.Error)
+	}
+}
+
+func TestHandlePort(t *testing.T) {
+	resetState(t)
+
+	port := testutil.OccupiedPort(t)
+	resp := handlePort(map[string]any{"port": float64(port)})
+	if !resp.OK {
+		t.Fatalf("expected OK, got error: %s", resp.Error)
+	}
+	var result protocol.PortResult
+	if err := json.Unmarshal([]byte(resp.Data), &result); err != nil {
+		t.Fatalf("failed to unmarshal port result: %v", err)
+	}
+	if !result.InUse || len(result.Owners) != 1 {
+		t.Fatalf("expected one owner of an in-use port, got %+v", result)
+	}
+	if o := result.Owners[0]; o.Pid != os.Getpid() || o.Kind != process.OwnerOther {
+		t.Errorf("expected the test process as an unrelated owner, got %+v", o)
+	}
+
+	resp = handlePort(map[string]any{"port": float64(testutil.FreePort(t))})
+	if err := json.Unmarshal([]byte(resp.Data), &result); err != nil || result.InUse {
+		t.Errorf("expected a free port, got %+v (%v)", result, err)
+	}
+}
+
+func TestHandlePortHost(t *testing.T) {
+	resetState(t)
+
+	l, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("127.0.0.2 not available: %v", err)
+	}
+	defer l.Close()
+	port := float64(l.Addr().(*net.TCPAddr).Port)
+
+	var result protocol.PortResult
+	resp := handlePort(map[string]any{"port": port, "host": "127.0.0.2"})
+	if err := json.Unmarshal([]byte(resp.Data), &result); err != nil || !result.InUse {
+		t.Errorf("expected the port to be in use on 127.0.0.2, got %+v (%v)", result, err)
+	}
+	resp = handlePort(map[string]any{"port": port, "host": "bad host"})
+	if resp.Kind != protocol.KindInvalid {
+		t.Errorf("expected an invalid host to be refused, got %+v", resp)
+	}
+}
+
+func TestHandlePortInvalid(t *testing.T) {
+	resetState(t)
+
+	resp := handlePort(map[string]any{})
+
+	if resp.Kind != protocol.KindInvalid {
+		t.Errorf("expected kind %q, got %q", protocol.KindInvalid, resp.Kind)
+	}
+}
+
+func TestHandleServeInvalidHost(t *testing.T) {
+	resetState(t)
+
+	resp := handleServe(map[string]any{
+		"name":    "app",
+		"port":    float64(testutil.FreePort(t)),
+		"command": "echo hi",
+		"host":    "localhost:3000",
+	})
+
+	if resp.Kind != protocol.KindInvalid {
+		t.Errorf("expected kind %q, got %q", protocol.KindInvalid, resp.Kind)
+	}
+}
+
+func TestHandleServeInvalidEnv(t *testing.T) {
+	resetState(t)
+
+	resp := handleServe(map[string]any{
+		"name":    "app",
+		"port":    float64(testutil.FreePort(t)),
+		"command": "echo hi",
+		"env":     map[string]any{"NOT VALID": "x"},
+	})
+
+	if resp.Kind != protocol.KindInvalid {
+		t.Errorf("expected kind %q, got %q", protocol.KindInvalid, resp.Kind)
+	}
+}
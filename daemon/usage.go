@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"context"
+	"github.com/jaiir320/devserve/process"
+	"github.com/jaiir320/devserve/protocol"
+	"time"
+)
+
+// sampleUsage records a resource usage sample for every managed process
+// each interval until ctx is cancelled.
+func sampleUsage(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mu.RLock()
+			snapshot := make([]*process.Process, 0, len(processes))
+			for _, p := range processes {
+				snapshot = append(snapshot, p)
+			}
+			mu.RUnlock()
+
+			// Errors mean the process is stopping or already gone; the next
+			// list or stop will reflect that, so there is nothing to log.
+			for _, p := range snapshot {
+				p.SampleUsage()
+			}
+		}
+	}
+}
+
+// latestUsage returns the most recent usage sample for p in wire format.
+func latestUsage(p *process.Process) *protocol.Usage {
+	u := p.Usage()
+	if u == nil {
+		return nil
+	}
+	pu := toProtocolUsage(*u)
+	return &pu
+}
+
+// usageHistory returns p's usage samples in wire format, oldest first.
+func usageHistory(p *process.Process) []protocol.Usage {
+	samples := p.UsageHistory()
+	if len(samples) == 0 {
+		return nil
+	}
+	out := make([]protocol.Usage, len(samples))
+	for i, u := range samples {
+		out[i] = toProtocolUsage(u)
+	}
+	return out
+}
+
+func toProtocolUsage(u process.Usage) protocol.Usage {
+	return protocol.Usage{
+		Time:       u.Time,
+		CPUPercent: u.CPUPercent,
+		RSSBytes:   u.RSSBytes,
+		Threads:    u.Threads,
+		FDs:        u.FDs,
+		Procs:      u.Procs,
+	}
+}
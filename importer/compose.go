@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseCompose proposes a process for each service of a Docker Compose
+// file that publishes a port, run with "docker compose up <service>".
+// Services without a published port cannot be reached from the host and
+// are left out.
+func ParseCompose(data []byte, dir, project string) ([]Candidate, error) {
+	var file struct {
+		Services yaml.Node `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Services.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var candidates []Candidate
+	// Walk the mapping rather than decoding it into a map, so services are
+	// proposed in the order the file lists them.
+	for i := 0; i+1 < len(file.Services.Content); i += 2 {
+		name := file.Services.Content[i].Value
+		var service struct {
+			Ports []yaml.Node `yaml:"ports"`
+		}
+		if err := file.Services.Content[i+1].Decode(&service); err != nil {
+			return nil, fmt.Errorf("service %s: %w", name, err)
+		}
+		port := 0
+		for _, p := range service.Ports {
+			if port = publishedPort(&p); port != 0 {
+				break
+			}
+		}
+		if port == 0 {
+			continue
+		}
+
+		c := Candidate{PortFrom: "compose ports"}
+		c.Config.Name = processName(project, name)
+		c.Config.Args = []string{"docker", "compose", "up", name}
+		c.Config.Directory = dir
+		c.Config.Port = port
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// publishedPort returns the host port of a compose ports entry, in the
+// short syntax ("8080:80", "127.0.0.1:8080:80/tcp", "3000-3001:3000-3001")
+// or the long one ({published: 8080}); 0 when it publishes none.
+func publishedPort(node *yaml.Node) int {
+	var published string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		spec, _, _ := strings.Cut(interpolate(node.Value), "/")
+		// The container port is last; a lone port is not published.
+		i := strings.LastIndex(spec, ":")
+		if i < 0 {
+			return 0
+		}
+		spec = spec[:i]
+		if j := strings.LastIndex(spec, ":"); j >= 0 {
+			spec = spec[j+1:] // drop the host IP
+		}
+		published = spec
+	case yaml.MappingNode:
+		var long struct {
+			Published string `yaml:"published"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return 0
+		}
+		published = interpolate(long.Published)
+	default:
+		return 0
+	}
+
+	published, _, _ = strings.Cut(published, "-") // the first of a range
+	port, err := strconv.Atoi(strings.TrimSpace(published))
+	if err != nil || port < 1 || port > 65535 {
+		return 0
+	}
+	return port
+}
+
+// composeVarPattern matches ${VAR}, ${VAR:-default} and ${VAR-default}.
+var composeVarPattern = regexp.MustCompile(`\$\{(\w+)(?::?-([^}]*))?\}`)
+
+// interpolate expands variables in s as compose would, from the
+// environment or their defaults.
+func interpolate(s string) string {
+	return composeVarPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := composeVarPattern.FindStringSubmatch(m)
+		if v, ok := os.LookupEnv(sub[1]); ok && v != "" {
+			return v
+		}
+		return sub[2]
+	})
+}
@@ -0,0 +1,142 @@
+// Package importer proposes process configs from the files a project
+// already describes its processes in: Procfiles, package.json scripts and
+// Docker Compose files.
+package importer
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Candidate is a process found in a project file.
+type Candidate struct {
+	Config config.ProcessConfig
+	Source string // the file it was found in, e.g. "Procfile"
+	// PortFrom says how the port was chosen, e.g. "--port flag"; empty
+	// when no port was found and one must be given.
+	PortFrom string
+
+	usesPortVar bool // the command reads $PORT
+}
+
+// SetPort sets the candidate's port, passing it in PORT as well when the
+// command reads it.
+func (c *Candidate) SetPort(port int) {
+	c.Config.Port = port
+	if c.usesPortVar {
+		if c.Config.Env == nil {
+			c.Config.Env = make(map[string]string)
+		}
+		c.Config.Env["PORT"] = strconv.Itoa(port)
+	}
+}
+
+// A parser proposes candidates from the contents of a file in dir.
+type parser func(data []byte, dir, project string) ([]Candidate, error)
+
+// sources are the files Detect looks for, in the order their candidates
+// are listed. Only the first compose file found is used.
+var sources = []struct {
+	names []string
+	parse parser
+}{
+	{[]string{"Procfile", "Procfile.dev"}, ParseProcfile},
+	{[]string{"package.json"}, ParsePackageJSON},
+	{[]string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}, ParseCompose},
+}
+
+// ErrNothingFound is returned by Detect when dir has none of the files it
+// understands.
+var ErrNothingFound = errors.New("no Procfile, package.json or compose file found")
+
+// Detect finds the project files in dir and proposes a process for each
+// entry in them. dir must be absolute.
+func Detect(dir string) ([]Candidate, error) {
+	project := projectName(dir)
+	var candidates []Candidate
+	found := false
+	for _, src := range sources {
+		for _, name := range src.names {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			found = true
+			cs, err := src.parse(data, dir, project)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			for i := range cs {
+				cs[i].Source = name
+			}
+			candidates = append(candidates, cs...)
+			if src.names[0] == "compose.yaml" {
+				break // one compose file describes the project
+			}
+		}
+	}
+	if !found {
+		return nil, ErrNothingFound
+	}
+	return candidates, nil
+}
+
+// projectName returns a name for the project in dir, used to prefix the
+// names of its processes.
+func projectName(dir string) string {
+	return sanitizeName(filepath.Base(dir))
+}
+
+// sanitizeName turns s into a valid process name.
+func sanitizeName(s string) string {
+	s = strings.TrimPrefix(s, "@") // npm scopes: @team/web
+	s = strings.Map(func(r rune) rune {
+		if r == '/' || r == ':' || r == ' ' || r == '\t' || r == '\n' {
+			return '-'
+		}
+		return r
+	}, s)
+	return strings.Trim(s, "-")
+}
+
+// processName joins a project and an entry into a process name.
+func processName(project, entry string) string {
+	if project == "" {
+		return sanitizeName(entry)
+	}
+	return project + "-" + sanitizeName(entry)
+}
+
+var (
+	// --port 3000, --port=3000, -p 3000, -p3000
+	portFlagPattern = regexp.MustCompile(`(?:^|\s)(?:--port[= ]|-p\s*)(\d{2,5})\b`)
+	// PORT=3000 as an environment assignment
+	portEnvPattern = regexp.MustCompile(`(?:^|\s)PORT=(\d{2,5})\b`)
+	// $PORT or ${PORT}
+	portVarPattern = regexp.MustCompile(`\$\{?PORT\b`)
+)
+
+// portFromCommand finds a port in a command line: a PORT=N assignment or
+// a --port or -p flag.
+func portFromCommand(command string) (int, string) {
+	if m := portEnvPattern.FindStringSubmatch(command); m != nil {
+		if port, err := strconv.Atoi(m[1]); err == nil && config.ValidatePort(port) == nil {
+			return port, "PORT variable"
+		}
+	}
+	if m := portFlagPattern.FindStringSubmatch(command); m != nil {
+		if port, err := strconv.Atoi(m[1]); err == nil && config.ValidatePort(port) == nil {
+			return port, "--port flag"
+		}
+	}
+	return 0, ""
+}
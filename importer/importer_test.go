@@ -0,0 +1,197 @@
+package importer
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseProcfile(t *testing.T) {
+	data := `# processes
+web: bundle exec rails server -p 3000
+worker: bundle exec sidekiq
+
+api: node server.js --port $PORT
+assets: PORT=8080 bin/assets
+`
+	candidates, err := ParseProcfile([]byte(data), "/srv/shop", "shop")
+	if err != nil {
+		t.Fatalf("ParseProcfile failed: %v", err)
+	}
+
+	want := []struct {
+		name, command, portFrom string
+		port                    int
+		env                     map[string]string
+	}{
+		{"shop-web", "bundle exec rails server -p 3000", "--port flag", 3000, nil},
+		{"shop-worker", "bundle exec sidekiq", "", 0, nil},
+		{"shop-api", "node server.js --port $PORT", "foreman numbering", 5200, map[string]string{"PORT": "5200"}},
+		{"shop-assets", "PORT=8080 bin/assets", "PORT variable", 8080, nil},
+	}
+	if len(candidates) != len(want) {
+		t.Fatalf("expected %d candidates, got %+v", len(want), candidates)
+	}
+	for i, w := range want {
+		c := candidates[i]
+		if c.Config.Name != w.name || c.Config.Command != w.command || c.Config.Port != w.port || c.PortFrom != w.portFrom {
+			t.Errorf("candidate %d = %+v, want %+v", i, c, w)
+		}
+		if c.Config.Directory != "/srv/shop" {
+			t.Errorf("%s: directory = %q", c.Config.Name, c.Config.Directory)
+		}
+		if len(w.env) > 0 && !reflect.DeepEqual(c.Config.Env, w.env) {
+			t.Errorf("%s: env = %v, want %v", c.Config.Name, c.Config.Env, w.env)
+		}
+	}
+
+	if _, err := ParseProcfile([]byte("web bundle exec rails server\n"), "/srv/shop", "shop"); err == nil {
+		t.Error("expected a line without a name to be refused")
+	}
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pnpm-lock.yaml"), "")
+	data := `{
+  "name": "@acme/site",
+  "scripts": {
+    "build": "vite build",
+    "dev": "vite",
+    "dev:api": "PORT=4000 node api.js",
+    "preview": "vite preview --port 8000",
+    "start": "node server.js $PORT",
+    "storybook": "storybook dev",
+    "test": "vitest"
+  }
+}`
+	candidates, err := ParsePackageJSON([]byte(data), dir, "site-repo")
+	if err != nil {
+		t.Fatalf("ParsePackageJSON failed: %v", err)
+	}
+
+	want := []struct {
+		name, command, portFrom string
+		port                    int
+	}{
+		{"acme-site-dev", "pnpm run dev", "vite default", 5173},
+		{"acme-site-dev-api", "pnpm run dev:api", "PORT variable", 4000},
+		{"acme-site-preview", "pnpm run preview", "--port flag", 8000},
+		{"acme-site-start", "pnpm run start", "", 0},
+		{"acme-site-storybook", "pnpm run storybook", "storybook default", 6006},
+	}
+	if len(candidates) != len(want) {
+		t.Fatalf("expected %d candidates, got %+v", len(want), candidates)
+	}
+	for i, w := range want {
+		c := candidates[i]
+		if c.Config.Name != w.name || c.Config.Command != w.command || c.Config.Port != w.port || c.PortFrom != w.portFrom {
+			t.Errorf("candidate %d = %+v, want %+v", i, c, w)
+		}
+	}
+
+	// A port given later reaches a script that reads $PORT.
+	start := candidates[3]
+	start.SetPort(3100)
+	if start.Config.Port != 3100 || start.Config.Env["PORT"] != "3100" {
+		t.Errorf("after SetPort: port %d, env %v", start.Config.Port, start.Config.Env)
+	}
+}
+
+func TestParseCompose(t *testing.T) {
+	t.Setenv("WEB_PORT", "")
+	t.Setenv("ADMIN_PORT", "9001")
+	data := `services:
+  web:
+    build: .
+    ports:
+      - "${WEB_PORT:-3000}:3000"
+  db:
+    image: postgres
+    ports:
+      - "5432"
+  admin:
+    image: adminer
+    ports:
+      - "127.0.0.1:${ADMIN_PORT}:8080/tcp"
+  cache:
+    image: redis
+  api:
+    build: ./api
+    ports:
+      - target: 8000
+        published: "8081"
+  range:
+    image: nginx
+    ports:
+      - 9100-9101:80-81
+`
+	candidates, err := ParseCompose([]byte(data), "/srv/shop", "shop")
+	if err != nil {
+		t.Fatalf("ParseCompose failed: %v", err)
+	}
+
+	want := map[string]int{"shop-web": 3000, "shop-admin": 9001, "shop-api": 8081, "shop-range": 9100}
+	order := []string{"shop-web", "shop-admin", "shop-api", "shop-range"}
+	if len(candidates) != len(order) {
+		t.Fatalf("expected %v, got %+v", order, candidates)
+	}
+	for i, c := range candidates {
+		if c.Config.Name != order[i] || c.Config.Port != want[order[i]] {
+			t.Errorf("candidate %d = %s on %d, want %s on %d", i, c.Config.Name, c.Config.Port, order[i], want[order[i]])
+		}
+	}
+	if got := candidates[0].Config.Args; !reflect.DeepEqual(got, []string{"docker", "compose", "up", "web"}) {
+		t.Errorf("args = %q", got)
+	}
+
+	if _, err := ParseCompose([]byte("services: [\n"), "/srv/shop", "shop"); err == nil {
+		t.Error("expected invalid YAML to be refused")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my shop")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Detect(dir); !errors.Is(err, ErrNothingFound) {
+		t.Fatalf("expected ErrNothingFound in an empty directory, got %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "Procfile"), "web: rails s -p 3000\n")
+	writeFile(t, filepath.Join(dir, "package.json"), `{"scripts": {"dev": "next dev"}}`)
+	writeFile(t, filepath.Join(dir, "compose.yaml"), "services:\n  db:\n    ports: [\"5433:5432\"]\n")
+	writeFile(t, filepath.Join(dir, "docker-compose.yml"), "services:\n  old:\n    ports: [\"8000:80\"]\n")
+
+	candidates, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	var got []string
+	for _, c := range candidates {
+		got = append(got, c.Source+" "+c.Config.Name)
+		if err := config.ValidateName(c.Config.Name); err != nil {
+			t.Errorf("%s: %v", c.Config.Name, err)
+		}
+	}
+	want := []string{"Procfile my-shop-web", "package.json my-shop-dev", "compose.yaml my-shop-db"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect = %q, want %q", got, want)
+	}
+
+	writeFile(t, filepath.Join(dir, "package.json"), `{"scripts": `)
+	if _, err := Detect(dir); err == nil {
+		t.Error("expected an invalid package.json to fail")
+	}
+}
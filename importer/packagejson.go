@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// serverScripts are the package.json scripts that conventionally run a
+// server. Scripts named like "dev:api" are taken as well.
+var serverScripts = map[string]bool{
+	"dev":       true,
+	"start":     true,
+	"serve":     true,
+	"preview":   true,
+	"storybook": true,
+}
+
+// frameworkPorts are the ports tools listen on when not told otherwise,
+// matched against a script's command in order.
+var frameworkPorts = []struct {
+	tool    string
+	pattern *regexp.Regexp
+	port    int
+}{
+	{"vite preview", regexp.MustCompile(`\bvite\s+preview\b`), 4173},
+	{"vite", regexp.MustCompile(`\bvite\b`), 5173},
+	{"next", regexp.MustCompile(`\bnext\b`), 3000},
+	{"nuxt", regexp.MustCompile(`\bnuxi?\b`), 3000},
+	{"react-scripts", regexp.MustCompile(`\breact-scripts\s+start\b`), 3000},
+	{"astro", regexp.MustCompile(`\bastro\b`), 4321},
+	{"angular", regexp.MustCompile(`\bng\s+serve\b`), 4200},
+	{"storybook", regexp.MustCompile(`\bstorybook\b|\bstart-storybook\b`), 6006},
+	{"gatsby", regexp.MustCompile(`\bgatsby\s+develop\b`), 8000},
+	{"webpack", regexp.MustCompile(`\bwebpack(-dev-server|\s+serve)\b`), 8080},
+}
+
+// lockFiles pick the package manager that runs the scripts.
+var lockFiles = []struct{ file, manager string }{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+}
+
+// ParsePackageJSON proposes a process for each script of a package.json
+// that runs a server, run with the package manager whose lock file is in
+// dir. The port comes from the script or the tool it runs.
+func ParsePackageJSON(data []byte, dir, project string) ([]Candidate, error) {
+	var pkg struct {
+		Name    string            `json:"name"`
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	if name := sanitizeName(pkg.Name); name != "" {
+		project = name
+	}
+	manager := packageManager(dir)
+
+	names := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		if serverScripts[name] || strings.HasPrefix(name, "dev:") || strings.HasPrefix(name, "serve:") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var candidates []Candidate
+	for _, name := range names {
+		script := pkg.Scripts[name]
+		c := Candidate{usesPortVar: portVarPattern.MatchString(script)}
+		c.Config.Name = processName(project, name)
+		c.Config.Command = manager + " run " + name
+		c.Config.Directory = dir
+		if port, from := portFromCommand(script); port != 0 {
+			c.Config.Port, c.PortFrom = port, from
+		} else if !c.usesPortVar {
+			for _, f := range frameworkPorts {
+				if f.pattern.MatchString(script) {
+					c.Config.Port, c.PortFrom = f.port, f.tool+" default"
+					break
+				}
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// packageManager returns the package manager used in dir, judged by its
+// lock file; npm when there is none.
+func packageManager(dir string) string {
+	for _, l := range lockFiles {
+		if _, err := os.Stat(filepath.Join(dir, l.file)); err == nil {
+			return l.manager
+		}
+	}
+	return "npm"
+}
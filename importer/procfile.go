@@ -0,0 +1,44 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// procfileBasePort and procfileStep number the ports of Procfile entries
+// that read $PORT, as foreman does: 5000, 5100, 5200...
+const (
+	procfileBasePort = 5000
+	procfileStep     = 100
+)
+
+// ParseProcfile proposes a process for each "name: command" line of a
+// Procfile. Entries that read $PORT without setting it get a port
+// numbered as foreman would, passed to them in PORT.
+func ParseProcfile(data []byte, dir, project string) ([]Candidate, error) {
+	var candidates []Candidate
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, command, ok := strings.Cut(line, ":")
+		name, command = strings.TrimSpace(name), strings.TrimSpace(command)
+		if !ok || name == "" || command == "" {
+			return nil, fmt.Errorf("line %d: expected name: command", i+1)
+		}
+
+		c := Candidate{usesPortVar: portVarPattern.MatchString(command)}
+		c.Config.Name = processName(project, name)
+		c.Config.Command = command
+		c.Config.Directory = dir
+		if port, from := portFromCommand(command); port != 0 {
+			c.Config.Port, c.PortFrom = port, from
+		} else if c.usesPortVar {
+			c.SetPort(procfileBasePort + procfileStep*len(candidates))
+			c.PortFrom = "foreman numbering"
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,165 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// viewerBuffer is how many output chunks a viewer may fall behind before
+// it is disconnected.
+const viewerBuffer = 256
+
+// Attach subscribes to the process's output from now on, for any number
+// of concurrent viewers. The channel is closed when the process's output
+// ends or detach is called. TTY output is delivered raw, as the terminal
+// received it; otherwise new lines of the stdout and stderr logs are.
+func (p *Process) Attach() (output <-chan []byte, detach func()) {
+	ch := make(chan []byte, viewerBuffer)
+	if p.pty != nil {
+		return p.attachTTY(ch)
+	}
+
+	// Open the logs before returning so that output written in response
+	// to input sent right after attaching is not missed.
+	var files []*os.File
+	for _, logFile := range []*os.File{p.Stdout, p.Stderr} {
+		f, err := os.Open(logFile.Name())
+		if err != nil {
+			continue
+		}
+		f.Seek(0, io.SeekEnd)
+		files = append(files, f)
+	}
+	done := make(chan struct{})
+	go p.followLogs(files, ch, done)
+	var once sync.Once
+	return ch, func() { once.Do(func() { close(done) }) }
+}
+
+func (p *Process) attachTTY(ch chan []byte) (<-chan []byte, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.viewersClosed {
+		close(ch)
+		return ch, func() {}
+	}
+	if p.viewers == nil {
+		p.viewers = make(map[chan []byte]struct{})
+	}
+	p.viewers[ch] = struct{}{}
+	return ch, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := p.viewers[ch]; ok {
+			delete(p.viewers, ch)
+			close(ch)
+		}
+	}
+}
+
+// broadcast hands a chunk of TTY output to every viewer. A viewer that
+// has fallen too far behind is disconnected rather than shown output
+// with gaps.
+func (p *Process) broadcast(b []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.viewers) == 0 {
+		return
+	}
+	chunk := append([]byte(nil), b...)
+	for ch := range p.viewers {
+		select {
+		case ch <- chunk:
+		default:
+			log.Printf("process %s: disconnecting slow viewer", p.Name)
+			delete(p.viewers, ch)
+			close(ch)
+		}
+	}
+}
+
+// closeViewers ends every viewer once the TTY output has ended.
+func (p *Process) closeViewers() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for ch := range p.viewers {
+		close(ch)
+	}
+	p.viewers = nil
+	p.viewersClosed = true
+}
+
+// followLogs sends what is appended to the open stdout and stderr logs
+// until done is closed or the process has exited and the logs are drained.
+func (p *Process) followLogs(files []*os.File, ch chan<- []byte, done <-chan struct{}) {
+	defer close(ch)
+	for _, f := range files {
+		defer f.Close()
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		exited, _ := p.Exited()
+		read := false
+		for _, f := range files {
+			n, _ := f.Read(buf)
+			if n == 0 {
+				continue
+			}
+			read = true
+			select {
+			case ch <- append([]byte(nil), buf[:n]...):
+			case <-done:
+				return
+			}
+		}
+		if read {
+			continue
+		}
+		if exited {
+			return
+		}
+		select {
+		case <-done:
+			return
+		case <-time.After(config.AttachPollInterval):
+		}
+	}
+}
+
+// WriteInput writes to the process's terminal when it has one, otherwise
+// to its stdin. It gives up after config.InputWriteTimeout if the process
+// is not reading its input and the buffer is full.
+func (p *Process) WriteInput(b []byte) (int, error) {
+	if exited, _ := p.Exited(); exited {
+		return 0, fmt.Errorf("process '%s' has exited", p.Name)
+	}
+	f := p.pty
+	if f == nil {
+		f = p.stdin
+	}
+	if f == nil {
+		return 0, fmt.Errorf("process '%s' is not running", p.Name)
+	}
+	f.SetWriteDeadline(time.Now().Add(config.InputWriteTimeout))
+	n, err := f.Write(b)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = fmt.Errorf("process '%s' is not reading its input", p.Name)
+	}
+	return n, err
+}
+
+// Enter returns the bytes an Enter key press sends to the process: a
+// carriage return on a terminal, a newline on a pipe.
+func (p *Process) Enter() []byte {
+	if p.pty != nil {
+		return []byte("\r")
+	}
+	return []byte("\n")
+}
@@ -0,0 +1,124 @@
+package process_test
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/process"
+	"github.com/jaiir320/devserve/testutil"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readUntil collects output from ch until it contains want.
+func readUntil(t *testing.T, ch <-chan []byte, want string) string {
+	t.Helper()
+	var got strings.Builder
+	timeout := time.After(5 * time.Second)
+	for !strings.Contains(got.String(), want) {
+		select {
+		case chunk, ok := <-ch:
+			if !ok {
+				t.Fatalf("output ended before %q, got %q", want, got.String())
+			}
+			got.Write(chunk)
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q, got %q", want, got.String())
+		}
+	}
+	return got.String()
+}
+
+func startInteractive(t *testing.T, tty bool) *process.Process {
+	t.Helper()
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.TTY = tty
+
+	cmd := fmt.Sprintf(`nc -l %d; while read line; do echo "got $line"; done`, port)
+	if err := p.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { p.Stop() })
+	return p
+}
+
+func TestProcessAttachTTY(t *testing.T) {
+	p := startInteractive(t, true)
+
+	first, detachFirst := p.Attach()
+	defer detachFirst()
+	second, detachSecond := p.Attach()
+	defer detachSecond()
+
+	if _, err := p.WriteInput([]byte("hello")); err != nil {
+		t.Fatalf("WriteInput failed: %v", err)
+	}
+	if _, err := p.WriteInput(p.Enter()); err != nil {
+		t.Fatalf("WriteInput failed: %v", err)
+	}
+
+	readUntil(t, first, "got hello")
+	readUntil(t, second, "got hello")
+}
+
+func TestProcessAttachStdin(t *testing.T) {
+	p := startInteractive(t, false)
+
+	output, detach := p.Attach()
+	defer detach()
+
+	if _, err := p.WriteInput(append([]byte("hi"), p.Enter()...)); err != nil {
+		t.Fatalf("WriteInput failed: %v", err)
+	}
+	readUntil(t, output, "got hi")
+
+	detach()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-output:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected output to end after detach")
+		}
+	}
+}
+
+func TestWriteInputNotRead(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	// The process never reads stdin, so the pipe fills up.
+	if err := p.Start(fmt.Sprintf("nc -l %d </dev/null; sleep 30", port)); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { p.Stop() })
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.WriteInput(make([]byte, 1<<20))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "not reading its input") {
+			t.Errorf("expected a timeout error, got %v", err)
+		}
+	case <-time.After(config.InputWriteTimeout + 5*time.Second):
+		t.Fatal("WriteInput blocked on a process that does not read its input")
+	}
+}
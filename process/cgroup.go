@@ -0,0 +1,258 @@
+package process
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cgroupFS is the cgroup v2 mount point.
+const cgroupFS = "/sys/fs/cgroup"
+
+// Names of the cgroups devserve creates under the daemon's own cgroup.
+const (
+	cgroupDaemonLeaf = "daemon.scope"
+	cgroupSliceName  = "devserve.slice"
+)
+
+// cgroupControllers are enabled on the devserve slice when available.
+var cgroupControllers = []string{"memory", "cpu", "pids"}
+
+// errCgroupUnavailable is returned when cgroups v2 delegation is missing.
+var errCgroupUnavailable = errors.New("cgroups v2 delegation is not available")
+
+var (
+	cgroupsEnabled bool
+	cgroupOnce     sync.Once
+	cgroupSlice    string
+	cgroupErr      error
+)
+
+// EnableCgroups makes processes started afterwards run in their own cgroup
+// under a devserve slice when cgroups v2 delegation is available. It is
+// called by the daemon; tests and library users keep the process-group
+// behaviour.
+func EnableCgroups() {
+	cgroupsEnabled = true
+}
+
+// devserveSlice returns the devserve slice, setting it up on first use.
+func devserveSlice() (string, error) {
+	if !cgroupsEnabled {
+		return "", errCgroupUnavailable
+	}
+	cgroupOnce.Do(func() {
+		self, err := ownCgroup("/proc/self/cgroup")
+		if err != nil {
+			cgroupErr = err
+			return
+		}
+		cgroupSlice, cgroupErr = setupSlice(cgroupFS, self, os.Getpid())
+		if cgroupErr != nil {
+			log.Printf("cgroups unavailable, falling back to rlimits: %s", cgroupErr)
+		} else {
+			log.Printf("using cgroup slice %s", cgroupSlice)
+		}
+	})
+	return cgroupSlice, cgroupErr
+}
+
+// ownCgroup returns the cgroup v2 path of the current process, relative to
+// the cgroup mount, from a /proc/<pid>/cgroup file.
+func ownCgroup(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errCgroupUnavailable, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return rest, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no unified hierarchy entry in %s", errCgroupUnavailable, path)
+}
+
+// setupSlice prepares <root>/<self>/devserve.slice for child cgroups.
+// cgroups v2 forbids enabling controllers for children of a cgroup that
+// itself holds processes, so the daemon (pid) first moves into a sibling
+// leaf cgroup. That only helps when the daemon is alone in its cgroup, as
+// it is not when started from a terminal's; the daemon is moved back if
+// the slice cannot be set up after all.
+func setupSlice(root, self string, pid int) (string, error) {
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("%w: %s is not a cgroup v2 mount", errCgroupUnavailable, root)
+	}
+	base := filepath.Join(root, self)
+
+	available, err := os.ReadFile(filepath.Join(base, "cgroup.controllers"))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errCgroupUnavailable, err)
+	}
+	var enable []string
+	for _, c := range cgroupControllers {
+		if containsField(string(available), c) {
+			enable = append(enable, "+"+c)
+		}
+	}
+
+	pids, err := cgroupPids(base)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errCgroupUnavailable, err)
+	}
+	for _, p := range pids {
+		if p != pid {
+			return "", fmt.Errorf("%w: the daemon's cgroup %s holds other processes", errCgroupUnavailable, self)
+		}
+	}
+
+	leaf := filepath.Join(base, cgroupDaemonLeaf)
+	slice := filepath.Join(base, cgroupSliceName)
+	if err := os.MkdirAll(leaf, 0755); err != nil {
+		return "", fmt.Errorf("%w: %w", errCgroupUnavailable, err)
+	}
+	if err := writeCgroupFile(leaf, "cgroup.procs", strconv.Itoa(pid)); err != nil {
+		os.Remove(leaf)
+		return "", fmt.Errorf("%w: %w", errCgroupUnavailable, err)
+	}
+	// rollback returns the daemon to its cgroup and removes the ones
+	// created for it, which rmdir leaves alone unless they are empty.
+	rollback := func(err error) (string, error) {
+		if err := writeCgroupFile(base, "cgroup.procs", strconv.Itoa(pid)); err != nil {
+			log.Printf("failed to move the daemon back to cgroup %s: %s", self, err)
+		}
+		os.Remove(slice)
+		os.Remove(leaf)
+		return "", fmt.Errorf("%w: %w", errCgroupUnavailable, err)
+	}
+
+	if err := os.MkdirAll(slice, 0755); err != nil {
+		return rollback(err)
+	}
+	if len(enable) > 0 {
+		controllers := strings.Join(enable, " ")
+		if err := writeCgroupFile(base, "cgroup.subtree_control", controllers); err != nil {
+			return rollback(err)
+		}
+		if err := writeCgroupFile(slice, "cgroup.subtree_control", controllers); err != nil {
+			return rollback(err)
+		}
+	}
+	return slice, nil
+}
+
+// createCgroup creates a cgroup for the named process under slice and
+// writes its limits.
+func createCgroup(slice, name string, l Limits) (string, error) {
+	dir := filepath.Join(slice, cgroupDirName(name))
+	// A leftover cgroup from a previous daemon can be reused if empty.
+	os.Remove(dir)
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return "", fmt.Errorf("failed to create cgroup: %w", err)
+	}
+
+	settings := map[string]string{}
+	if l.MemoryMax > 0 {
+		settings["memory.max"] = strconv.FormatInt(l.MemoryMax, 10)
+	}
+	if l.CPUQuota > 0 {
+		const period = 100000
+		settings["cpu.max"] = fmt.Sprintf("%d %d", int64(l.CPUQuota/100*period), period)
+	}
+	if l.PidsMax > 0 {
+		settings["pids.max"] = strconv.Itoa(l.PidsMax)
+	}
+	for file, value := range settings {
+		if err := writeCgroupFile(dir, file, value); err != nil {
+			os.Remove(dir)
+			return "", fmt.Errorf("failed to set %s: %w", file, err)
+		}
+	}
+	return dir, nil
+}
+
+// cgroupDirName maps a process name to a safe cgroup directory name.
+func cgroupDirName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	return safe + ".scope"
+}
+
+// cgroupPids returns the pids currently in the cgroup.
+func cgroupPids(dir string) ([]int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "cgroup.procs"))
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, field := range strings.Fields(string(data)) {
+		if pid, err := strconv.Atoi(field); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// killCgroup kills every process in the cgroup via cgroup.kill, available
+// since Linux 5.14.
+func killCgroup(dir string) error {
+	return writeCgroupFile(dir, "cgroup.kill", "1")
+}
+
+// oomKilled reports whether the kernel OOM killer fired inside the cgroup.
+func oomKilled(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "memory.events"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(line, "oom_kill "); ok {
+			n, _ := strconv.Atoi(strings.TrimSpace(rest))
+			return n > 0
+		}
+	}
+	return false
+}
+
+// removeCgroup removes an emptied cgroup, waiting briefly for the kernel
+// to finish reaping killed members.
+func removeCgroup(dir string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := os.Remove(dir)
+		if err == nil || os.IsNotExist(err) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("failed to remove cgroup %s: %w", dir, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func writeCgroupFile(dir, file, value string) error {
+	return os.WriteFile(filepath.Join(dir, file), []byte(value), 0644)
+}
+
+func containsField(s, field string) bool {
+	for _, f := range strings.Fields(s) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
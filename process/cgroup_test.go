@@ -0,0 +1,175 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCgroupRoot creates a cgroup v2-like tree with the daemon's cgroup at
+// <root>/user.slice/dev.scope.
+func fakeCgroupRoot(t *testing.T) (root, self string) {
+	t.Helper()
+	root = t.TempDir()
+	self = "/user.slice/dev.scope"
+	base := filepath.Join(root, self)
+	if err := os.MkdirAll(base, 0755); err != nil {
+		t.Fatalf("failed to create fake cgroup: %v", err)
+	}
+	os.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpuset cpu io memory pids\n"), 0644)
+	os.WriteFile(filepath.Join(base, "cgroup.controllers"), []byte("cpu memory pids\n"), 0644)
+	os.WriteFile(filepath.Join(base, "cgroup.procs"), []byte("1234\n"), 0644)
+	return root, self
+}
+
+func TestOwnCgroup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cgroup")
+	os.WriteFile(path, []byte("1:name=systemd:/legacy\n0::/user.slice/user-1000.slice/dev.scope\n"), 0644)
+
+	got, err := ownCgroup(path)
+	if err != nil {
+		t.Fatalf("ownCgroup failed: %v", err)
+	}
+	if got != "/user.slice/user-1000.slice/dev.scope" {
+		t.Errorf("unexpected cgroup %q", got)
+	}
+}
+
+func TestOwnCgroupV1Only(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cgroup")
+	os.WriteFile(path, []byte("4:memory:/docker/abc\n"), 0644)
+
+	if _, err := ownCgroup(path); err == nil {
+		t.Fatal("expected error without a unified hierarchy entry")
+	}
+}
+
+func TestSetupSlice(t *testing.T) {
+	root, self := fakeCgroupRoot(t)
+	base := filepath.Join(root, self)
+
+	slice, err := setupSlice(root, self, 1234)
+	if err != nil {
+		t.Fatalf("setupSlice failed: %v", err)
+	}
+	if slice != filepath.Join(base, cgroupSliceName) {
+		t.Errorf("unexpected slice path %q", slice)
+	}
+
+	procs, _ := os.ReadFile(filepath.Join(base, cgroupDaemonLeaf, "cgroup.procs"))
+	if string(procs) != "1234" {
+		t.Errorf("expected daemon pid moved into leaf, got %q", procs)
+	}
+	for _, dir := range []string{base, slice} {
+		control, _ := os.ReadFile(filepath.Join(dir, "cgroup.subtree_control"))
+		if string(control) != "+memory +cpu +pids" {
+			t.Errorf("expected controllers enabled in %s, got %q", dir, control)
+		}
+	}
+}
+
+func TestSetupSliceSharedCgroup(t *testing.T) {
+	root, self := fakeCgroupRoot(t)
+	base := filepath.Join(root, self)
+	// A daemon started from a terminal shares the terminal's cgroup.
+	os.WriteFile(filepath.Join(base, "cgroup.procs"), []byte("1000\n1234\n"), 0644)
+
+	if _, err := setupSlice(root, self, 1234); err == nil || !strings.Contains(err.Error(), "other processes") {
+		t.Fatalf("expected a shared cgroup to be refused, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, cgroupDaemonLeaf)); !os.IsNotExist(err) {
+		t.Errorf("expected the daemon not to be moved, got %v", err)
+	}
+}
+
+func TestSetupSliceRollsBack(t *testing.T) {
+	root, self := fakeCgroupRoot(t)
+	base := filepath.Join(root, self)
+	// Enabling controllers fails, as with EBUSY.
+	if err := os.Mkdir(filepath.Join(base, "cgroup.subtree_control"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := setupSlice(root, self, 1234); err == nil {
+		t.Fatal("expected setupSlice to fail")
+	}
+	// The leaf holds a regular cgroup.procs here, so only a real cgroup
+	// mount lets it be removed too.
+	if _, err := os.Stat(filepath.Join(base, cgroupSliceName)); !os.IsNotExist(err) {
+		t.Errorf("expected the slice to be removed, got %v", err)
+	}
+	procs, _ := os.ReadFile(filepath.Join(base, "cgroup.procs"))
+	if string(procs) != "1234" {
+		t.Errorf("expected the daemon moved back, got %q", procs)
+	}
+}
+
+func TestSetupSliceWithoutCgroupV2(t *testing.T) {
+	_, err := setupSlice(t.TempDir(), "/", 1234)
+	if err == nil {
+		t.Fatal("expected error for a root without cgroup.controllers")
+	}
+	if !strings.Contains(err.Error(), "not available") {
+		t.Errorf("expected unavailable error, got %q", err)
+	}
+}
+
+func TestCreateCgroupWritesLimits(t *testing.T) {
+	slice := t.TempDir()
+	l := Limits{MemoryMax: 512 << 20, CPUQuota: 150, PidsMax: 32}
+
+	dir, err := createCgroup(slice, "my app", l)
+	if err != nil {
+		t.Fatalf("createCgroup failed: %v", err)
+	}
+	if filepath.Base(dir) != "my_app.scope" {
+		t.Errorf("expected sanitized cgroup name, got %q", filepath.Base(dir))
+	}
+
+	want := map[string]string{
+		"memory.max": "536870912",
+		"cpu.max":    "150000 100000",
+		"pids.max":   "32",
+	}
+	for file, value := range want {
+		got, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Errorf("expected %s to be written: %v", file, err)
+			continue
+		}
+		if string(got) != value {
+			t.Errorf("%s: expected %q, got %q", file, value, got)
+		}
+	}
+}
+
+func TestOOMKilled(t *testing.T) {
+	dir := t.TempDir()
+	if oomKilled(dir) {
+		t.Error("expected false without memory.events")
+	}
+
+	os.WriteFile(filepath.Join(dir, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 0\n"), 0644)
+	if oomKilled(dir) {
+		t.Error("expected false when oom_kill is 0")
+	}
+
+	os.WriteFile(filepath.Join(dir, "memory.events"), []byte("low 0\nhigh 0\nmax 9\noom 2\noom_kill 1\n"), 0644)
+	if !oomKilled(dir) {
+		t.Error("expected true when oom_kill is non-zero")
+	}
+}
+
+func TestCgroupPids(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte("10\n11\n12\n"), 0644)
+
+	pids, err := cgroupPids(dir)
+	if err != nil {
+		t.Fatalf("cgroupPids failed: %v", err)
+	}
+	if len(pids) != 3 || pids[0] != 10 || pids[2] != 12 {
+		t.Errorf("unexpected pids %v", pids)
+	}
+}
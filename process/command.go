@@ -0,0 +1,167 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// buildCmd returns the command that runs the process: its argv directly
+// when set, otherwise command in the configured shell.
+func (p *Process) buildCmd(command string) *exec.Cmd {
+	argv := p.Argv
+	if len(argv) == 0 {
+		argv = shellArgv(p.Shell, command)
+		argv[len(argv)-1] = execForm(argv[0], command)
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = p.environ()
+	return cmd
+}
+
+// environ returns the environment of the process and its hooks: the
+// daemon's, overridden by the process's Env, and DEVSERVE_PROCESS.
+func (p *Process) environ() []string {
+	env := os.Environ()
+	for _, name := range slices.Sorted(maps.Keys(p.Env)) {
+		env = append(env, name+"="+p.Env[name])
+	}
+	return append(env, config.ProcessEnvVar+"="+p.Name)
+}
+
+// shellArgv returns the argv that runs command in shell. Shells whose
+// startup files set up version managers (nvm, asdf) are started as login
+// or interactive shells so those load: bash -lc, zsh -ic, fish -lc. The
+// shell "$SHELL" is the daemon user's shell; "" is sh.
+func shellArgv(shell, command string) []string {
+	if shell == "$SHELL" {
+		shell = os.Getenv("SHELL")
+	}
+	if shell == "" {
+		shell = "sh"
+	}
+	switch filepath.Base(shell) {
+	case "bash", "fish":
+		return []string{shell, "-lc", command}
+	case "zsh":
+		return []string{shell, "-ic", command}
+	}
+	return []string{shell, "-c", command}
+}
+
+// shellKeywords start compound commands, and run special builtins, that
+// cannot follow exec.
+var shellKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "until": true, "case": true,
+	"select": true, "function": true, "time": true, "exec": true,
+	"{": true, "!": true, "[[": true,
+	"exit": true, "return": true, "cd": true, ".": true, "source": true,
+	"export": true, "set": true, "unset": true, "eval": true, "trap": true,
+	"wait": true, "ulimit": true, "umask": true,
+}
+
+// execForm rewrites a simple shell command so the shell replaces itself
+// with it, making the tracked PID the server's rather than the shell's:
+// "PORT=3000 ./server" becomes "PORT=3000 exec ./server". exec only runs
+// programs, so a command named without a path is exec'd only when the
+// shell finds a program for it, after its startup files have defined
+// their functions and aliases (nvm, asdf and pyenv shims often are):
+//
+//	case $(command -v npm) in /*) exec npm run dev ;; *) npm run dev ;; esac
+//
+// Commands with control operators, subshells or compound commands, and
+// commands run by fish, whose syntax differs, are returned unchanged.
+func execForm(shell, command string) string {
+	if filepath.Base(shell) == "fish" {
+		return command
+	}
+	words, simple := shellWords(command)
+	if !simple {
+		return command
+	}
+	for _, w := range words {
+		if isAssignment(w.text) {
+			continue
+		}
+		if shellKeywords[w.text] {
+			return command
+		}
+		exec := command[:w.start] + "exec " + command[w.start:]
+		if strings.Contains(w.text, "/") {
+			return exec
+		}
+		return fmt.Sprintf("case $(command -v %s) in /*) %s ;; *) %s ;; esac", w.text, exec, command)
+	}
+	return command
+}
+
+type shellWord struct {
+	text  string
+	start int
+}
+
+// shellWords splits command into words, honouring quotes and backslashes.
+// simple is false when an unquoted control character (; & | newline,
+// parentheses or a backtick) appears.
+func shellWords(command string) (words []shellWord, simple bool) {
+	var (
+		cur     strings.Builder
+		start   = -1
+		quote   byte
+		escaped bool
+	)
+	flush := func() {
+		if start >= 0 {
+			words = append(words, shellWord{text: cur.String(), start: start})
+			cur.Reset()
+			start = -1
+		}
+	}
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				escaped = true
+			}
+		case c == '\\':
+			escaped = true
+		case c == '\'' || c == '"':
+			quote = c
+		case strings.IndexByte(";&|\n()`", c) >= 0:
+			return nil, false
+		case c == ' ' || c == '\t':
+			flush()
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		cur.WriteByte(c)
+	}
+	flush()
+	return words, quote == 0
+}
+
+// isAssignment reports whether word is a NAME=value variable assignment.
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && (i == 0 || !(r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package process
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExecForm(t *testing.T) {
+	guard := func(name, exec, command string) string {
+		return "case $(command -v " + name + ") in /*) " + exec + " ;; *) " + command + " ;; esac"
+	}
+	cases := []struct {
+		in, want string
+	}{
+		{"npm run dev", guard("npm", "exec npm run dev", "npm run dev")},
+		{"PORT=3000 NODE_ENV=dev npm start", guard("npm", "PORT=3000 NODE_ENV=dev exec npm start", "PORT=3000 NODE_ENV=dev npm start")},
+		{`FOO="a b" ./server --flag 'x y'`, `FOO="a b" exec ./server --flag 'x y'`},
+		{"/usr/bin/node server.js", "exec /usr/bin/node server.js"},
+		{"npm install && npm start", "npm install && npm start"},
+		{"a; b", "a; b"},
+		{"a | b", "a | b"},
+		{"server &", "server &"},
+		{"echo 'a;b' > out", guard("echo", "exec echo 'a;b' > out", "echo 'a;b' > out")},
+		{"(cd web && npm start)", "(cd web && npm start)"},
+		{"if true; then x; fi", "if true; then x; fi"},
+		{"exec node server.js", "exec node server.js"},
+		{"exit 2", "exit 2"},
+		{"cd web", "cd web"},
+		{"FOO=1", "FOO=1"},
+		{"echo 'unterminated", "echo 'unterminated"},
+	}
+	for _, c := range cases {
+		if got := execForm("sh", c.in); got != c.want {
+			t.Errorf("execForm(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+	if got := execForm("/usr/bin/fish", "npm run dev"); got != "npm run dev" {
+		t.Errorf("expected fish commands to be left alone, got %q", got)
+	}
+}
+
+func TestShellArgv(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	cases := []struct {
+		shell string
+		want  []string
+	}{
+		{"", []string{"sh", "-c", "cmd"}},
+		{"bash", []string{"bash", "-lc", "cmd"}},
+		{"/bin/zsh", []string{"/bin/zsh", "-ic", "cmd"}},
+		{"$SHELL", []string{"/usr/bin/zsh", "-ic", "cmd"}},
+		{"dash", []string{"dash", "-c", "cmd"}},
+	}
+	for _, c := range cases {
+		if got := shellArgv(c.shell, "cmd"); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("shellArgv(%q) = %v, want %v", c.shell, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"os"
+	"strings"
+)
+
+// StartError is returned by Start when the command could not be run or
+// did not become ready, with what is known about why.
+type StartError struct {
+	Err       error
+	Exit      string   // how the process exited, empty if it was still running
+	PortInUse bool     // another program has the port, judging by a connection or the logs
+	PortOwner string   // who has the port, when known
+	Command   string   // the argv run, shell included
+	Dir       string   // the working directory
+	Env       []string // diagnosticEnvVars as the process saw them
+	Stdout    []string // last lines of each log
+	Stderr    []string
+}
+
+func (e *StartError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// diagnosticEnvVars are the environment variables behind most commands
+// that fail under the daemon but work in a terminal.
+var diagnosticEnvVars = []string{"PATH", "SHELL", "HOME", "USER"}
+
+// portInUseMessages appear in the output of servers that could not bind.
+var portInUseMessages = []string{"address already in use", "eaddrinuse"}
+
+// startError collects diagnostics for err. It must be called before the
+// process is torn down, so that Exit reports how it failed on its own.
+func (p *Process) startError(err error) *StartError {
+	e := &StartError{
+		Err:     err,
+		Command: config.JoinArgs(p.Cmd.Args),
+		Dir:     p.Cmd.Dir,
+		Stdout:  tailLines(p.Stdout.Name(), config.StartErrorLines),
+		Stderr:  tailLines(p.Stderr.Name(), config.StartErrorLines),
+	}
+	if e.Dir == "" {
+		e.Dir, _ = os.Getwd()
+	}
+
+	env := make(map[string]string)
+	for _, kv := range p.Cmd.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			env[name] = value
+		}
+	}
+	for _, name := range diagnosticEnvVars {
+		if value, ok := env[name]; ok {
+			e.Env = append(e.Env, name+"="+value)
+		}
+	}
+
+	exited, reason := p.Exited()
+	e.Exit = reason
+	if _, inUse := DialPort(p.Host, p.Port); inUse && (exited || !p.ownsPort()) {
+		e.PortInUse = true
+		if owners, _ := FindPortOwners(p.Port); len(owners) > 0 {
+			ClassifyPortOwners(owners, nil)
+			e.PortOwner = owners[0].String()
+		}
+	}
+	for _, line := range append(e.Stderr, e.Stdout...) {
+		for _, msg := range portInUseMessages {
+			if strings.Contains(strings.ToLower(line), msg) {
+				e.PortInUse = true
+			}
+		}
+	}
+	return e
+}
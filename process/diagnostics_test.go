@@ -0,0 +1,72 @@
+package process_test
+
+import (
+	"github.com/jaiir320/devserve/process"
+	"github.com/jaiir320/devserve/testutil"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProcessStartErrorDiagnostics(t *testing.T) {
+	swapTunnel(t)
+
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", testutil.FreePort(t), dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+
+	err = p.Start("echo starting; echo 'Error: listen EADDRINUSE' >&2; exit 1")
+	var startErr *process.StartError
+	if !errors.As(err, &startErr) {
+		t.Fatalf("expected a StartError, got %v", err)
+	}
+	if startErr.Exit != "exited with status 1" {
+		t.Errorf("expected the exit status, got %q", startErr.Exit)
+	}
+	if !startErr.PortInUse {
+		t.Error("expected the port to be reported in use from the logs")
+	}
+	if !strings.HasPrefix(startErr.Command, "sh -c ") {
+		t.Errorf("expected the effective command, got %q", startErr.Command)
+	}
+	if startErr.Dir != dir {
+		t.Errorf("expected dir %q, got %q", dir, startErr.Dir)
+	}
+	if !slices.ContainsFunc(startErr.Env, func(kv string) bool { return strings.HasPrefix(kv, "PATH=") }) {
+		t.Errorf("expected PATH in the environment, got %q", startErr.Env)
+	}
+	if !slices.Equal(startErr.Stderr, []string{"Error: listen EADDRINUSE"}) {
+		t.Errorf("expected the last stderr lines, got %q", startErr.Stderr)
+	}
+}
+
+func TestProcessStartPortTakenByAnother(t *testing.T) {
+	swapTunnel(t)
+
+	port := testutil.OccupiedPort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+
+	err = p.Start("sleep 0.3; exit 1")
+	var startErr *process.StartError
+	if !errors.As(err, &startErr) {
+		t.Fatalf("expected a StartError, got %v", err)
+	}
+	want := fmt.Sprintf("process exited with status 1 before port %d was ready", port)
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+	if !startErr.PortInUse {
+		t.Error("expected the port to be reported in use")
+	}
+	if !strings.Contains(startErr.PortOwner, fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Errorf("expected the test process as port owner, got %q", startErr.PortOwner)
+	}
+}
@@ -0,0 +1,142 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/reaper"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Hooks are shell commands run around a process's lifecycle, in its
+// directory and environment. Empty commands are skipped.
+type Hooks struct {
+	PreStart  string // before the command starts; failure aborts the start
+	PostReady string // once the port accepts connections
+	PostStop  string // after the process tree has been killed
+}
+
+// Hook names, also used for their log files under .devserve/hooks/.
+const (
+	HookPreStart  = "pre_start"
+	HookPostReady = "post_ready"
+	HookPostStop  = "post_stop"
+)
+
+// HookError is returned when a hook fails. Output holds the last lines
+// the hook wrote.
+type HookError struct {
+	Hook   string
+	Err    error
+	Output string
+}
+
+func (e *HookError) Error() string {
+	msg := fmt.Sprintf("%s hook failed: %s", e.Hook, e.Err)
+	if e.Output != "" {
+		msg += "\n" + e.Output
+	}
+	return msg
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// hookTimeout returns how long the named hook may run.
+func hookTimeout(hook string) time.Duration {
+	switch hook {
+	case HookPreStart:
+		return config.PreStartTimeout
+	case HookPostReady:
+		return config.PostReadyTimeout
+	}
+	return config.PostStopTimeout
+}
+
+// hookCommand builds a command that runs in the process's shell and
+// directory in its own process group, killed as a whole when ctx is done.
+func (p *Process) hookCommand(ctx context.Context, command string) *exec.Cmd {
+	argv := shellArgv(p.Shell, command)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = p.Dir
+	cmd.Env = p.environ()
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+	return cmd
+}
+
+// HookLogPath returns the log file of the named hook.
+func (p *Process) HookLogPath(hook string) string {
+	return filepath.Join(p.Dir, config.ProcessLogDir, config.HookLogDir, hook+".log")
+}
+
+// runHook runs the named hook command with its output written to the
+// hook's log file, replacing the previous run's log. The hook is killed
+// when ctx is done.
+func (p *Process) runHook(ctx context.Context, hook, command string) error {
+	if command == "" {
+		return nil
+	}
+	logPath := p.HookLogPath(hook)
+	if err := os.MkdirAll(filepath.Dir(logPath), config.DirPermissions); err != nil {
+		return &HookError{Hook: hook, Err: fmt.Errorf("failed to create hook log directory: %w", err)}
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return &HookError{Hook: hook, Err: fmt.Errorf("failed to create hook log: %w", err)}
+	}
+	defer logFile.Close()
+
+	timeout := hookTimeout(hook)
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := p.hookCommand(hookCtx, command)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	log.Printf("running %s hook for %s", hook, p.Name)
+	err = reaper.Run(cmd)
+	if err == nil {
+		return nil
+	}
+	switch {
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	case hookCtx.Err() != nil:
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	return &HookError{Hook: hook, Err: err, Output: tailFile(logPath, config.HookErrorLines)}
+}
+
+// runHookAsync runs a hook in the background, logging failures.
+func (p *Process) runHookAsync(hook, command string) {
+	if command == "" {
+		return
+	}
+	go func() {
+		if err := p.runHook(context.Background(), hook, command); err != nil {
+			log.Printf("process %s: %s", p.Name, err)
+		}
+	}()
+}
+
+// tailFile returns the last n lines of the file at path.
+func tailFile(path string, n int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
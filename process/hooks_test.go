@@ -0,0 +1,120 @@
+package process_test
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/process"
+	"github.com/jaiir320/devserve/testutil"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessPreStartHookFailureAborts(t *testing.T) {
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.Hooks.PreStart = "echo installing; echo broken >&2; exit 3"
+
+	err = p.Start(fmt.Sprintf("nc -l %d", port))
+	var hookErr *process.HookError
+	if !errors.As(err, &hookErr) {
+		t.Fatalf("expected *process.HookError, got %v", err)
+	}
+	if hookErr.Hook != process.HookPreStart {
+		t.Errorf("expected hook %q, got %q", process.HookPreStart, hookErr.Hook)
+	}
+	for _, want := range []string{"exit status 3", "installing", "broken"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+	if p.Cmd != nil {
+		t.Error("expected the command not to be started")
+	}
+
+	logPath := filepath.Join(dir, config.ProcessLogDir, config.HookLogDir, "pre_start.log")
+	if _, err := os.Stat(logPath); err != nil {
+		t.Errorf("expected hook log %s: %v", logPath, err)
+	}
+}
+
+func TestProcessHooksRun(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.Hooks = process.Hooks{
+		PreStart:  "touch pre_start",
+		PostReady: "touch post_ready",
+		PostStop:  "touch post_stop",
+	}
+
+	if err := p.Start(fmt.Sprintf("nc -l %d", port)); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pre_start")); err != nil {
+		t.Errorf("expected pre_start hook to have run before Start returned: %v", err)
+	}
+
+	// post_ready runs in the background.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "post_ready")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected post_ready hook to run")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post_stop")); err != nil {
+		t.Errorf("expected post_stop hook to have run: %v", err)
+	}
+}
+
+func TestProcessEnvReachesCommandAndHooks(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.Env = map[string]string{"GREETING": "hello world", "HOME": "/overridden"}
+	p.Hooks.PreStart = `printf '%s %s' "$GREETING" "$HOME" > hook_env`
+
+	if err := p.Start(fmt.Sprintf(`printf '%%s %%s' "$GREETING" "$HOME" > cmd_env; nc -l %d`, port)); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	for _, file := range []string{"hook_env", "cmd_env"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", file, err)
+		}
+		if got := string(data); got != "hello world /overridden" {
+			t.Errorf("%s: got %q", file, got)
+		}
+	}
+}
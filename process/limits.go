@@ -0,0 +1,89 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"fmt"
+	"log"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Limits caps the resources a process and its descendants may use. Zero
+// values mean unlimited.
+type Limits struct {
+	MemoryMax int64   // bytes
+	CPUQuota  float64 // percent of one CPU, e.g. 150 for one and a half cores
+	PidsMax   int
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return l.MemoryMax == 0 && l.CPUQuota == 0 && l.PidsMax == 0
+}
+
+// ParseLimits parses the limit settings of a process config. memoryMax
+// takes a byte count with an optional K, M, G or T suffix (powers of 1024);
+// cpuQuota takes a percentage of one CPU ("150%") or a number of CPUs ("1.5").
+func ParseLimits(memoryMax, cpuQuota string, pidsMax int) (Limits, error) {
+	var l Limits
+	var err error
+	if memoryMax != "" {
+		if l.MemoryMax, err = config.ParseMemory(memoryMax); err != nil {
+			return Limits{}, err
+		}
+	}
+	if cpuQuota != "" {
+		if l.CPUQuota, err = config.ParseCPUQuota(cpuQuota); err != nil {
+			return Limits{}, err
+		}
+	}
+	if pidsMax < 0 {
+		return Limits{}, fmt.Errorf("invalid pids_max %d: must not be negative", pidsMax)
+	}
+	l.PidsMax = pidsMax
+	return l, nil
+}
+
+// Spec formats the limits back into config form, the inverse of ParseLimits.
+func (l Limits) Spec() (memoryMax, cpuQuota string, pidsMax int) {
+	if l.MemoryMax > 0 {
+		memoryMax = config.FormatMemory(l.MemoryMax)
+	}
+	if l.CPUQuota > 0 {
+		cpuQuota = strconv.FormatFloat(l.CPUQuota, 'f', -1, 64) + "%"
+	}
+	return memoryMax, cpuQuota, l.PidsMax
+}
+
+// applyRlimits is the fallback when cgroups are unavailable. It sets
+// limits on the freshly started child, which its descendants inherit.
+// RLIMIT_AS bounds virtual rather than resident memory, so it is an
+// approximation. CPU quota has no rlimit equivalent, and RLIMIT_NPROC
+// counts every process of the user rather than this tree, so a low
+// pids_max would stop the user's other programs from forking; neither is
+// enforced.
+func applyRlimits(pid int, l Limits) error {
+	if l.MemoryMax > 0 {
+		lim := unix.Rlimit{Cur: uint64(l.MemoryMax), Max: uint64(l.MemoryMax)}
+		if err := unix.Prlimit(pid, unix.RLIMIT_AS, &lim, nil); err != nil {
+			return fmt.Errorf("failed to set memory limit: %w", err)
+		}
+	}
+	if l.PidsMax > 0 {
+		log.Printf("pids_max requires cgroups v2 delegation; not enforced for pid %d", pid)
+	}
+	if l.CPUQuota > 0 {
+		log.Printf("cpu_quota requires cgroups v2 delegation; not enforced for pid %d", pid)
+	}
+	return nil
+}
+
+// signalName returns the conventional name of sig, e.g. "SIGKILL".
+func signalName(sig syscall.Signal) string {
+	if name := unix.SignalName(sig); name != "" {
+		return name
+	}
+	return sig.String()
+}
@@ -0,0 +1,102 @@
+package process_test
+
+import (
+	"github.com/jaiir320/devserve/process"
+	"strings"
+	"testing"
+)
+
+func TestParseLimits(t *testing.T) {
+	l, err := process.ParseLimits("512M", "150%", 64)
+	if err != nil {
+		t.Fatalf("ParseLimits failed: %v", err)
+	}
+	if l.MemoryMax != 512<<20 {
+		t.Errorf("expected memory %d, got %d", 512<<20, l.MemoryMax)
+	}
+	if l.CPUQuota != 150 {
+		t.Errorf("expected cpu quota 150, got %f", l.CPUQuota)
+	}
+	if l.PidsMax != 64 {
+		t.Errorf("expected pids 64, got %d", l.PidsMax)
+	}
+}
+
+func TestParseLimitsFormats(t *testing.T) {
+	cases := []struct {
+		memory string
+		want   int64
+	}{
+		{"1048576", 1 << 20},
+		{"2G", 2 << 30},
+		{"2g", 2 << 30},
+		{"256MiB", 256 << 20},
+		{"64kb", 64 << 10},
+	}
+	for _, c := range cases {
+		l, err := process.ParseLimits(c.memory, "", 0)
+		if err != nil {
+			t.Errorf("ParseLimits(%q) failed: %v", c.memory, err)
+			continue
+		}
+		if l.MemoryMax != c.want {
+			t.Errorf("ParseLimits(%q): expected %d, got %d", c.memory, c.want, l.MemoryMax)
+		}
+	}
+
+	// A bare number is a count of CPUs.
+	l, err := process.ParseLimits("", "1.5", 0)
+	if err != nil {
+		t.Fatalf("ParseLimits failed: %v", err)
+	}
+	if l.CPUQuota != 150 {
+		t.Errorf("expected 1.5 CPUs to be 150%%, got %f", l.CPUQuota)
+	}
+}
+
+func TestParseLimitsInvalid(t *testing.T) {
+	cases := []struct {
+		memory, cpu string
+		pids        int
+		want        string
+	}{
+		{"lots", "", 0, "invalid memory_max"},
+		{"-5M", "", 0, "invalid memory_max"},
+		{"", "fast", 0, "invalid cpu_quota"},
+		{"", "0%", 0, "invalid cpu_quota"},
+		{"", "Inf", 0, "invalid cpu_quota"},
+		{"", "NaN%", 0, "invalid cpu_quota"},
+		{"", "0.5%", 0, "at least 1%"},
+		{"", "0.001", 0, "at least 1%"},
+		{"", "", -1, "invalid pids_max"},
+	}
+	for _, c := range cases {
+		_, err := process.ParseLimits(c.memory, c.cpu, c.pids)
+		if err == nil {
+			t.Errorf("ParseLimits(%q, %q, %d): expected error, got nil", c.memory, c.cpu, c.pids)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("expected error to contain %q, got %q", c.want, err.Error())
+		}
+	}
+}
+
+func TestLimitsSpecRoundTrip(t *testing.T) {
+	l, err := process.ParseLimits("1536M", "50%", 10)
+	if err != nil {
+		t.Fatalf("ParseLimits failed: %v", err)
+	}
+	memory, cpu, pids := l.Spec()
+	if memory != "1536M" || cpu != "50%" || pids != 10 {
+		t.Errorf("expected 1536M 50%% 10, got %s %s %d", memory, cpu, pids)
+	}
+
+	if !(process.Limits{}).IsZero() {
+		t.Error("expected zero Limits to report IsZero")
+	}
+	memory, cpu, pids = process.Limits{}.Spec()
+	if memory != "" || cpu != "" || pids != 0 {
+		t.Errorf("expected empty spec for zero limits, got %q %q %d", memory, cpu, pids)
+	}
+}
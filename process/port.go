@@ -5,33 +5,74 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 )
 
-func CheckPortInUse(port int) error {
-	addr := "localhost:" + strconv.Itoa(port)
-	conn, err := net.DialTimeout("tcp", addr, config.PortDialTimeout)
-	if err == nil {
-		conn.Close()
+// loopbackHosts are tried in turn for processes without a host, since
+// servers may bind only one of them.
+var loopbackHosts = []string{"127.0.0.1", "::1"}
+
+// ParseHost validates the host a process's server listens on: an IP
+// address, with or without brackets, or a hostname. "" means loopback.
+func ParseHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "" || net.ParseIP(host) != nil {
+		return host, nil
+	}
+	if strings.ContainsAny(host, ":/ \t") {
+		return "", fmt.Errorf("invalid host %q: expected an IP address or hostname", host)
+	}
+	return host, nil
+}
+
+// DialPort returns the address on host that accepts connections on port,
+// trying IPv4 and then IPv6 loopback when host is "".
+func DialPort(host string, port int) (string, bool) {
+	hosts := loopbackHosts
+	if host != "" {
+		hosts = []string{host}
+	}
+	for _, h := range hosts {
+		addr := net.JoinHostPort(h, strconv.Itoa(port))
+		conn, err := net.DialTimeout("tcp", addr, config.PortDialTimeout)
+		if err == nil {
+			conn.Close()
+			return addr, true
+		}
+	}
+	return "", false
+}
+
+// Addr returns the address the process accepted connections on when it
+// became ready, or localhost and its port before then.
+func (p *Process) Addr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.addr == "" {
+		return net.JoinHostPort("localhost", strconv.Itoa(p.Port))
+	}
+	return p.addr
+}
+
+// CheckPortInUse reports an error if something accepts connections on
+// port at host, or on loopback when host is "".
+func CheckPortInUse(host string, port int) error {
+	if _, ok := DialPort(host, port); ok {
 		return fmt.Errorf("port %d is already in use", port)
 	}
 	return nil
 }
 
-func WaitForPort(port int, timeout time.Duration) error {
-	addr := "localhost:" + strconv.Itoa(port)
-	deadline := time.After(timeout)
-	for {
-		select {
-		case <-deadline:
-			return fmt.Errorf("port %d not ready after %s", port, timeout)
-		default:
-			conn, err := net.DialTimeout("tcp", addr, config.PortDialTimeout)
-			if err == nil {
-				conn.Close()
-				return nil
-			}
-			time.Sleep(config.PortPollInterval)
+// WaitForPortRelease waits until nothing accepts connections on port at
+// host, or on loopback when host is "".
+func WaitForPortRelease(host string, port int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for CheckPortInUse(host, port) != nil {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("port %d still in use after %s", port, timeout)
 		}
+		time.Sleep(config.PortReleasePollInterval)
 	}
+	return nil
 }
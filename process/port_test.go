@@ -13,7 +13,7 @@ import (
 func TestCheckPortInUseFree(t *testing.T) {
 	port := testutil.FreePort(t)
 
-	err := process.CheckPortInUse(port)
+	err := process.CheckPortInUse("", port)
 	if err != nil {
 		t.Errorf("expected no error for free port %d, got %v", port, err)
 	}
@@ -22,7 +22,7 @@ func TestCheckPortInUseFree(t *testing.T) {
 func TestCheckPortInUseOccupied(t *testing.T) {
 	port := testutil.OccupiedPort(t)
 
-	err := process.CheckPortInUse(port)
+	err := process.CheckPortInUse("", port)
 	if err == nil {
 		t.Fatalf("expected error for occupied port %d, got nil", port)
 	}
@@ -31,61 +31,75 @@ func TestCheckPortInUseOccupied(t *testing.T) {
 	}
 }
 
-func TestWaitForPortImmediate(t *testing.T) {
-	port := testutil.OccupiedPort(t)
-
-	start := time.Now()
-	err := process.WaitForPort(port, 2*time.Second)
-	elapsed := time.Since(start)
-
+func TestDialPortIPv6Loopback(t *testing.T) {
+	l, err := net.Listen("tcp", "[::1]:0")
 	if err != nil {
-		t.Fatalf("expected no error, got %v", err)
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	defer l.Close()
+	port := l.Addr().(*net.TCPAddr).Port
+
+	addr, ok := process.DialPort("", port)
+	if !ok {
+		t.Fatalf("expected port %d on ::1 to answer", port)
 	}
-	if elapsed > 1*time.Second {
-		t.Errorf("expected quick return, took %s", elapsed)
+	if want := fmt.Sprintf("[::1]:%d", port); addr != want {
+		t.Errorf("expected address %q, got %q", want, addr)
+	}
+	if process.CheckPortInUse("", port) == nil {
+		t.Error("expected a port bound only on ::1 to be in use")
+	}
+	if _, ok := process.DialPort("127.0.0.1", port); ok {
+		t.Error("expected 127.0.0.1 not to answer")
 	}
 }
 
-func TestWaitForPortDelayed(t *testing.T) {
-	port := testutil.FreePort(t)
-
-	go func() {
-		time.Sleep(200 * time.Millisecond)
-		l, err := net.Listen("tcp", "localhost:0")
-		if err != nil {
-			return
+func TestParseHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"127.0.0.1", "127.0.0.1", false},
+		{"::1", "::1", false},
+		{"[::1]", "::1", false},
+		{"172.17.0.2", "172.17.0.2", false},
+		{"web.local", "web.local", false},
+		{"localhost:3000", "", true},
+		{"http://localhost", "", true},
+	}
+	for _, tt := range tests {
+		got, err := process.ParseHost(tt.host)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseHost(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+			continue
 		}
-		// We need to listen on the specific port, not :0
-		l.Close()
-		l, _ = net.Listen("tcp", net.JoinHostPort("localhost", fmt.Sprintf("%d", port)))
-		if l != nil {
-			// Keep alive until test completes
-			time.Sleep(5 * time.Second)
-			l.Close()
+		if got != tt.want {
+			t.Errorf("ParseHost(%q) = %q, want %q", tt.host, got, tt.want)
 		}
-	}()
-
-	err := process.WaitForPort(port, 3*time.Second)
-	if err != nil {
-		t.Fatalf("expected port to become available, got %v", err)
 	}
 }
 
-func TestWaitForPortTimeout(t *testing.T) {
-	port := testutil.FreePort(t)
-
-	start := time.Now()
-	err := process.WaitForPort(port, 300*time.Millisecond)
-	elapsed := time.Since(start)
+func TestPortChecksUseHost(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("127.0.0.2 not available: %v", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
 
-	if err == nil {
-		t.Fatal("expected timeout error, got nil")
+	if process.CheckPortInUse("", port) != nil {
+		t.Fatal("expected a port bound only on 127.0.0.2 to look free on loopback")
 	}
-	if !strings.Contains(err.Error(), "not ready after") {
-		t.Errorf("expected error to contain %q, got %q", "not ready after", err.Error())
+	if process.CheckPortInUse("127.0.0.2", port) == nil {
+		t.Error("expected the port to be in use on 127.0.0.2")
 	}
-	// Should not take much longer than the timeout
-	if elapsed > 2*time.Second {
-		t.Errorf("timeout took too long: %s", elapsed)
+	if process.WaitForPortRelease("127.0.0.2", port, 100*time.Millisecond) == nil {
+		t.Error("expected the port not to be released while bound")
+	}
+
+	l.Close()
+	if err := process.WaitForPortRelease("127.0.0.2", port, 2*time.Second); err != nil {
+		t.Errorf("expected the port to be released once closed, got %v", err)
 	}
 }
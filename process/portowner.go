@@ -0,0 +1,214 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Kinds of port owner.
+const (
+	OwnerDevserve = "devserve" // a member of a process devserve is running
+	OwnerOrphan   = "orphan"   // left behind by an earlier devserve process
+	OwnerOther    = "other"    // an unrelated program
+)
+
+// PortOwner is a process with a socket listening on a TCP port.
+type PortOwner struct {
+	Pid     int
+	Command string
+	Dir     string
+	Kind    string // set by ClassifyPortOwners
+	Process string // the devserve process it belongs or belonged to
+}
+
+func (o PortOwner) String() string {
+	switch o.Kind {
+	case OwnerDevserve:
+		return fmt.Sprintf("devserve process '%s' (pid %d, %s)", o.Process, o.Pid, o.Command)
+	case OwnerOrphan:
+		return fmt.Sprintf("pid %d (%s) left behind by devserve process '%s'", o.Pid, o.Command, o.Process)
+	}
+	return fmt.Sprintf("pid %d (%s) in %s", o.Pid, o.Command, o.Dir)
+}
+
+// FindPortOwners returns the processes listening on port, found by
+// matching the listening sockets in /proc/net/tcp and tcp6 against every
+// process's file descriptors. Processes of other users cannot be inspected
+// and are missing.
+func FindPortOwners(port int) ([]PortOwner, error) {
+	return findPortOwners(procRoot, port)
+}
+
+func findPortOwners(root string, port int) ([]PortOwner, error) {
+	inodes := make(map[string]bool)
+	for _, table := range []string{"net/tcp", "net/tcp6"} {
+		data, err := os.ReadFile(filepath.Join(root, table))
+		if os.IsNotExist(err) {
+			continue // no IPv6
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		for _, inode := range listeningInodes(string(data), port) {
+			inodes[inode] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	var owners []PortOwner
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || !ownsSocket(filepath.Join(root, e.Name(), "fd"), inodes) {
+			continue
+		}
+		dir, _ := os.Readlink(filepath.Join(root, e.Name(), "cwd"))
+		owners = append(owners, PortOwner{
+			Pid:     pid,
+			Command: commandLine(root, pid),
+			Dir:     dir,
+			Process: environValue(filepath.Join(root, e.Name(), "environ"), config.ProcessEnvVar),
+		})
+	}
+	return owners, nil
+}
+
+// listeningInodes returns the inodes of the sockets listening on port in
+// a /proc/net/tcp table.
+func listeningInodes(table string, port int) []string {
+	var inodes []string
+	for _, line := range strings.Split(table, "\n") {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[3] != "0A" { // TCP_LISTEN
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		if p, err := strconv.ParseUint(hexPort, 16, 16); err == nil && int(p) == port {
+			inodes = append(inodes, fields[9])
+		}
+	}
+	return inodes
+}
+
+// ownsSocket reports whether any fd in fdDir is one of the socket inodes.
+func ownsSocket(fdDir string, inodes map[string]bool) bool {
+	fds, err := os.ReadDir(fdDir)
+	if err != nil {
+		return false
+	}
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok && inodes[strings.TrimSuffix(inode, "]")] {
+			return true
+		}
+	}
+	return false
+}
+
+// environValue returns the value of name in a /proc/<pid>/environ file.
+func environValue(path, name string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, kv := range bytes.Split(data, []byte{0}) {
+		if value, ok := bytes.CutPrefix(kv, []byte(name+"=")); ok {
+			return string(value)
+		}
+	}
+	return ""
+}
+
+// Owns reports whether pid is the process or a member of its tree.
+func (p *Process) Owns(pid int) bool {
+	p.mu.Lock()
+	started := p.started
+	p.mu.Unlock()
+	if !started {
+		return false
+	}
+	if pid == p.Cmd.Process.Pid {
+		return true
+	}
+	p.trackTree()
+	for _, member := range p.liveTracked() {
+		if member == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// ownsPort reports whether the process's tree is listening on its port,
+// or whether that cannot be told because the owners cannot be inspected
+// or have already exited, as a server that accepts one connection does.
+func (p *Process) ownsPort() bool {
+	owners, err := FindPortOwners(p.Port)
+	if err != nil || len(owners) == 0 {
+		return true
+	}
+	for _, o := range owners {
+		if p.Owns(o.Pid) {
+			return true
+		}
+		if st, err := readStat(procRoot, o.Pid); err != nil || st.state == 'Z' {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyPortOwners sets the kind of each owner: a member of one of the
+// running processes, an orphan whose environment names a devserve
+// process, or an unrelated program.
+func ClassifyPortOwners(owners []PortOwner, running []*Process) {
+	for i := range owners {
+		o := &owners[i]
+		o.Kind = OwnerOther
+		if o.Process != "" {
+			o.Kind = OwnerOrphan
+		}
+		for _, p := range running {
+			if p.Owns(o.Pid) {
+				o.Kind = OwnerDevserve
+				o.Process = p.Name
+				break
+			}
+		}
+	}
+}
+
+// KillPortOwners sends SIGTERM to pids and waits up to timeout for port
+// at host to be released, then SIGKILLs them and waits once more.
+func KillPortOwners(host string, port int, pids []int, timeout time.Duration) error {
+	for _, sig := range []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL} {
+		for _, pid := range pids {
+			if pid != os.Getpid() {
+				syscall.Kill(pid, sig)
+			}
+		}
+		if WaitForPortRelease(host, port, timeout) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("port %d still in use after killing its owners", port)
+}
@@ -0,0 +1,81 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+const fakeTCPTable = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 111 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:0BB8 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 222 1 0000000000000000 20 4 30 10 -1
+   2: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 333 1 0000000000000000 100 0 0 10 0
+`
+
+func TestListeningInodes(t *testing.T) {
+	if got := listeningInodes(fakeTCPTable, 3000); !reflect.DeepEqual(got, []string{"111"}) {
+		t.Errorf("listeningInodes(3000) = %v, want [111]", got)
+	}
+	if got := listeningInodes(fakeTCPTable, 8080); !reflect.DeepEqual(got, []string{"333"}) {
+		t.Errorf("listeningInodes(8080) = %v, want [333]", got)
+	}
+	if got := listeningInodes(fakeTCPTable, 5000); len(got) != 0 {
+		t.Errorf("expected no sockets on a free port, got %v", got)
+	}
+}
+
+// writeFakeOwner creates a fake /proc/<pid> holding a socket fd.
+func writeFakeOwner(t *testing.T, root string, pid int, inode, cmdline, cwd, environ string) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0755); err != nil {
+		t.Fatalf("failed to create fake proc dir: %v", err)
+	}
+	files := map[string]string{"cmdline": cmdline, "environ": environ}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write fake %s: %v", name, err)
+		}
+	}
+	if err := os.Symlink(cwd, filepath.Join(dir, "cwd")); err != nil {
+		t.Fatalf("failed to link fake cwd: %v", err)
+	}
+	if err := os.Symlink("socket:["+inode+"]", filepath.Join(dir, "fd", "3")); err != nil {
+		t.Fatalf("failed to link fake fd: %v", err)
+	}
+}
+
+func TestFindPortOwners(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "net"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "net", "tcp"), []byte(fakeTCPTable), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeFakeOwner(t, root, 42, "111", "node\x00server.js\x00", "/projects/web", "HOME=/home/dev\x00"+config.ProcessEnvVar+"=web\x00")
+	writeFakeOwner(t, root, 43, "222", "curl\x00localhost:3000\x00", "/tmp", "")
+	writeFakeOwner(t, root, 44, "333", "python3\x00-m\x00http.server\x00", "/srv", "")
+
+	owners, err := findPortOwners(root, 3000)
+	if err != nil {
+		t.Fatalf("findPortOwners failed: %v", err)
+	}
+	want := []PortOwner{{Pid: 42, Command: "node server.js", Dir: "/projects/web", Process: "web"}}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("findPortOwners(3000) = %+v, want %+v", owners, want)
+	}
+
+	ClassifyPortOwners(owners, nil)
+	if owners[0].Kind != OwnerOrphan {
+		t.Errorf("expected an orphan, got %q", owners[0].Kind)
+	}
+
+	owners, err = findPortOwners(root, 5000)
+	if err != nil || owners != nil {
+		t.Errorf("expected no owners of a free port, got %+v, %v", owners, err)
+	}
+}
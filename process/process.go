@@ -2,30 +2,60 @@ package process
 
 import (
 	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/reaper"
 	"github.com/jaiir320/devserve/tunnel"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sync"
 	"syscall"
 	"time"
 )
 
 type Process struct {
-	Name    string
-	Cmd     *exec.Cmd
-	Port    int
-	Dir     string
-	Command string
-	Stdout  *os.File
-	Stderr  *os.File
+	Name         string
+	Cmd          *exec.Cmd
+	Port         int
+	Dir          string
+	Command      string   // shell command, or the quoted Argv for display
+	Argv         []string // run directly instead of through a shell when set
+	Shell        string   // shell for Command and hooks: "" (sh), "bash", "zsh", "$SHELL", or a path
+	TTY          bool     // run on a pseudo-terminal, with its output in the stdout log
+	StripANSI    bool     // remove escape sequences from TTY output before logging it
+	Limits       Limits
+	StopPolicy   StopPolicy
+	Hooks        Hooks
+	ReadyWhen    *regexp.Regexp    // ready on the first output line matching, as well as the port
+	StartTimeout time.Duration     // how long Start waits for readiness, config.PortWaitTimeout if zero
+	Host         string            // where the server listens; "" tries IPv4 and IPv6 loopback
+	Env          map[string]string // set for the command and hooks on top of the daemon's environment
+	Tags         []string          // labels for grouping, kept for the config
+	Stdout       *os.File
+	Stderr       *os.File
 
 	mu            sync.Mutex
 	started       bool
+	startedAt     time.Time
 	stopped       bool
 	processKilled bool
+	usage         *usageHistory
+	cgroup        string        // cgroup directory, empty when only the process group is used
+	exited        chan struct{} // closed once the child has been reaped
+	exitReason    string
+	tracked       map[int]uint64 // pid -> start time of processes seen in the tree
+	pty           *os.File       // pty master when TTY is set
+	ttyDone       chan struct{}  // closed once the pty output has been copied
+	stdin         *os.File       // stdin pipe when TTY is not set
+	viewers       map[chan []byte]struct{}
+	viewersClosed bool
+	readyLine     string // the output line that matched ReadyWhen
+	addr          string // the address that accepted connections once ready
+	progress      StartProgress
 }
 
 func CreateProcess(name string, port int, dir string, command string) (*Process, error) {
@@ -54,7 +84,25 @@ func CreateProcess(name string, port int, dir string, command string) (*Process,
 }
 
 func (p *Process) Start(command string) error {
-	p.Cmd = exec.Command("sh", "-c", command)
+	return p.StartContext(context.Background(), command)
+}
+
+// StartContext starts the process and waits until it is ready. If ctx is
+// cancelled first, the process is torn down and ErrStartCancelled is
+// returned.
+func (p *Process) StartContext(ctx context.Context, command string) error {
+	if p.Hooks.PreStart != "" {
+		p.setStage("running " + HookPreStart + " hook")
+	}
+	if err := p.runHook(ctx, HookPreStart, p.Hooks.PreStart); err != nil {
+		p.closeLogs()
+		if ctx.Err() != nil {
+			return ErrStartCancelled
+		}
+		return err
+	}
+
+	p.Cmd = p.buildCmd(command)
 
 	p.Cmd.Stderr = p.Stderr
 	p.Cmd.Stdout = p.Stdout
@@ -64,34 +112,220 @@ func (p *Process) Start(command string) error {
 
 	p.Cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
-	err := p.Cmd.Start()
+	// slave is the child's end of its terminal or stdin pipe, closed here
+	// once the child has it.
+	var slave *os.File
+	if p.TTY {
+		var err error
+		if slave, err = p.startTTY(); err != nil {
+			p.closeLogs()
+			return err
+		}
+	} else {
+		// Kept open so input can be sent later; wait closes it. Unlike
+		// StdinPipe's, an os.Pipe takes write deadlines.
+		r, w, err := os.Pipe()
+		if err != nil {
+			p.closeLogs()
+			return fmt.Errorf("failed to create stdin pipe: %w", err)
+		}
+		p.Cmd.Stdin = r
+		slave, p.stdin = r, w
+	}
+
+	cgroupFD := p.prepareCgroup()
+	err := reaper.Start(p.Cmd)
+	if cgroupFD >= 0 {
+		syscall.Close(cgroupFD)
+	}
+	if slave != nil {
+		slave.Close()
+	}
 	if err != nil {
+		if p.cgroup != "" {
+			os.Remove(p.cgroup)
+		}
+		if p.stdin != nil {
+			p.stdin.Close()
+		}
 		p.closeLogs()
-		return fmt.Errorf("failed to start command: %w", err)
+		return p.startError(fmt.Errorf("failed to start command: %w", err))
+	}
+	if p.pty != nil {
+		p.ttyDone = make(chan struct{})
+		go func() {
+			p.copyTTY()
+			close(p.ttyDone)
+		}()
 	}
 
 	p.mu.Lock()
 	p.started = true
+	p.startedAt = time.Now()
+	p.exited = make(chan struct{})
 	p.mu.Unlock()
+	go p.wait()
 
-	log.Printf("waiting for port %d...", p.Port)
-	if err := WaitForPort(p.Port, config.PortWaitTimeout); err != nil {
-		syscall.Kill(-p.Cmd.Process.Pid, syscall.SIGTERM)
-		p.closeLogs()
-		return fmt.Errorf("failed to wait for port %d: %w", p.Port, err)
+	if p.cgroup == "" && !p.Limits.IsZero() {
+		if err := applyRlimits(p.Cmd.Process.Pid, p.Limits); err != nil {
+			p.abort()
+			return fmt.Errorf("failed to apply resource limits: %w", err)
+		}
 	}
 
-	if err := tunnel.DefaultTunnel.Serve(p.Port); err != nil {
-		sysErr := syscall.Kill(-p.Cmd.Process.Pid, syscall.SIGTERM)
-		p.closeLogs()
+	if err := p.waitReady(ctx, p.startTimeout()); err != nil {
+		if err != ErrStartCancelled {
+			err = p.startError(err)
+		}
+		p.abort()
+		return err
+	}
+
+	if err := tunnel.DefaultTunnel.Serve(p.Port, p.Addr()); err != nil {
+		sysErr := p.abort()
 		if sysErr != nil {
 			return fmt.Errorf("failed to kill process after tailscale error: %w", sysErr)
 		}
 		return fmt.Errorf("failed to enable tailscale serve: %w", err)
 	}
+
+	p.runHookAsync(HookPostReady, p.Hooks.PostReady)
 	return nil
 }
 
+// prepareCgroup creates the process's cgroup when cgroups are enabled and
+// configures the command to be cloned straight into it. It returns the
+// cgroup directory fd to close after starting, or -1.
+func (p *Process) prepareCgroup() int {
+	slice, err := devserveSlice()
+	if err != nil {
+		return -1
+	}
+	dir, err := createCgroup(slice, p.Name, p.Limits)
+	if err != nil {
+		log.Printf("failed to create cgroup for %s, falling back to rlimits: %s", p.Name, err)
+		return -1
+	}
+	fd, err := syscall.Open(dir, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		log.Printf("failed to open cgroup for %s, falling back to rlimits: %s", p.Name, err)
+		os.Remove(dir)
+		return -1
+	}
+	p.cgroup = dir
+	p.Cmd.SysProcAttr.UseCgroupFD = true
+	p.Cmd.SysProcAttr.CgroupFD = fd
+	return fd
+}
+
+// wait reaps the child and records why it exited.
+func (p *Process) wait() {
+	err := reaper.Wait(p.Cmd)
+	if p.stdin != nil {
+		p.stdin.Close()
+	}
+
+	reason := describeExit(p.Cmd.ProcessState, err)
+	if p.cgroup != "" && oomKilled(p.cgroup) {
+		memoryMax, _, _ := p.Limits.Spec()
+		reason = "killed by the OOM killer"
+		if memoryMax != "" {
+			reason += " (memory_max " + memoryMax + ")"
+		}
+	}
+
+	p.mu.Lock()
+	p.exitReason = reason
+	p.mu.Unlock()
+	log.Printf("process %s (pid %d) %s", p.Name, p.Cmd.Process.Pid, reason)
+	close(p.exited)
+}
+
+// describeExit turns the result of Cmd.Wait into a short explanation.
+func describeExit(state *os.ProcessState, err error) string {
+	if state == nil {
+		return fmt.Sprintf("exited: %s", err)
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return "killed by " + signalName(ws.Signal())
+	}
+	return fmt.Sprintf("exited with status %d", state.ExitCode())
+}
+
+// StartedAt returns when the command was started, or the zero time if
+// it has not been.
+func (p *Process) StartedAt() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.startedAt
+}
+
+// Exited reports whether the child has exited, and why.
+func (p *Process) Exited() (bool, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.exited == nil {
+		return false, ""
+	}
+	select {
+	case <-p.exited:
+		return true, p.exitReason
+	default:
+		return false, ""
+	}
+}
+
+// Cgroup returns the process's cgroup directory, or "" if it runs without one.
+func (p *Process) Cgroup() string {
+	return p.cgroup
+}
+
+// signal sends sig to the process group, to every member of the cgroup
+// when the process has one, and to tracked descendants, so that children
+// which left the group are reached too. A group that no longer exists is
+// not an error.
+func (p *Process) signal(sig syscall.Signal) error {
+	err := syscall.Kill(-p.Cmd.Process.Pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		err = nil
+	}
+	if p.cgroup != "" {
+		pids, _ := cgroupPids(p.cgroup)
+		for _, pid := range pids {
+			syscall.Kill(pid, sig)
+		}
+	}
+	for _, pid := range p.liveTracked() {
+		syscall.Kill(pid, sig)
+	}
+	return err
+}
+
+// kill forcibly terminates the process, using cgroup.kill when available.
+func (p *Process) kill() error {
+	if p.cgroup != "" {
+		if err := killCgroup(p.cgroup); err == nil {
+			return nil
+		}
+	}
+	return p.signal(syscall.SIGKILL)
+}
+
+// abort tears down a process whose start failed: it is sent SIGTERM, its
+// logs are closed and its cgroup is removed once it has exited.
+func (p *Process) abort() error {
+	err := p.signal(syscall.SIGTERM)
+	p.closeLogs()
+	if p.cgroup != "" {
+		go func() {
+			<-p.exited
+			killCgroup(p.cgroup)
+			removeCgroup(p.cgroup, config.StopGracePeriod)
+		}()
+	}
+	return err
+}
+
 func (p *Process) Stop() error {
 	p.mu.Lock()
 	if !p.started {
@@ -107,29 +341,43 @@ func (p *Process) Stop() error {
 
 	if needsKill {
 		log.Printf("stopping process %s (pid %d)", p.Name, p.Cmd.Process.Pid)
-		err := syscall.Kill(-p.Cmd.Process.Pid, syscall.SIGTERM)
+		p.trackTree()
+		p.runPreStop()
+
+		sig := p.StopPolicy.signal()
+		err := p.signal(sig)
 		if err != nil {
-			return fmt.Errorf("failed to send SIGTERM to process '%s': %w", p.Name, err)
+			return fmt.Errorf("failed to send %s to process '%s': %w", signalName(sig), p.Name, err)
 		}
 
-		// Wait for the process to exit with a 5s timeout, escalate to SIGKILL if needed
-		done := make(chan error, 1)
-		go func() {
-			done <- p.Cmd.Wait()
-		}()
-
+		// Wait for the process to exit within its grace period, escalate to SIGKILL if needed
 		select {
-		case <-done:
+		case <-p.exited:
 			log.Printf("process %s exited gracefully", p.Name)
-		case <-time.After(config.StopGracePeriod):
-			log.Printf("process %s did not exit after SIGTERM, sending SIGKILL", p.Name)
-			if killErr := syscall.Kill(-p.Cmd.Process.Pid, syscall.SIGKILL); killErr != nil {
+		case <-time.After(p.StopPolicy.gracePeriod()):
+			log.Printf("process %s did not exit after %s, sending SIGKILL", p.Name, signalName(sig))
+			if killErr := p.kill(); killErr != nil {
 				log.Printf("failed to SIGKILL process %s: %s", p.Name, killErr)
 			}
-			<-done // wait for Wait() to return after SIGKILL
+			<-p.exited // wait for Wait() to return after SIGKILL
 			log.Printf("process %s killed with SIGKILL", p.Name)
 		}
 
+		// Descendants can outlive the main process, having left its process
+		// group or been reparented; kill whatever is left of the tree.
+		p.trackTree()
+		p.killTree(config.TreeKillTimeout)
+		if p.cgroup != "" {
+			killCgroup(p.cgroup)
+			if err := removeCgroup(p.cgroup, config.StopGracePeriod); err != nil {
+				log.Printf("process %s: %s", p.Name, err)
+			}
+		}
+
+		if err := p.runHook(context.Background(), HookPostStop, p.Hooks.PostStop); err != nil {
+			log.Printf("process %s: %s", p.Name, err)
+		}
+
 		p.closeLogs()
 
 		p.mu.Lock()
@@ -144,10 +392,33 @@ func (p *Process) Stop() error {
 	p.mu.Lock()
 	p.stopped = true
 	p.mu.Unlock()
+
+	// Report anything left behind rather than claiming success.
+	stopErr := &StopError{Name: p.Name, Port: p.Port, Survivors: p.survivors()}
+	if err := WaitForPortRelease(p.Host, p.Port, config.PortReleaseTimeout); err != nil {
+		stopErr.PortBound = true
+	}
+	if len(stopErr.Survivors) > 0 || stopErr.PortBound {
+		log.Printf("%s", stopErr)
+		return stopErr
+	}
 	return nil
 }
 
 func (p *Process) closeLogs() {
+	if p.pty != nil {
+		// Let the copy drain what the exited process wrote before closing.
+		if p.ttyDone != nil {
+			select {
+			case <-p.ttyDone:
+			case <-time.After(config.TTYDrainTimeout):
+			}
+		}
+		p.pty.Close()
+		if p.ttyDone != nil {
+			<-p.ttyDone
+		}
+	}
 	if p.Stdout != nil {
 		p.Stdout.Close()
 	}
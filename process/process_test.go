@@ -5,11 +5,15 @@ import (
 	"github.com/jaiir320/devserve/process"
 	"github.com/jaiir320/devserve/testutil"
 	"github.com/jaiir320/devserve/tunnel"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // failOnceStopTunnel fails the first Stop() call and succeeds on subsequent calls.
@@ -18,7 +22,7 @@ type failOnceStopTunnel struct {
 	stopCalls int
 }
 
-func (f *failOnceStopTunnel) Serve(port int) error { return nil }
+func (f *failOnceStopTunnel) Serve(port int, upstream string) error { return nil }
 func (f *failOnceStopTunnel) Stop(port int) error {
 	f.stopCalls++
 	if f.stopCalls == 1 {
@@ -91,7 +95,7 @@ func TestProcessStart(t *testing.T) {
 		t.Fatal("expected process to be running")
 	}
 
-	// Port was verified reachable by Start() via WaitForPort.
+	// Port was verified reachable by Start() before it returned.
 	// nc -l exits after the first connection, so we just verify the process started.
 }
 
@@ -214,11 +218,53 @@ func TestProcessStopCanRetryAfterTailscaleFailure(t *testing.T) {
 	}
 }
 
+// upstreamTunnel records the upstream address Serve() was given.
+type upstreamTunnel struct {
+	upstream string
+}
+
+func (u *upstreamTunnel) Serve(port int, upstream string) error {
+	u.upstream = upstream
+	return nil
+}
+func (u *upstreamTunnel) Stop(port int) error { return nil }
+
+func TestProcessStartServesAnsweringAddress(t *testing.T) {
+	testutil.RequireNC(t)
+
+	mockTunnel := &upstreamTunnel{}
+	original := tunnel.DefaultTunnel
+	tunnel.SetTunnel(mockTunnel)
+	t.Cleanup(func() { tunnel.SetTunnel(original) })
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.Host = "127.0.0.1"
+
+	if err := p.Start(fmt.Sprintf("nc -l %d; sleep 30", port)); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	want := fmt.Sprintf("127.0.0.1:%d", port)
+	if p.Addr() != want {
+		t.Errorf("expected address %q, got %q", want, p.Addr())
+	}
+	if mockTunnel.upstream != want {
+		t.Errorf("expected the tunnel to proxy to %q, got %q", want, mockTunnel.upstream)
+	}
+}
+
 // failServeTunnel fails on Serve() but succeeds on Stop().
 type failServeTunnel struct{}
 
-func (failServeTunnel) Serve(port int) error { return fmt.Errorf("tailscale serve failed") }
-func (failServeTunnel) Stop(port int) error  { return nil }
+func (failServeTunnel) Serve(port int, upstream string) error {
+	return fmt.Errorf("tailscale serve failed")
+}
+func (failServeTunnel) Stop(port int) error { return nil }
 
 func TestProcessStartTailscaleFails(t *testing.T) {
 	testutil.RequireNC(t)
@@ -248,3 +294,261 @@ func TestProcessStartTailscaleFails(t *testing.T) {
 	// If process is alive, logs might still be open depending on timing
 	// The main assertion is that we got the proper error and cleanup was attempted
 }
+
+func TestProcessExitedReason(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+
+	// nc -l exits after the connection made by the readiness check closes.
+	if err := p.Start(fmt.Sprintf("nc -l %d", port)); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		exited, reason := p.Exited()
+		if exited {
+			if !strings.Contains(reason, "exited with status") {
+				t.Errorf("expected exit status in reason, got %q", reason)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected process to be reported as exited")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestProcessStopKillsEscapedDescendants(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+
+	// The child leaves the process group with setsid and ignores SIGTERM,
+	// so only the tree walk can find and kill it.
+	cmd := fmt.Sprintf(`setsid sh -c 'trap "" TERM; echo $$ > child.pid; sleep 30' & nc -l %d; wait`, port)
+	if err := p.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	var pid int
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "child.pid"))
+		if err == nil {
+			if _, err := fmt.Sscan(string(data), &pid); err == nil {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("escaped child did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if processRunning(pid) {
+		t.Errorf("expected escaped child %d to be killed", pid)
+	}
+}
+
+func TestProcessStopReportsBoundPort(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	if err := p.Start(fmt.Sprintf("nc -l %d", port)); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Something else grabs the port once nc has exited.
+	var ln net.Listener
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ln, err = net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("failed to bind port %d: %v", port, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	err = p.Stop()
+	var stopErr *process.StopError
+	if !errors.As(err, &stopErr) {
+		t.Fatalf("expected *process.StopError, got %v", err)
+	}
+	if !stopErr.PortBound {
+		t.Error("expected PortBound to be set")
+	}
+	if !strings.Contains(err.Error(), fmt.Sprintf("port %d is still in use", port)) {
+		t.Errorf("unexpected error message: %q", err.Error())
+	}
+}
+
+// processRunning reports whether pid exists and is not a zombie.
+func processRunning(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	s := string(data)
+	fields := strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestProcessStartArgv(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.Argv = []string{"nc", "-l", fmt.Sprint(port)}
+
+	if err := p.Start(""); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	if filepath.Base(p.Cmd.Path) != "nc" {
+		t.Errorf("expected nc to be run directly, got %s", p.Cmd.Path)
+	}
+}
+
+func TestProcessStartExecReplacesShell(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+
+	// The pid the shell reports for itself is the pid devserve tracks, and
+	// after exec it belongs to the command rather than to sh.
+	script := filepath.Join(dir, "server.sh")
+	body := fmt.Sprintf("#!/bin/sh\necho $$ > pid.txt\nexec nc -l %d\n", port)
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	if err := p.Start("PORT=1 ./server.sh"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	data, err := os.ReadFile(filepath.Join(dir, "pid.txt"))
+	if err != nil {
+		t.Fatalf("failed to read pid: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != fmt.Sprint(p.Cmd.Process.Pid) {
+		t.Errorf("expected tracked pid %d to be the command's, got %s", p.Cmd.Process.Pid, got)
+	}
+}
+
+func TestProcessStartShellFunction(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	// /etc/profile may reset PATH for the login shell.
+	nc, err := exec.LookPath("nc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Version managers define commands as functions in the startup files
+	// bash -lc reads; exec cannot run those.
+	port := testutil.FreePort(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	profile := fmt.Sprintf("serve() { echo function > ran.txt; %s -l %d; }\n", nc, port)
+	if err := os.WriteFile(filepath.Join(home, ".bash_profile"), []byte(profile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.Shell = "bash"
+	if err := p.Start("serve"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	if _, err := os.Stat(filepath.Join(dir, "ran.txt")); err != nil {
+		t.Errorf("expected the function to run: %v", err)
+	}
+}
+
+func TestProcessStartTTY(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.TTY = true
+	p.StripANSI = true
+
+	cmd := fmt.Sprintf(`[ -t 1 ] && printf '\033[32mon a tty\033[0m\n'; nc -l %d; sleep 30`, port)
+	if err := p.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := p.Resize(50, 200); err != nil {
+		t.Errorf("Resize failed: %v", err)
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, config.ProcessLogDir, config.ProcessStdoutLog))
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	if string(data) != "on a tty\n" {
+		t.Errorf("expected stripped tty output, got %q", data)
+	}
+}
@@ -0,0 +1,203 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Default terminal size for processes started with a TTY, until a client
+// resizes it.
+const (
+	defaultTTYRows = 40
+	defaultTTYCols = 120
+)
+
+// openPTY allocates a pseudo-terminal pair. The master stays with the
+// daemon; the slave becomes the child's stdin, stdout and stderr.
+func openPTY() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pty: %w", err)
+	}
+
+	var n int
+	var ioctlErr error
+	// Fd() would switch the master to blocking mode, which keeps Close
+	// from interrupting a pending Read.
+	conn, err := master.SyscallConn()
+	if err == nil {
+		err = conn.Control(func(fd uintptr) {
+			if ioctlErr = unix.IoctlSetPointerInt(int(fd), unix.TIOCSPTLCK, 0); ioctlErr != nil {
+				return
+			}
+			n, ioctlErr = unix.IoctlGetInt(int(fd), unix.TIOCGPTN)
+		})
+	}
+	if err = errors.Join(err, ioctlErr); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("failed to set up pty: %w", err)
+	}
+
+	slave, err = os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("failed to open pty: %w", err)
+	}
+	return master, slave, nil
+}
+
+// setWinsize sets the terminal size of a pty; the kernel sends SIGWINCH
+// to the terminal's foreground process group.
+func setWinsize(f *os.File, rows, cols int) error {
+	if rows <= 0 || cols <= 0 || rows > 0xffff || cols > 0xffff {
+		return fmt.Errorf("invalid terminal size %dx%d", cols, rows)
+	}
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ioctlErr error
+	err = conn.Control(func(fd uintptr) {
+		ws := &unix.Winsize{Row: uint16(rows), Col: uint16(cols)}
+		ioctlErr = unix.IoctlSetWinsize(int(fd), unix.TIOCSWINSZ, ws)
+	})
+	return errors.Join(err, ioctlErr)
+}
+
+// startTTY prepares the command to run on a new pty as session leader
+// with the pty as its controlling terminal. The returned slave must be
+// closed once the command has started.
+func (p *Process) startTTY() (*os.File, error) {
+	master, slave, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	if err := setWinsize(master, defaultTTYRows, defaultTTYCols); err != nil {
+		master.Close()
+		slave.Close()
+		return nil, fmt.Errorf("failed to set terminal size: %w", err)
+	}
+	p.pty = master
+	p.Cmd.Stdin = slave
+	p.Cmd.Stdout = slave
+	p.Cmd.Stderr = slave
+	// A new session makes the child a process group leader, as Setpgid
+	// does otherwise, so group signals still reach the whole tree.
+	p.Cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	return slave, nil
+}
+
+// copyTTY records the pty's output in the stdout log and passes it to
+// attached viewers until the child and its descendants have closed the
+// terminal.
+func (p *Process) copyTTY() {
+	defer p.closeViewers()
+	var w io.Writer = p.Stdout
+	if p.StripANSI {
+		w = newANSIStripper(p.Stdout)
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := p.pty.Read(buf)
+		if n > 0 {
+			p.broadcast(buf[:n])
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				log.Printf("process %s: failed to write tty output: %s", p.Name, werr)
+				return
+			}
+		}
+		if err != nil {
+			// EIO means every slave fd is closed.
+			return
+		}
+	}
+}
+
+// Resize sets the terminal size of a process started with a TTY.
+func (p *Process) Resize(rows, cols int) error {
+	if p.pty == nil {
+		return fmt.Errorf("process '%s' has no terminal", p.Name)
+	}
+	return setWinsize(p.pty, rows, cols)
+}
+
+// ansiStripper removes terminal escape sequences and the carriage
+// returns the pty adds before newlines. Sequences may be split across
+// writes, so the parser state is kept between calls.
+type ansiStripper struct {
+	w     io.Writer
+	state ansiState
+	cr    bool // a carriage return is pending
+}
+
+type ansiState int
+
+const (
+	ansiText   ansiState = iota
+	ansiEsc              // after ESC
+	ansiCSI              // ESC [ parameters, until a final byte
+	ansiOSC              // ESC ] string, until BEL or ESC \
+	ansiOSCEsc           // ESC inside an OSC string
+)
+
+func newANSIStripper(w io.Writer) *ansiStripper {
+	return &ansiStripper{w: w}
+}
+
+func (s *ansiStripper) Write(b []byte) (int, error) {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		switch s.state {
+		case ansiText:
+			switch c {
+			case 0x1b:
+				s.state = ansiEsc
+				continue
+			case '\r':
+				if s.cr {
+					out = append(out, '\r')
+				}
+				s.cr = true
+				continue
+			}
+			if s.cr && c != '\n' {
+				out = append(out, '\r')
+			}
+			s.cr = false
+			out = append(out, c)
+		case ansiEsc:
+			switch c {
+			case '[':
+				s.state = ansiCSI
+			case ']':
+				s.state = ansiOSC
+			default:
+				s.state = ansiText
+			}
+		case ansiCSI:
+			if c >= 0x40 && c <= 0x7e {
+				s.state = ansiText
+			}
+		case ansiOSC:
+			switch c {
+			case 0x07:
+				s.state = ansiText
+			case 0x1b:
+				s.state = ansiOSCEsc
+			}
+		case ansiOSCEsc:
+			s.state = ansiText
+		}
+	}
+	if _, err := s.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
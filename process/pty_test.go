@@ -0,0 +1,54 @@
+package process
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestANSIStripper(t *testing.T) {
+	var out bytes.Buffer
+	s := newANSIStripper(&out)
+
+	// Sequences and CRLF pairs split across writes.
+	chunks := []string{
+		"\x1b[1;3", "1mred\x1b[0m\r", "\nplain\r\n",
+		"\x1b]0;title\x07osc\x1b]8;;http://x\x1b", "\\link\r\n",
+		"progress 1\rprogress 2\r\n",
+	}
+	for _, c := range chunks {
+		if _, err := s.Write([]byte(c)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	want := "red\nplain\nosclink\nprogress 1\rprogress 2\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestPTYWinsize(t *testing.T) {
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Skipf("pty unavailable: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	if err := setWinsize(master, 30, 100); err != nil {
+		t.Fatalf("setWinsize failed: %v", err)
+	}
+	ws, err := unix.IoctlGetWinsize(int(slave.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		t.Fatalf("failed to read terminal size: %v", err)
+	}
+	if ws.Row != 30 || ws.Col != 100 {
+		t.Errorf("expected 100x30, got %dx%d", ws.Col, ws.Row)
+	}
+
+	if err := setWinsize(master, 0, 80); err == nil {
+		t.Error("expected error for zero rows")
+	}
+}
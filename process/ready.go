@@ -0,0 +1,205 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReadyError reports a process that did not print a line matching its
+// ready pattern. Start returns it inside a StartError with the last lines
+// of the logs.
+type ReadyError struct {
+	Pattern string
+	Timeout time.Duration
+	Exit    string // why the process exited first, empty on timeout
+}
+
+func (e *ReadyError) Error() string {
+	if e.Exit != "" {
+		return fmt.Sprintf("process %s before printing a line matching %q", e.Exit, e.Pattern)
+	}
+	return fmt.Sprintf("no output matched %q after %s", e.Pattern, e.Timeout)
+}
+
+// logLines reads the complete lines appended to a log file.
+type logLines struct {
+	f       *os.File
+	partial []byte
+}
+
+// read returns the lines completed since the last call.
+func (l *logLines) read(buf []byte) []string {
+	var lines []string
+	for {
+		n, _ := l.f.Read(buf)
+		if n == 0 {
+			return lines
+		}
+		l.partial = append(l.partial, buf[:n]...)
+		for {
+			i := bytes.IndexByte(l.partial, '\n')
+			if i < 0 {
+				break
+			}
+			lines = append(lines, string(l.partial[:i]))
+			l.partial = l.partial[i+1:]
+		}
+	}
+}
+
+// ErrStartCancelled is returned by StartContext when its context is
+// cancelled before the process is ready.
+var ErrStartCancelled = errors.New("start cancelled")
+
+// StartProgress describes what a starting process is waiting for.
+type StartProgress struct {
+	Stage string // e.g. "waiting for port 3000"
+	Line  string // the last line of output, escape sequences removed
+}
+
+// StartProgress returns what Start is currently waiting for.
+func (p *Process) StartProgress() StartProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.progress
+}
+
+func (p *Process) setStage(stage string) {
+	p.mu.Lock()
+	p.progress.Stage = stage
+	p.mu.Unlock()
+}
+
+func (p *Process) setLastLine(line string) {
+	p.mu.Lock()
+	p.progress.Line = line
+	p.mu.Unlock()
+}
+
+// startTimeout returns how long Start waits for the process to become
+// ready, defaulting to config.PortWaitTimeout.
+func (p *Process) startTimeout() time.Duration {
+	if p.StartTimeout == 0 {
+		return config.PortWaitTimeout
+	}
+	return p.StartTimeout
+}
+
+// waitReady waits until the process's port accepts connections and, when
+// ReadyWhen is set, a line of its stdout or stderr log has matched. It
+// fails when the process and everything it started have exited, timeout
+// passes or ctx is cancelled.
+func (p *Process) waitReady(ctx context.Context, timeout time.Duration) error {
+	var logs []*logLines
+	for _, logFile := range []*os.File{p.Stdout, p.Stderr} {
+		f, err := os.Open(logFile.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read log: %w", err)
+		}
+		defer f.Close()
+		logs = append(logs, &logLines{f: f})
+	}
+
+	matched := p.ReadyWhen == nil
+	if matched {
+		p.setStage(fmt.Sprintf("waiting for port %d", p.Port))
+	} else {
+		p.setStage(fmt.Sprintf("waiting for output matching %q", p.ReadyWhen))
+	}
+	log.Printf("process %s: %s...", p.Name, p.StartProgress().Stage)
+
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(config.ReadyPollInterval)
+	defer ticker.Stop()
+	buf := make([]byte, 32*1024)
+	for {
+		// Check the exit before reading so output written just before it
+		// is not missed.
+		exited, reason := p.Exited()
+		for _, l := range logs {
+			for _, line := range l.read(buf) {
+				line = stripANSI(line)
+				if strings.TrimSpace(line) != "" {
+					p.setLastLine(line)
+				}
+				if !matched && p.ReadyWhen.MatchString(line) {
+					matched = true
+					p.mu.Lock()
+					p.readyLine = line
+					p.mu.Unlock()
+					p.setStage(fmt.Sprintf("waiting for port %d", p.Port))
+				}
+			}
+		}
+
+		// Check the exit first: another program listening on the port
+		// does not make this one ready.
+		if exited {
+			// A command that daemonizes exits while its server starts up.
+			p.trackTree()
+			if len(p.liveTracked()) == 0 {
+				if !matched {
+					return &ReadyError{Pattern: p.ReadyWhen.String(), Timeout: timeout, Exit: reason}
+				}
+				return fmt.Errorf("process %s before port %d was ready", reason, p.Port)
+			}
+		}
+		if matched {
+			if addr, ok := DialPort(p.Host, p.Port); ok && p.ownsPort() {
+				p.mu.Lock()
+				p.addr = addr
+				p.mu.Unlock()
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			if !matched {
+				return &ReadyError{Pattern: p.ReadyWhen.String(), Timeout: timeout}
+			}
+			return fmt.Errorf("port %d not ready after %s", p.Port, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ErrStartCancelled
+		case <-ticker.C:
+		}
+	}
+}
+
+// tailLines returns the last n lines of a log, with escape sequences
+// removed, or nil if it is empty.
+func tailLines(path string, n int) []string {
+	tail := tailFile(path, n)
+	if tail == "" {
+		return nil
+	}
+	lines := strings.Split(tail, "\n")
+	for i, line := range lines {
+		lines[i] = stripANSI(line)
+	}
+	return lines
+}
+
+// stripANSI removes terminal escape sequences and carriage returns from
+// a line of output.
+func stripANSI(s string) string {
+	var b bytes.Buffer
+	newANSIStripper(&b).Write([]byte(s))
+	return strings.TrimRight(b.String(), "\r")
+}
+
+// ReadyLine returns the output line that matched the ready pattern when
+// the process started, if it has one.
+func (p *Process) ReadyLine() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.readyLine
+}
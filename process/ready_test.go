@@ -0,0 +1,149 @@
+package process_test
+
+import (
+	"github.com/jaiir320/devserve/process"
+	"github.com/jaiir320/devserve/testutil"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestProcessStartReadyWhenLogMatches(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.ReadyWhen = regexp.MustCompile(`compiled .* in \d+ms`)
+
+	cmd := fmt.Sprintf(`echo starting; sleep 0.3; echo "compiled client in 42ms" >&2; nc -l %d`, port)
+	if err := p.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	if got := p.ReadyLine(); got != "compiled client in 42ms" {
+		t.Errorf("expected the matching line, got %q", got)
+	}
+}
+
+func TestProcessStartReadyWhenLogMatchesExit(t *testing.T) {
+	swapTunnel(t)
+
+	p, err := process.CreateProcess("testapp", testutil.FreePort(t), t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.ReadyWhen = regexp.MustCompile(`ready`)
+
+	err = p.Start("echo building; echo 'syntax error' >&2; exit 3")
+	var readyErr *process.ReadyError
+	if !errors.As(err, &readyErr) {
+		t.Fatalf("expected a ReadyError, got %v", err)
+	}
+	if readyErr.Exit != "exited with status 3" {
+		t.Errorf("expected the exit status, got %q", readyErr.Exit)
+	}
+	var startErr *process.StartError
+	if !errors.As(err, &startErr) {
+		t.Fatalf("expected a StartError, got %v", err)
+	}
+	if len(startErr.Stdout) != 1 || startErr.Stdout[0] != "building" {
+		t.Errorf("expected the last stdout lines, got %q", startErr.Stdout)
+	}
+	if len(startErr.Stderr) != 1 || startErr.Stderr[0] != "syntax error" {
+		t.Errorf("expected the last stderr lines, got %q", startErr.Stderr)
+	}
+}
+
+func TestReadyErrorMessage(t *testing.T) {
+	cases := []struct {
+		err  *process.ReadyError
+		want string
+	}{
+		{&process.ReadyError{Pattern: "Listening on", Timeout: 15 * time.Second}, `no output matched "Listening on" after 15s`},
+		{&process.ReadyError{Pattern: "ready", Exit: "exited with status 1"}, `process exited with status 1 before printing a line matching "ready"`},
+	}
+	for _, c := range cases {
+		if got := c.err.Error(); got != c.want {
+			t.Errorf("expected %q, got %q", c.want, got)
+		}
+	}
+}
+
+func TestProcessStartContextCancel(t *testing.T) {
+	swapTunnel(t)
+
+	p, err := process.CreateProcess("testapp", testutil.FreePort(t), t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(300*time.Millisecond, cancel)
+	begin := time.Now()
+	err = p.StartContext(ctx, "echo compiling; sleep 30")
+	if !errors.Is(err, process.ErrStartCancelled) {
+		t.Fatalf("expected ErrStartCancelled, got %v", err)
+	}
+	if time.Since(begin) > 2*time.Second {
+		t.Errorf("cancelled start took %s", time.Since(begin))
+	}
+	if got := p.StartProgress().Line; got != "compiling" {
+		t.Errorf("expected the last output line, got %q", got)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if exited, _ := p.Exited(); exited {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("process still running after the start was cancelled")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestProcessStartTimeout(t *testing.T) {
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.StartTimeout = 300 * time.Millisecond
+
+	err = p.Start("sleep 30")
+	want := fmt.Sprintf("port %d not ready after 300ms", port)
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+}
+
+func TestProcessStartExitsBeforePort(t *testing.T) {
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+
+	begin := time.Now()
+	err = p.Start("exit 2")
+	want := fmt.Sprintf("process exited with status 2 before port %d was ready", port)
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+	if time.Since(begin) > 2*time.Second {
+		t.Errorf("expected the start to fail as soon as the process exited, took %s", time.Since(begin))
+	}
+}
@@ -0,0 +1,46 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"syscall"
+)
+
+// Signal delivers sig to the main process or, with group set, to its
+// whole tree: the process group, cgroup and tracked descendants.
+func (p *Process) Signal(sig syscall.Signal, group bool) error {
+	p.mu.Lock()
+	running := p.started && !p.stopped
+	p.mu.Unlock()
+	if !running {
+		return fmt.Errorf("process '%s' is not running", p.Name)
+	}
+	if exited, _ := p.Exited(); exited {
+		return fmt.Errorf("process '%s' has exited", p.Name)
+	}
+
+	pid := p.Cmd.Process.Pid
+	if group {
+		p.trackTree()
+		if err := p.signal(sig); err != nil {
+			return fmt.Errorf("failed to send %s to process group %d: %w", signalName(sig), pid, err)
+		}
+		log.Printf("sent %s to '%s' (process group %d)", signalName(sig), p.Name, pid)
+		return nil
+	}
+
+	if err := syscall.Kill(pid, sig); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("process '%s' has exited", p.Name)
+		}
+		return fmt.Errorf("failed to send %s to pid %d: %w", signalName(sig), pid, err)
+	}
+	log.Printf("sent %s to '%s' (pid %d)", signalName(sig), p.Name, pid)
+	return nil
+}
+
+// SignalName returns the conventional name of sig, e.g. "SIGHUP".
+func SignalName(sig syscall.Signal) string {
+	return signalName(sig)
+}
@@ -0,0 +1,85 @@
+package process_test
+
+import (
+	"github.com/jaiir320/devserve/process"
+	"github.com/jaiir320/devserve/testutil"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// startTrapping starts a process whose shell and background child both
+// record SIGHUP in marker files under dir.
+func startTrapping(t *testing.T, dir string) *process.Process {
+	t.Helper()
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	cmd := fmt.Sprintf(`sh -c 'trap "touch child" HUP; while :; do sleep 0.1; done' & trap "touch parent" HUP; nc -l %d; while :; do sleep 0.1; done`, port)
+	if err := p.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { p.Stop() })
+	return p
+}
+
+func waitForFile(path string) bool {
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return false
+}
+
+func TestProcessSignal(t *testing.T) {
+	dir := t.TempDir()
+	p := startTrapping(t, dir)
+
+	if err := p.Signal(syscall.SIGHUP, false); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	if !waitForFile(filepath.Join(dir, "parent")) {
+		t.Fatal("main process did not receive SIGHUP")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(dir, "child")); err == nil {
+		t.Error("child received SIGHUP sent to the main process only")
+	}
+}
+
+func TestProcessSignalGroup(t *testing.T) {
+	dir := t.TempDir()
+	p := startTrapping(t, dir)
+
+	if err := p.Signal(syscall.SIGHUP, true); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	for _, name := range []string{"parent", "child"} {
+		if !waitForFile(filepath.Join(dir, name)) {
+			t.Errorf("%s did not receive SIGHUP sent to the group", name)
+		}
+	}
+}
+
+func TestProcessSignalNotRunning(t *testing.T) {
+	p, err := process.CreateProcess("testapp", 3000, t.TempDir(), "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	err = p.Signal(syscall.SIGHUP, false)
+	if err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("expected not running error, got %v", err)
+	}
+}
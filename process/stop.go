@@ -0,0 +1,104 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/reaper"
+	"context"
+	"fmt"
+	"log"
+	"syscall"
+	"time"
+)
+
+// StopPolicy controls how a process is stopped. Zero values mean the
+// defaults: SIGTERM, config.StopGracePeriod and no pre-stop command.
+type StopPolicy struct {
+	Signal      syscall.Signal
+	GracePeriod time.Duration // before escalating to SIGKILL
+	PreStop     string        // shell command run before the stop signal
+}
+
+// ParseStopPolicy parses the stop settings of a process config. signal
+// takes a name with or without the SIG prefix ("SIGINT", "quit") or a
+// number; timeout takes a Go duration like "10s".
+func ParseStopPolicy(signal, timeout, preStop string) (StopPolicy, error) {
+	s := StopPolicy{PreStop: preStop}
+	if signal != "" {
+		sig, err := config.ParseSignal(signal)
+		if err != nil {
+			return StopPolicy{}, fmt.Errorf("invalid stop_signal %q: %w", signal, err)
+		}
+		s.Signal = sig
+	}
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return StopPolicy{}, fmt.Errorf("invalid stop_timeout %q: expected a duration like 10s", timeout)
+		}
+		s.GracePeriod = d
+	}
+	return s, nil
+}
+
+// Spec formats the policy back into config form, the inverse of
+// ParseStopPolicy.
+func (s StopPolicy) Spec() (signal, timeout, preStop string) {
+	if s.Signal != 0 {
+		signal = signalName(s.Signal)
+	}
+	if s.GracePeriod > 0 {
+		timeout = s.GracePeriod.String()
+	}
+	return signal, timeout, s.PreStop
+}
+
+// signal returns the stop signal, defaulting to SIGTERM.
+func (s StopPolicy) signal() syscall.Signal {
+	if s.Signal == 0 {
+		return syscall.SIGTERM
+	}
+	return s.Signal
+}
+
+// gracePeriod returns the grace period, defaulting to config.StopGracePeriod.
+func (s StopPolicy) gracePeriod() time.Duration {
+	if s.GracePeriod == 0 {
+		return config.StopGracePeriod
+	}
+	return s.GracePeriod
+}
+
+// MaxDuration is the longest Stop can take before escalating to SIGKILL.
+func (s StopPolicy) MaxDuration() time.Duration {
+	d := s.gracePeriod()
+	if s.PreStop != "" {
+		d += config.PreStopTimeout
+	}
+	return d
+}
+
+// runPreStop runs the pre-stop command in the process's directory with
+// its output appended to the process logs. Failures are logged and do not
+// prevent the stop.
+func (p *Process) runPreStop() {
+	if p.StopPolicy.PreStop == "" {
+		return
+	}
+	if exited, _ := p.Exited(); exited {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.PreStopTimeout)
+	defer cancel()
+
+	cmd := p.hookCommand(ctx, p.StopPolicy.PreStop)
+	cmd.Stdout = p.Stdout
+	cmd.Stderr = p.Stderr
+
+	log.Printf("running pre-stop command for %s", p.Name)
+	if err := reaper.Run(cmd); err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s", config.PreStopTimeout)
+		}
+		log.Printf("pre-stop command for %s failed: %s", p.Name, err)
+	}
+}
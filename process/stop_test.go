@@ -0,0 +1,165 @@
+package process_test
+
+import (
+	"github.com/jaiir320/devserve/process"
+	"github.com/jaiir320/devserve/reaper"
+	"github.com/jaiir320/devserve/testutil"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParseStopPolicy(t *testing.T) {
+	cases := []struct {
+		signal string
+		want   syscall.Signal
+	}{
+		{"SIGINT", syscall.SIGINT},
+		{"quit", syscall.SIGQUIT},
+		{"HUP", syscall.SIGHUP},
+		{"15", syscall.SIGTERM},
+	}
+	for _, c := range cases {
+		s, err := process.ParseStopPolicy(c.signal, "", "")
+		if err != nil {
+			t.Errorf("ParseStopPolicy(%q) failed: %v", c.signal, err)
+			continue
+		}
+		if s.Signal != c.want {
+			t.Errorf("ParseStopPolicy(%q): expected %v, got %v", c.signal, c.want, s.Signal)
+		}
+	}
+
+	s, err := process.ParseStopPolicy("", "1m30s", "make drain")
+	if err != nil {
+		t.Fatalf("ParseStopPolicy failed: %v", err)
+	}
+	if s.GracePeriod != 90*time.Second || s.PreStop != "make drain" {
+		t.Errorf("unexpected policy: %+v", s)
+	}
+}
+
+func TestParseStopPolicyInvalid(t *testing.T) {
+	cases := []struct {
+		signal, timeout, want string
+	}{
+		{"SIGNOPE", "", "invalid stop_signal"},
+		{"99", "", "invalid stop_signal"},
+		{"", "10", "invalid stop_timeout"},
+		{"", "-5s", "invalid stop_timeout"},
+	}
+	for _, c := range cases {
+		_, err := process.ParseStopPolicy(c.signal, c.timeout, "")
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("ParseStopPolicy(%q, %q): expected %q error, got %v", c.signal, c.timeout, c.want, err)
+		}
+	}
+}
+
+func TestStopPolicySpecRoundTrip(t *testing.T) {
+	s, err := process.ParseStopPolicy("int", "10s", "echo bye")
+	if err != nil {
+		t.Fatalf("ParseStopPolicy failed: %v", err)
+	}
+	signal, timeout, preStop := s.Spec()
+	if signal != "SIGINT" || timeout != "10s" || preStop != "echo bye" {
+		t.Errorf("unexpected spec: %q %q %q", signal, timeout, preStop)
+	}
+	if empty, _, _ := (process.StopPolicy{}).Spec(); empty != "" {
+		t.Errorf("expected empty signal for default policy, got %q", empty)
+	}
+}
+
+func TestProcessStopUsesPolicy(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+
+	port := testutil.FreePort(t)
+	dir := t.TempDir()
+	p, err := process.CreateProcess("testapp", port, dir, "echo test")
+	if err != nil {
+		t.Fatalf("CreateProcess failed: %v", err)
+	}
+	p.StopPolicy, err = process.ParseStopPolicy("SIGINT", "10s", "echo drained > pre_stop.txt")
+	if err != nil {
+		t.Fatalf("ParseStopPolicy failed: %v", err)
+	}
+
+	// SIGTERM would kill the shell without running the trap.
+	cmd := fmt.Sprintf(`trap 'echo interrupted > signal.txt; exit 0' INT; nc -l %d; while :; do sleep 0.1; done`, port)
+	if err := p.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	start := time.Now()
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected stop signal to end the process quickly, took %s", elapsed)
+	}
+
+	for _, name := range []string{"pre_stop.txt", "signal.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be written: %v", name, err)
+		}
+	}
+}
+
+func TestPreStopWithReaper(t *testing.T) {
+	testutil.RequireNC(t)
+	swapTunnel(t)
+	if err := process.BecomeSubreaper(); err != nil {
+		t.Skipf("cannot become a child subreaper: %v", err)
+	}
+	t.Cleanup(func() { unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 0, 0, 0, 0) })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reaper.Watch(ctx)
+	// Keep the reaper scanning, as a busy daemon's would be.
+	go func() {
+		for ctx.Err() == nil {
+			syscall.Kill(os.Getpid(), syscall.SIGCHLD)
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// The daemon reaps orphans as children exit; each pre-stop command
+	// must still run and report its own exit status to the stop.
+	dir := t.TempDir()
+	const stops = 10
+	for range stops {
+		port := testutil.FreePort(t)
+		p, err := process.CreateProcess("testapp", port, dir, "echo test")
+		if err != nil {
+			t.Fatalf("CreateProcess failed: %v", err)
+		}
+		p.StopPolicy.PreStop = "echo drained >> pre_stop.txt"
+		if err := p.Start(fmt.Sprintf("nc -l %d; sleep 30", port)); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		if err := p.Stop(); err != nil {
+			t.Fatalf("Stop failed: %v", err)
+		}
+	}
+	if strings.Contains(logs.String(), "pre-stop command for testapp failed") {
+		t.Errorf("pre-stop command reported as failed:\n%s", logs.String())
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "pre_stop.txt"))
+	if err != nil || strings.Count(string(data), "drained") != stops {
+		t.Errorf("expected %d pre-stop runs, got %q, %v", stops, data, err)
+	}
+}
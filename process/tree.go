@@ -0,0 +1,197 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/reaper"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Survivor is a process that was still alive after Stop finished.
+type Survivor struct {
+	Pid     int
+	Command string
+}
+
+// StopError reports that a stopped process left something behind: members
+// of its process tree that could not be killed, or a port still bound.
+type StopError struct {
+	Name      string
+	Port      int
+	Survivors []Survivor
+	PortBound bool
+}
+
+func (e *StopError) Error() string {
+	var parts []string
+	if len(e.Survivors) > 0 {
+		pids := make([]string, len(e.Survivors))
+		for i, s := range e.Survivors {
+			pids[i] = fmt.Sprintf("pid %d (%s)", s.Pid, s.Command)
+		}
+		parts = append(parts, fmt.Sprintf("%d process(es) survived: %s", len(e.Survivors), strings.Join(pids, ", ")))
+	}
+	if e.PortBound {
+		parts = append(parts, fmt.Sprintf("port %d is still in use", e.Port))
+	}
+	return fmt.Sprintf("process '%s' stopped but %s", e.Name, strings.Join(parts, "; "))
+}
+
+// BecomeSubreaper marks the calling process as a child subreaper, so
+// descendants whose parent exits (daemonizing servers, double forks) are
+// reparented to it instead of init and can still be reaped and killed.
+// The caller must run reaper.Watch so those that exit on their own do not
+// stay zombies.
+func BecomeSubreaper() error {
+	return unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0)
+}
+
+// snapshotProcs reads the stat of every process in root.
+func snapshotProcs(root string) (map[int]procStat, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	procs := make(map[int]procStat, len(entries))
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		// Processes can exit between ReadDir and reading their stat.
+		if st, err := readStat(root, pid); err == nil {
+			procs[pid] = st
+		}
+	}
+	return procs, nil
+}
+
+// descendantsOf returns every transitive child of pid in procs.
+func descendantsOf(procs map[int]procStat, pid int) []int {
+	children := make(map[int][]int)
+	for child, st := range procs {
+		children[st.ppid] = append(children[st.ppid], child)
+	}
+
+	var out []int
+	queue := []int{pid}
+	seen := map[int]bool{pid: true}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, child := range children[next] {
+			if !seen[child] {
+				seen[child] = true
+				out = append(out, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return out
+}
+
+// trackTree records the current members of the process tree: descendants
+// of the main process, members of its process group and of its cgroup.
+// Recorded pids stay tracked after they leave the tree (setsid, parent
+// exiting), identified by their start time so reused pids are ignored.
+func (p *Process) trackTree() {
+	procs, err := snapshotProcs(procRoot)
+	if err != nil {
+		return
+	}
+	pid := p.Cmd.Process.Pid
+
+	members := descendantsOf(procs, pid)
+	for other, st := range procs {
+		if st.pgrp == pid && other != pid {
+			members = append(members, other)
+		}
+	}
+	if p.cgroup != "" {
+		cgroupMembers, _ := cgroupPids(p.cgroup)
+		members = append(members, cgroupMembers...)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tracked == nil {
+		p.tracked = make(map[int]uint64)
+	}
+	for _, m := range members {
+		if st, ok := procs[m]; ok && m != pid {
+			p.tracked[m] = st.starttime
+		}
+	}
+}
+
+// liveTracked returns tracked processes that are still running. Tracked
+// zombies that were reparented to us as subreaper are reaped on the way.
+func (p *Process) liveTracked() []int {
+	p.mu.Lock()
+	tracked := make(map[int]uint64, len(p.tracked))
+	for pid, start := range p.tracked {
+		tracked[pid] = start
+	}
+	p.mu.Unlock()
+
+	self := os.Getpid()
+	var alive []int
+	for pid, start := range tracked {
+		st, err := readStat(procRoot, pid)
+		if err != nil || st.starttime != start {
+			continue
+		}
+		if st.state == 'Z' {
+			if st.ppid == self {
+				reaper.Reap(pid)
+			}
+			continue
+		}
+		alive = append(alive, pid)
+	}
+	return alive
+}
+
+// killTree SIGKILLs every tracked process still running and waits up to
+// timeout for them to disappear.
+func (p *Process) killTree(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for {
+		alive := p.liveTracked()
+		if len(alive) == 0 || time.Now().After(deadline) {
+			return
+		}
+		for _, pid := range alive {
+			syscall.Kill(pid, syscall.SIGKILL)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// survivors describes the tracked processes still running.
+func (p *Process) survivors() []Survivor {
+	var out []Survivor
+	for _, pid := range p.liveTracked() {
+		out = append(out, Survivor{Pid: pid, Command: commandLine(procRoot, pid)})
+	}
+	return out
+}
+
+// commandLine returns the command line of pid, falling back to its name.
+func commandLine(root string, pid int) string {
+	data, err := os.ReadFile(filepath.Join(root, strconv.Itoa(pid), "cmdline"))
+	if err == nil && len(data) > 0 {
+		return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+	}
+	comm, err := os.ReadFile(filepath.Join(root, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return "?"
+	}
+	return strings.TrimSpace(string(comm))
+}
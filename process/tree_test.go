@@ -0,0 +1,58 @@
+package process
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestDescendantsOf(t *testing.T) {
+	procs := map[int]procStat{
+		1:  {ppid: 0},
+		10: {ppid: 1},
+		11: {ppid: 10},
+		12: {ppid: 11},
+		13: {ppid: 10},
+		20: {ppid: 1},
+		21: {ppid: 20},
+	}
+
+	got := descendantsOf(procs, 10)
+	sort.Ints(got)
+	if want := []int{11, 12, 13}; !reflect.DeepEqual(got, want) {
+		t.Errorf("descendantsOf(10) = %v, want %v", got, want)
+	}
+	if got := descendantsOf(procs, 12); len(got) != 0 {
+		t.Errorf("expected no descendants of a leaf, got %v", got)
+	}
+}
+
+func TestSnapshotProcs(t *testing.T) {
+	root := t.TempDir()
+	writeFakeProc(t, root, 42, 40, 1, 1, 1, 1, 0)
+	writeFakeProc(t, root, 43, 40, 1, 1, 1, 1, 0)
+
+	procs, err := snapshotProcs(root)
+	if err != nil {
+		t.Fatalf("snapshotProcs failed: %v", err)
+	}
+	if len(procs) != 2 {
+		t.Fatalf("expected 2 processes, got %d", len(procs))
+	}
+	if st := procs[42]; st.state != 'S' || st.starttime != 12345 {
+		t.Errorf("unexpected stat for pid 42: %+v", st)
+	}
+}
+
+func TestStopErrorMessage(t *testing.T) {
+	err := &StopError{
+		Name:      "web",
+		Port:      3000,
+		Survivors: []Survivor{{Pid: 123, Command: "node server.js"}},
+		PortBound: true,
+	}
+	want := "process 'web' stopped but 1 process(es) survived: pid 123 (node server.js); port 3000 is still in use"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
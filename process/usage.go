@@ -0,0 +1,237 @@
+package process
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of utime/stime in /proc/<pid>/stat. It is
+// 100 on every Linux architecture Go supports.
+const clockTicks = 100
+
+// procRoot is the procfs mount point, overridable in tests.
+var procRoot = "/proc"
+
+// Usage is one resource usage sample for a process group.
+type Usage struct {
+	Time       time.Time
+	CPUPercent float64
+	RSSBytes   uint64
+	Threads    int
+	FDs        int
+	Procs      int
+}
+
+// groupTotals holds the raw counters summed over a process group.
+type groupTotals struct {
+	cpuTicks uint64
+	rssBytes uint64
+	threads  int
+	fds      int
+	procs    int
+}
+
+// usageHistory is a fixed-size ring buffer of samples plus the counters
+// needed to compute CPU% between samples.
+type usageHistory struct {
+	mu        sync.Mutex
+	samples   []Usage
+	next      int
+	full      bool
+	lastTicks uint64
+	lastTime  time.Time
+}
+
+func newUsageHistory(size int) *usageHistory {
+	return &usageHistory{samples: make([]Usage, size)}
+}
+
+// add records totals taken at t and returns the resulting sample.
+func (h *usageHistory) add(t time.Time, totals groupTotals) Usage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	u := Usage{
+		Time:     t,
+		RSSBytes: totals.rssBytes,
+		Threads:  totals.threads,
+		FDs:      totals.fds,
+		Procs:    totals.procs,
+	}
+	// Children that exit take their ticks with them, so the total can drop.
+	if !h.lastTime.IsZero() && totals.cpuTicks >= h.lastTicks {
+		elapsed := t.Sub(h.lastTime).Seconds()
+		if elapsed > 0 {
+			cpuSeconds := float64(totals.cpuTicks-h.lastTicks) / clockTicks
+			u.CPUPercent = cpuSeconds / elapsed * 100
+		}
+	}
+	h.lastTicks = totals.cpuTicks
+	h.lastTime = t
+
+	h.samples[h.next] = u
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+	return u
+}
+
+// latest returns the most recent sample, or nil if none were taken.
+func (h *usageHistory) latest() *Usage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full && h.next == 0 {
+		return nil
+	}
+	i := (h.next - 1 + len(h.samples)) % len(h.samples)
+	u := h.samples[i]
+	return &u
+}
+
+// all returns the samples in chronological order.
+func (h *usageHistory) all() []Usage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]Usage(nil), h.samples[:h.next]...)
+	}
+	out := make([]Usage, 0, len(h.samples))
+	out = append(out, h.samples[h.next:]...)
+	return append(out, h.samples[:h.next]...)
+}
+
+// SampleUsage reads /proc for every process in the process group and
+// records a sample in the process's usage history.
+func (p *Process) SampleUsage() (Usage, error) {
+	p.mu.Lock()
+	if !p.started || p.stopped || p.Cmd == nil || p.Cmd.Process == nil {
+		p.mu.Unlock()
+		return Usage{}, fmt.Errorf("process '%s' is not running", p.Name)
+	}
+	if p.usage == nil {
+		p.usage = newUsageHistory(config.UsageHistorySize)
+	}
+	pgid := p.Cmd.Process.Pid
+	h := p.usage
+	p.mu.Unlock()
+
+	// Sampling doubles as the tree walk that remembers descendants, so that
+	// Stop can find them even after they leave the process group.
+	p.trackTree()
+
+	totals, err := readGroupTotals(procRoot, pgid)
+	if err != nil {
+		return Usage{}, err
+	}
+	if totals.procs == 0 {
+		return Usage{}, fmt.Errorf("no processes left in group %d", pgid)
+	}
+	return h.add(time.Now(), totals), nil
+}
+
+// Usage returns the most recent usage sample, or nil if none were taken.
+func (p *Process) Usage() *Usage {
+	p.mu.Lock()
+	h := p.usage
+	p.mu.Unlock()
+	if h == nil {
+		return nil
+	}
+	return h.latest()
+}
+
+// UsageHistory returns the recorded usage samples, oldest first.
+func (p *Process) UsageHistory() []Usage {
+	p.mu.Lock()
+	h := p.usage
+	p.mu.Unlock()
+	if h == nil {
+		return nil
+	}
+	return h.all()
+}
+
+// readGroupTotals sums CPU time, RSS, threads and open file descriptors
+// over every process whose process group is pgid.
+func readGroupTotals(root string, pgid int) (groupTotals, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return groupTotals{}, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+
+	pageSize := uint64(os.Getpagesize())
+	var totals groupTotals
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		st, err := readStat(root, pid)
+		if err != nil || st.pgrp != pgid {
+			// Processes can exit between ReadDir and reading their stat.
+			continue
+		}
+		totals.procs++
+		totals.cpuTicks += st.utime + st.stime
+		totals.threads += st.threads
+		totals.rssBytes += st.rssPages * pageSize
+		if fds, err := os.ReadDir(filepath.Join(root, strconv.Itoa(pid), "fd")); err == nil {
+			totals.fds += len(fds)
+		}
+	}
+	return totals, nil
+}
+
+// procStat holds the fields of /proc/<pid>/stat used by devserve.
+type procStat struct {
+	state     byte
+	ppid      int
+	pgrp      int
+	utime     uint64
+	stime     uint64
+	threads   int
+	starttime uint64
+	rssPages  uint64
+}
+
+// readStat parses /proc/<pid>/stat. The command name may contain spaces
+// and parentheses, so fields are split after its closing parenthesis.
+func readStat(root string, pid int) (procStat, error) {
+	data, err := os.ReadFile(filepath.Join(root, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return procStat{}, err
+	}
+	s := string(data)
+	end := strings.LastIndexByte(s, ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	// fields[0] is field 3 (state) in proc(5) numbering.
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+
+	st := procStat{state: fields[0][0]}
+	var errs [7]error
+	st.ppid, errs[0] = strconv.Atoi(fields[1])
+	st.pgrp, errs[1] = strconv.Atoi(fields[2])
+	st.utime, errs[2] = strconv.ParseUint(fields[11], 10, 64)
+	st.stime, errs[3] = strconv.ParseUint(fields[12], 10, 64)
+	st.threads, errs[4] = strconv.Atoi(fields[17])
+	st.starttime, errs[5] = strconv.ParseUint(fields[19], 10, 64)
+	st.rssPages, errs[6] = strconv.ParseUint(fields[21], 10, 64)
+	for _, err := range errs {
+		if err != nil {
+			return procStat{}, fmt.Errorf("malformed stat for pid %d: %w", pid, err)
+		}
+	}
+	return st, nil
+}
@@ -0,0 +1,156 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeFakeProc creates <root>/<pid>/stat and n entries under <root>/<pid>/fd.
+func writeFakeProc(t *testing.T, root string, pid, pgrp int, utime, stime uint64, threads int, rssPages uint64, fds int) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0755); err != nil {
+		t.Fatalf("failed to create fake proc dir: %v", err)
+	}
+	// Fields 3..24 of proc(5); the command name contains a space and a
+	// parenthesis to exercise the parser.
+	stat := fmt.Sprintf("%d (node (dev) S 1 %d %d 0 -1 4194560 100 0 0 0 %d %d 0 0 20 0 %d 0 12345 1000000 %d\n",
+		pid, pgrp, pgrp, utime, stime, threads, rssPages)
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644); err != nil {
+		t.Fatalf("failed to write fake stat: %v", err)
+	}
+	for i := 0; i < fds; i++ {
+		if err := os.WriteFile(filepath.Join(dir, "fd", strconv.Itoa(i)), nil, 0644); err != nil {
+			t.Fatalf("failed to write fake fd: %v", err)
+		}
+	}
+}
+
+func TestReadStat(t *testing.T) {
+	root := t.TempDir()
+	writeFakeProc(t, root, 42, 40, 150, 50, 7, 1000, 0)
+
+	st, err := readStat(root, 42)
+	if err != nil {
+		t.Fatalf("readStat failed: %v", err)
+	}
+	if st.ppid != 1 || st.pgrp != 40 {
+		t.Errorf("expected ppid 1 pgrp 40, got ppid %d pgrp %d", st.ppid, st.pgrp)
+	}
+	if st.utime != 150 || st.stime != 50 {
+		t.Errorf("expected utime 150 stime 50, got %d %d", st.utime, st.stime)
+	}
+	if st.threads != 7 {
+		t.Errorf("expected 7 threads, got %d", st.threads)
+	}
+	if st.rssPages != 1000 {
+		t.Errorf("expected 1000 rss pages, got %d", st.rssPages)
+	}
+}
+
+func TestReadGroupTotals(t *testing.T) {
+	root := t.TempDir()
+	writeFakeProc(t, root, 100, 100, 10, 5, 2, 10, 3)
+	writeFakeProc(t, root, 101, 100, 20, 5, 4, 20, 2)
+	writeFakeProc(t, root, 200, 200, 999, 999, 9, 999, 9) // other group
+	os.MkdirAll(filepath.Join(root, "self"), 0755)        // non-pid entries are skipped
+
+	totals, err := readGroupTotals(root, 100)
+	if err != nil {
+		t.Fatalf("readGroupTotals failed: %v", err)
+	}
+	if totals.procs != 2 {
+		t.Errorf("expected 2 processes, got %d", totals.procs)
+	}
+	if totals.cpuTicks != 40 {
+		t.Errorf("expected 40 cpu ticks, got %d", totals.cpuTicks)
+	}
+	if totals.threads != 6 {
+		t.Errorf("expected 6 threads, got %d", totals.threads)
+	}
+	if totals.fds != 5 {
+		t.Errorf("expected 5 fds, got %d", totals.fds)
+	}
+	if want := uint64(30 * os.Getpagesize()); totals.rssBytes != want {
+		t.Errorf("expected %d rss bytes, got %d", want, totals.rssBytes)
+	}
+}
+
+func TestUsageHistoryCPUPercent(t *testing.T) {
+	h := newUsageHistory(4)
+	start := time.Now()
+
+	first := h.add(start, groupTotals{cpuTicks: 100})
+	if first.CPUPercent != 0 {
+		t.Errorf("expected 0%% CPU for first sample, got %f", first.CPUPercent)
+	}
+
+	// 50 ticks (0.5s of CPU) over 1s of wall time is 50%.
+	second := h.add(start.Add(time.Second), groupTotals{cpuTicks: 150})
+	if second.CPUPercent != 50 {
+		t.Errorf("expected 50%% CPU, got %f", second.CPUPercent)
+	}
+
+	// A drop in ticks (a child exited) must not produce a negative value.
+	third := h.add(start.Add(2*time.Second), groupTotals{cpuTicks: 20})
+	if third.CPUPercent != 0 {
+		t.Errorf("expected 0%% CPU after tick drop, got %f", third.CPUPercent)
+	}
+}
+
+func TestUsageHistoryWraps(t *testing.T) {
+	h := newUsageHistory(3)
+	if h.latest() != nil {
+		t.Fatal("expected nil latest for empty history")
+	}
+
+	start := time.Now()
+	for i := 1; i <= 5; i++ {
+		h.add(start.Add(time.Duration(i)*time.Second), groupTotals{threads: i})
+	}
+
+	all := h.all()
+	if len(all) != 3 {
+		t.Fatalf("expected 3 samples, got %d", len(all))
+	}
+	for i, want := range []int{3, 4, 5} {
+		if all[i].Threads != want {
+			t.Errorf("sample %d: expected threads %d, got %d", i, want, all[i].Threads)
+		}
+	}
+	if latest := h.latest(); latest == nil || latest.Threads != 5 {
+		t.Errorf("expected latest threads 5, got %+v", latest)
+	}
+}
+
+func TestSampleUsageNotStarted(t *testing.T) {
+	p := &Process{Name: "app"}
+	if _, err := p.SampleUsage(); err == nil {
+		t.Fatal("expected error sampling a process that was never started")
+	}
+	if p.Usage() != nil {
+		t.Error("expected nil usage before any sample")
+	}
+}
+
+func TestReadGroupTotalsLive(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("procfs not available")
+	}
+
+	totals, err := readGroupTotals("/proc", syscall.Getpgrp())
+	if err != nil {
+		t.Fatalf("readGroupTotals failed: %v", err)
+	}
+	if totals.procs < 1 {
+		t.Errorf("expected the test process group to have at least 1 process, got %d", totals.procs)
+	}
+	if totals.rssBytes == 0 {
+		t.Error("expected non-zero RSS for the test process group")
+	}
+}
@@ -2,8 +2,10 @@ package protocol
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"time"
 )
 
 type Request struct {
@@ -15,6 +17,116 @@ type Response struct {
 	OK    bool   `json:"ok"`
 	Data  string `json:"data,omitempty"`
 	Error string `json:"error,omitempty"`
+	Kind  string `json:"kind,omitempty"`
+	// Progress marks an interim response sent before the final one.
+	Progress *Progress `json:"progress,omitempty"`
+	// Diagnostics explains a failed start.
+	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
+}
+
+// Progress reports on a request that takes a while, for requests that
+// ask for it with a "progress" argument.
+type Progress struct {
+	Elapsed float64 `json:"elapsed"`         // seconds since the request was received
+	Stage   string  `json:"stage,omitempty"` // what the daemon is waiting for
+	Line    string  `json:"line,omitempty"`  // the last line of process output
+}
+
+// Error kinds carried in Response.Kind so clients can tell failures apart
+// without matching on message text.
+const (
+	KindNotFound = "not_found"
+	KindConflict = "conflict"
+	KindRunning  = "running" // a conflict with a process of the same name
+	KindInvalid  = "invalid"
+)
+
+// Sentinel errors matching each error kind. Use WithKind to tag an error
+// without changing its message.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+	ErrInvalid  = errors.New("invalid argument")
+	// ErrRunning is the conflict of serving a name that is already
+	// running, which is also an ErrConflict.
+	ErrRunning = fmt.Errorf("already running: %w", ErrConflict)
+)
+
+// kindError tags an error with one of the sentinel kinds.
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string   { return e.err.Error() }
+func (e *kindError) Unwrap() []error { return []error{e.err, e.kind} }
+
+// WithKind returns err tagged so that errors.Is(err, kind) reports true.
+// The message is left unchanged.
+func WithKind(err error, kind error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{err: err, kind: kind}
+}
+
+// ErrorKind returns the Response.Kind value for err, or "" if err is untagged.
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return KindNotFound
+	case errors.Is(err, ErrRunning):
+		return KindRunning
+	case errors.Is(err, ErrConflict):
+		return KindConflict
+	case errors.Is(err, ErrInvalid):
+		return KindInvalid
+	}
+	return ""
+}
+
+// Diagnostics describes why a process failed to start.
+type Diagnostics struct {
+	Exit      string   `json:"exit,omitempty"` // how the process exited, if it had
+	PortInUse bool     `json:"port_in_use,omitempty"`
+	PortOwner string   `json:"port_owner,omitempty"` // who has the port, when known
+	Command   string   `json:"command"`              // the argv run, shell included
+	Dir       string   `json:"dir"`
+	Env       []string `json:"env,omitempty"` // NAME=value of the variables that matter most
+	Stdout    []string `json:"stdout,omitempty"`
+	Stderr    []string `json:"stderr,omitempty"`
+}
+
+// DiagnosticsError is an error that comes with start diagnostics, carried
+// in Response.Diagnostics.
+type DiagnosticsError struct {
+	Err         error
+	Diagnostics Diagnostics
+}
+
+func (e *DiagnosticsError) Error() string { return e.Err.Error() }
+func (e *DiagnosticsError) Unwrap() error { return e.Err }
+
+// Err returns the response's error, tagged with its kind, or nil if OK.
+func (r *Response) Err() error {
+	if r.OK {
+		return nil
+	}
+	err := errors.New(r.Error)
+	switch r.Kind {
+	case KindNotFound:
+		err = WithKind(err, ErrNotFound)
+	case KindConflict:
+		err = WithKind(err, ErrConflict)
+	case KindRunning:
+		err = WithKind(err, ErrRunning)
+	case KindInvalid:
+		err = WithKind(err, ErrInvalid)
+	}
+	if r.Diagnostics != nil {
+		return &DiagnosticsError{Err: err, Diagnostics: *r.Diagnostics}
+	}
+	return err
 }
 
 // Result types used across daemon and client
@@ -23,6 +135,8 @@ type ServeResult struct {
 	Port     int    `json:"port"`
 	Hostname string `json:"hostname"`
 	IP       string `json:"ip"`
+	// ReadyLine is the output line that matched ready_when_log_matches.
+	ReadyLine string `json:"ready_line,omitempty"`
 }
 
 type ListResult struct {
@@ -32,17 +146,72 @@ type ListResult struct {
 }
 
 type ListEntry struct {
-	Name    string `json:"name"`
-	Port    int    `json:"port"`
-	Command string `json:"command,omitempty"`
-	Dir     string `json:"dir,omitempty"`
+	Name       string    `json:"name"`
+	Port       int       `json:"port"`
+	Command    string    `json:"command,omitempty"`
+	Dir        string    `json:"dir,omitempty"`
+	ExitReason string    `json:"exit_reason,omitempty"` // set once the process has exited
+	Tags       []string  `json:"tags,omitempty"`
+	Started    time.Time `json:"started,omitzero"`
+	Usage      *Usage    `json:"usage,omitempty"`
+	History    []Usage   `json:"history,omitempty"`
 }
 
 type ProcessInfo struct {
-	Name    string `json:"name"`
-	Port    int    `json:"port"`
+	Name                string            `json:"name"`
+	Port                int               `json:"port"`
+	Command             string            `json:"command"`
+	Dir                 string            `json:"dir"`
+	Args                []string          `json:"args,omitempty"` // set when run without a shell; Command is then for display
+	Shell               string            `json:"shell,omitempty"`
+	TTY                 bool              `json:"tty,omitempty"`
+	StripANSI           bool              `json:"strip_ansi,omitempty"`
+	MemoryMax           string            `json:"memory_max,omitempty"`
+	CPUQuota            string            `json:"cpu_quota,omitempty"`
+	PidsMax             int               `json:"pids_max,omitempty"`
+	StopSignal          string            `json:"stop_signal,omitempty"`
+	StopTimeout         string            `json:"stop_timeout,omitempty"`
+	PreStop             string            `json:"pre_stop,omitempty"`
+	PreStart            string            `json:"pre_start,omitempty"`
+	PostReady           string            `json:"post_ready,omitempty"`
+	PostStop            string            `json:"post_stop,omitempty"`
+	ReadyWhenLogMatches string            `json:"ready_when_log_matches,omitempty"`
+	StartTimeout        string            `json:"start_timeout,omitempty"`
+	Host                string            `json:"host,omitempty"`
+	Address             string            `json:"address,omitempty"` // the host:port that accepted connections once ready
+	Env                 map[string]string `json:"env,omitempty"`
+	Tags                []string          `json:"tags,omitempty"`
+	Cgroup              string            `json:"cgroup,omitempty"` // empty when limits fall back to rlimits
+	ExitReason          string            `json:"exit_reason,omitempty"`
+	Usage               *Usage            `json:"usage,omitempty"`
+	History             []Usage           `json:"history,omitempty"`
+}
+
+// Usage is a resource usage sample summed over a process's whole
+// process group.
+type Usage struct {
+	Time       time.Time `json:"time"`
+	CPUPercent float64   `json:"cpu_percent"`
+	RSSBytes   uint64    `json:"rss_bytes"`
+	Threads    int       `json:"threads"`
+	FDs        int       `json:"fds"`
+	Procs      int       `json:"procs"`
+}
+
+// PortResult reports who is listening on a port. Owners can be empty
+// while InUse is set when the listener belongs to another user.
+type PortResult struct {
+	Port   int         `json:"port"`
+	InUse  bool        `json:"in_use"`
+	Owners []PortOwner `json:"owners,omitempty"`
+}
+
+type PortOwner struct {
+	Pid     int    `json:"pid"`
 	Command string `json:"command"`
-	Dir     string `json:"dir"`
+	Dir     string `json:"dir,omitempty"`
+	Kind    string `json:"kind"`              // "devserve", "orphan" or "other"
+	Process string `json:"process,omitempty"` // the devserve process it belongs or belonged to
 }
 
 type LogsResult struct {
@@ -50,12 +219,36 @@ type LogsResult struct {
 	Stderr []string `json:"stderr"`
 }
 
+// MessageResult is emitted by commands that only report a status message.
+type MessageResult struct {
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// DaemonLogsResult holds the tail of the daemon log.
+type DaemonLogsResult struct {
+	Lines []string `json:"lines"`
+}
+
+// ErrorResult is emitted on stderr when a command fails in a
+// machine-readable output format.
+type ErrorResult struct {
+	Error       string       `json:"error"`
+	Kind        string       `json:"kind,omitempty"`
+	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
+}
+
 func OkResponse(data string) *Response {
 	return &Response{OK: true, Data: data}
 }
 
 func ErrResponse(err error) *Response {
-	return &Response{OK: false, Error: err.Error()}
+	resp := &Response{OK: false, Error: err.Error(), Kind: ErrorKind(err)}
+	var de *DiagnosticsError
+	if errors.As(err, &de) {
+		resp.Diagnostics = &de.Diagnostics
+	}
+	return resp
 }
 
 func SendRequest(conn net.Conn, req *Request) error {
@@ -81,6 +274,50 @@ func SendResponse(conn net.Conn, resp *Response) error {
 	return nil
 }
 
+// ReadResponseUnbuffered reads a response without reading past its
+// terminating newline, for connections that carry raw data afterwards.
+func ReadResponseUnbuffered(conn net.Conn) (*Response, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		if _, err := conn.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if b[0] == '\n' {
+			break
+		}
+		line = append(line, b[0])
+	}
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &resp, nil
+}
+
+// SendProgress sends an interim progress response.
+func SendProgress(conn net.Conn, progress Progress) error {
+	return SendResponse(conn, &Response{OK: true, Progress: &progress})
+}
+
+// ReadFinalResponse reads responses until the final one, passing any
+// progress sent before it to progress.
+func ReadFinalResponse(conn net.Conn, progress func(Progress)) (*Response, error) {
+	dec := json.NewDecoder(conn)
+	for {
+		var resp Response
+		if err := dec.Decode(&resp); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if resp.Progress == nil {
+			return &resp, nil
+		}
+		if progress != nil {
+			progress(*resp.Progress)
+		}
+	}
+}
+
 func ReadResponse(conn net.Conn) (*Response, error) {
 	var resp Response
 	err := json.NewDecoder(conn).Decode(&resp)
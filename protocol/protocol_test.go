@@ -1,6 +1,7 @@
 package protocol
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"testing"
@@ -91,3 +92,91 @@ func TestOmitempty(t *testing.T) {
 		t.Error("expected Data to be empty (omitted when empty)")
 	}
 }
+
+func TestErrResponseKind(t *testing.T) {
+	err := WithKind(fmt.Errorf("process 'web' not found"), ErrNotFound)
+
+	resp := ErrResponse(err)
+	if resp.Error != "process 'web' not found" {
+		t.Errorf("expected message to be unchanged, got %q", resp.Error)
+	}
+	if resp.Kind != KindNotFound {
+		t.Errorf("expected kind %q, got %q", KindNotFound, resp.Kind)
+	}
+
+	// The kind survives the round trip back into an error on the client.
+	got := resp.Err()
+	if !errors.Is(got, ErrNotFound) {
+		t.Errorf("expected errors.Is(err, ErrNotFound) after round trip, got %v", got)
+	}
+	if got.Error() != resp.Error {
+		t.Errorf("expected message %q, got %q", resp.Error, got.Error())
+	}
+
+	if err := OkResponse("pong").Err(); err != nil {
+		t.Errorf("expected nil error for OK response, got %v", err)
+	}
+}
+
+func TestErrResponseRunning(t *testing.T) {
+	got := ErrResponse(WithKind(fmt.Errorf("process 'web' already in use"), ErrRunning)).Err()
+	if !errors.Is(got, ErrRunning) || !errors.Is(got, ErrConflict) {
+		t.Errorf("expected a running conflict after round trip, got %v", got)
+	}
+	if ErrorKind(WithKind(fmt.Errorf("port 3000 is already in use"), ErrConflict)) != KindConflict {
+		t.Error("expected a plain conflict to keep its kind")
+	}
+}
+
+func TestErrResponseUntagged(t *testing.T) {
+	resp := ErrResponse(fmt.Errorf("boom"))
+	if resp.Kind != "" {
+		t.Errorf("expected empty kind for untagged error, got %q", resp.Kind)
+	}
+}
+
+func TestReadFinalResponseProgress(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		SendProgress(server, Progress{Elapsed: 1, Stage: "waiting for port 3000"})
+		SendProgress(server, Progress{Elapsed: 2, Line: "compiling..."})
+		SendResponse(server, OkResponse("done"))
+	}()
+
+	var got []Progress
+	resp, err := ReadFinalResponse(client, func(p Progress) { got = append(got, p) })
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if resp.Data != "done" {
+		t.Errorf("expected the final response, got %+v", resp)
+	}
+	if len(got) != 2 || got[0].Stage != "waiting for port 3000" || got[1].Line != "compiling..." {
+		t.Errorf("expected both progress updates, got %+v", got)
+	}
+}
+
+func TestDiagnosticsRoundTrip(t *testing.T) {
+	diag := Diagnostics{Exit: "exited with status 1", Command: "sh -c 'exec npm run dev'", Dir: "/app", Stderr: []string{"boom"}}
+	err := fmt.Errorf("failed to start process 'web': %w", &DiagnosticsError{Err: errors.New("port 3000 not ready after 15s"), Diagnostics: diag})
+
+	resp := ErrResponse(err)
+	if resp.Diagnostics == nil || resp.Diagnostics.Exit != diag.Exit {
+		t.Fatalf("expected diagnostics in the response, got %+v", resp.Diagnostics)
+	}
+
+	got := resp.Err()
+	var de *DiagnosticsError
+	if !errors.As(got, &de) {
+		t.Fatalf("expected a DiagnosticsError, got %T", got)
+	}
+	if got.Error() != err.Error() {
+		t.Errorf("expected message %q, got %q", err.Error(), got.Error())
+	}
+	if de.Diagnostics.Command != diag.Command || len(de.Diagnostics.Stderr) != 1 {
+		t.Errorf("expected diagnostics to survive the round trip, got %+v", de.Diagnostics)
+	}
+}
@@ -0,0 +1,137 @@
+// Package reaper collects the orphans reparented to the daemon, a child
+// subreaper, without taking the exit status of the children it started
+// with os/exec, which their Cmd.Wait collects.
+//
+// Commands run in the daemon must be started with Start or Run so the
+// reaper knows to leave them alone.
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// mu is held while starting a command and while reaping, so a child
+	// is registered in owned before the reaper can see it.
+	mu    sync.Mutex
+	owned = make(map[int]*exec.Cmd) // children reaped by their Cmd.Wait
+)
+
+// Start starts cmd, registering its pid so the reaper leaves it to Wait.
+func Start(cmd *exec.Cmd) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	owned[cmd.Process.Pid] = cmd
+	return nil
+}
+
+// Wait waits for cmd, started with Start, to exit.
+func Wait(cmd *exec.Cmd) error {
+	err := cmd.Wait()
+	mu.Lock()
+	// The pid may already be reused by a command started since.
+	if owned[cmd.Process.Pid] == cmd {
+		delete(owned, cmd.Process.Pid)
+	}
+	mu.Unlock()
+	return err
+}
+
+// Run is like cmd.Run, for commands run in the daemon.
+func Run(cmd *exec.Cmd) error {
+	if err := Start(cmd); err != nil {
+		return err
+	}
+	return Wait(cmd)
+}
+
+// Output is like cmd.Output, for commands run in the daemon.
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := Run(cmd)
+	return stdout.Bytes(), err
+}
+
+// Watch reaps orphaned children whenever a child exits, until ctx is done.
+func Watch(ctx context.Context) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGCHLD)
+	defer signal.Stop(sigs)
+
+	reapOrphans()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigs:
+			reapOrphans()
+		}
+	}
+}
+
+// Reap collects pid if it is an exited child that no Cmd.Wait owns.
+func Reap(pid int) {
+	mu.Lock()
+	defer mu.Unlock()
+	if owned[pid] == nil {
+		var ws unix.WaitStatus
+		unix.Wait4(pid, &ws, unix.WNOHANG, nil)
+	}
+}
+
+// reapOrphans collects every exited child that no Cmd.Wait owns. Zombies
+// are found in /proc rather than with wait4(-1), which could take the
+// status of an owned child.
+func reapOrphans() {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return
+	}
+	self := os.Getpid()
+	mu.Lock()
+	defer mu.Unlock()
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || owned[pid] != nil {
+			continue
+		}
+		if ppid, state, ok := readStat(pid); ok && ppid == self && state == 'Z' {
+			var ws unix.WaitStatus
+			unix.Wait4(pid, &ws, unix.WNOHANG, nil)
+		}
+	}
+}
+
+// readStat returns the parent and state of pid from /proc/<pid>/stat.
+func readStat(pid int) (ppid int, state byte, ok bool) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, 0, false
+	}
+	// The command name, in parentheses, may contain spaces.
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return 0, 0, false
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 2 || len(fields[0]) != 1 {
+		return 0, 0, false
+	}
+	ppid, err = strconv.Atoi(fields[1])
+	return ppid, fields[0][0], err == nil
+}
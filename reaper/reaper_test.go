@@ -0,0 +1,62 @@
+package reaper
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestWatchReapsOrphans(t *testing.T) {
+	if err := unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0); err != nil {
+		t.Skipf("cannot become a child subreaper: %v", err)
+	}
+	t.Cleanup(func() { unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 0, 0, 0, 0) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go Watch(ctx)
+
+	// The shell exits at once, leaving sleep to be reparented to us.
+	out, err := Output(exec.Command("sh", "-c", "sleep 0.2 & echo $!"))
+	if err != nil {
+		t.Fatalf("Output failed: %v", err)
+	}
+	orphan, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	// Commands started meanwhile keep their exit status for Wait.
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Run(exec.Command("sh", "-c", "exit 3")); err == nil || !strings.Contains(err.Error(), "exit status 3") {
+				t.Errorf("Run = %v, want exit status 3", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := os.Stat(filepath.Join("/proc", strconv.Itoa(orphan)))
+		if os.IsNotExist(err) {
+			return
+		}
+		if time.Now().After(deadline) {
+			ppid, state, _ := readStat(orphan)
+			t.Fatalf("orphan %d not reaped: state %c, parent %d", orphan, state, ppid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -42,8 +42,8 @@ func RequireNC(t *testing.T) {
 // NoopTunnel implements tunnel.Tunnel with no-op Serve and Stop.
 type NoopTunnel struct{}
 
-func (NoopTunnel) Serve(port int) error { return nil }
-func (NoopTunnel) Stop(port int) error  { return nil }
+func (NoopTunnel) Serve(port int, upstream string) error { return nil }
+func (NoopTunnel) Stop(port int) error                   { return nil }
 
 // FailOnceStopTunnel fails the first Stop() call per port, succeeds on retry.
 // Serve() always succeeds.
@@ -55,7 +55,7 @@ func NewFailOnceStopTunnel() *FailOnceStopTunnel {
 	return &FailOnceStopTunnel{failed: make(map[int]bool)}
 }
 
-func (f *FailOnceStopTunnel) Serve(port int) error { return nil }
+func (f *FailOnceStopTunnel) Serve(port int, upstream string) error { return nil }
 func (f *FailOnceStopTunnel) Stop(port int) error {
 	if !f.failed[port] {
 		f.failed[port] = true
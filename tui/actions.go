@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// itemsMsg carries a fresh list of items from the daemon and config.
+type itemsMsg struct {
+	items []listItem
+	err   error
+}
+
+// refreshTickMsg triggers the next periodic refresh.
+type refreshTickMsg struct{}
+
+// actionMsg reports that a background action on the named item finished.
+type actionMsg struct {
+	name   string
+	status string
+	err    error
+}
+
+// loadItems fetches the items in the background.
+func loadItems() tea.Msg {
+	items, err := fetchItems()
+	return itemsMsg{items: items, err: err}
+}
+
+// refreshTick schedules the next refresh, in step with the daemon's
+// usage sampling.
+func refreshTick() tea.Cmd {
+	return tea.Tick(config.UsageSampleInterval, func(time.Time) tea.Msg {
+		return refreshTickMsg{}
+	})
+}
+
+// newSpinner returns the spinner shown next to items with an action in
+// progress.
+func newSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.MiniDot), spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("#F780E2"))))
+}
+
+// runAction runs fn in the background, showing name as pending with verb
+// ("starting", "stopping") until it finishes. fn returns the status
+// message to show, or an error. An item runs one action at a time.
+func (m model) runAction(name, verb string, fn func() (string, error)) (model, tea.Cmd) {
+	if current, ok := m.pending[name]; ok {
+		m.statusMsg = fmt.Sprintf("'%s' is already %s", name, current)
+		m.statusErr = true
+		return m, nil
+	}
+	if m.pending == nil {
+		m.pending = make(map[string]string)
+	}
+	m.pending[name] = verb
+	m.statusMsg = ""
+
+	cmd := func() tea.Msg {
+		status, err := fn()
+		return actionMsg{name: name, status: status, err: err}
+	}
+	if len(m.pending) == 1 {
+		// The spinner stops ticking while nothing is pending.
+		return m, tea.Batch(cmd, m.spinner.Tick)
+	}
+	return m, cmd
+}
+
+// finishAction shows the result of a finished action and refreshes.
+func (m model) finishAction(msg actionMsg) (model, tea.Cmd) {
+	delete(m.pending, msg.name)
+	if msg.err != nil {
+		m.statusMsg = msg.err.Error()
+		m.statusErr = true
+	} else {
+		m.statusMsg = msg.status
+		m.statusErr = false
+	}
+	return m, loadItems
+}
+
+// setItems replaces the items, keeping the cursor on the same process
+// when it is still listed.
+func (m model) setItems(items []listItem) model {
+	m.all = items
+	return m.applyView()
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetItemsKeepsCursorOnName(t *testing.T) {
+	m := model{items: []listItem{{Name: "api"}, {Name: "web"}, {Name: "worker"}}, cursor: 1}
+
+	// web moves to the top when a config before it is removed.
+	m = m.setItems([]listItem{{Name: "web"}, {Name: "worker"}})
+	if m.cursor != 0 {
+		t.Errorf("expected the cursor to follow web to 0, got %d", m.cursor)
+	}
+
+	m = m.setItems([]listItem{{Name: "admin"}, {Name: "api"}, {Name: "web"}})
+	if m.cursor != 2 {
+		t.Errorf("expected the cursor to follow web to 2, got %d", m.cursor)
+	}
+
+	// When the selected process goes, the cursor stays in range.
+	m = m.setItems([]listItem{{Name: "admin"}})
+	if m.cursor != 0 {
+		t.Errorf("expected the cursor clamped to 0, got %d", m.cursor)
+	}
+	m = m.setItems(nil)
+	if m.cursor != 0 {
+		t.Errorf("expected the cursor at 0 with no items, got %d", m.cursor)
+	}
+}
+
+func TestRunActionInBackground(t *testing.T) {
+	m := model{items: []listItem{{Name: "web"}}, spinner: newSpinner()}
+	ran := false
+	m, cmd := m.runAction("web", "starting", func() (string, error) {
+		ran = true
+		return "process 'web' started", nil
+	})
+	if ran {
+		t.Fatal("expected the action not to run inside Update")
+	}
+	if m.pending["web"] != "starting" {
+		t.Fatalf("expected web to be pending, got %v", m.pending)
+	}
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+
+	// A second action on the same item is refused while the first runs.
+	m, again := m.runAction("web", "stopping", func() (string, error) { return "", nil })
+	if again != nil || !m.statusErr || m.pending["web"] != "starting" {
+		t.Errorf("expected the second action to be refused, got status %q", m.statusMsg)
+	}
+
+	m, _ = m.finishAction(actionMsg{name: "web", status: "process 'web' started"})
+	if _, ok := m.pending["web"]; ok || m.statusErr || m.statusMsg != "process 'web' started" {
+		t.Errorf("expected web done with a success status, got %v %q", m.pending, m.statusMsg)
+	}
+
+	m, _ = m.runAction("web", "stopping", func() (string, error) { return "", nil })
+	m, _ = m.finishAction(actionMsg{name: "web", err: errors.New("failed to stop 'web': boom")})
+	if !m.statusErr || m.statusMsg != "failed to stop 'web': boom" {
+		t.Errorf("expected the error in the status line, got %q", m.statusMsg)
+	}
+}
+
+func TestRefreshContinuesUnderForm(t *testing.T) {
+	swapConfigFile(t)
+	m := model{width: 80, items: []listItem{{Name: "web"}}}
+	m = updateAll(m, keyMsg("n"))
+	if m.form == nil {
+		t.Fatal("expected the form to open")
+	}
+
+	next, cmd := m.Update(refreshTickMsg{})
+	if cmd == nil {
+		t.Error("expected the refresh to be scheduled while the form is open")
+	}
+	next, _ = next.(model).Update(itemsMsg{items: []listItem{{Name: "api"}, {Name: "web"}}})
+	m = next.(model)
+	if len(m.items) != 2 || m.form == nil {
+		t.Errorf("expected the items refreshed under the open form, got %d items", len(m.items))
+	}
+}
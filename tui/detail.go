@@ -12,8 +12,14 @@ import (
 var (
 	detailLabel = cli.Dim
 	urlStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+
+	selectedURLStyle = urlStyle.Underline(true)
+	sparkStyle       = cli.Green
 )
 
+// detailSparkWidth is the number of usage samples drawn in the detail pane.
+const detailSparkWidth = 24
+
 // renderRightPane renders the right pane based on the selected item.
 func renderRightPane(m model) string {
 	if len(m.items) == 0 {
@@ -23,8 +29,12 @@ func renderRightPane(m model) string {
 	item := m.items[m.cursor]
 	var b strings.Builder
 
-	// Name header
-	b.WriteString(" " + cli.Bold.Render(item.Name) + "\n")
+	// Name header, with the action in progress
+	b.WriteString(" " + cli.Bold.Render(item.Name))
+	if verb, ok := m.pending[item.Name]; ok {
+		b.WriteString(" " + cli.Dim.Render(verb+"…"))
+	}
+	b.WriteString("\n")
 
 	// Key-value pairs
 	rows := []struct {
@@ -36,25 +46,50 @@ func renderRightPane(m model) string {
 		{"Directory", item.Dir},
 	}
 
-	if item.Running {
+	if len(item.Tags) > 0 {
 		rows = append(rows, struct {
 			label string
 			value string
-		}{"Local", item.LocalURL})
-		if item.IPURL != "" {
-			rows = append(rows, struct {
-				label string
-				value string
-			}{"IP", item.IPURL})
-		}
-		if item.DNSURL != "" {
+		}{"Tags", strings.Join(item.Tags, ", ")})
+	}
+
+	if item.Running {
+		selected := m.selectedURL()
+		for _, u := range []struct{ label, url string }{{"Local", item.LocalURL}, {"IP", item.IPURL}, {"DNS", item.DNSURL}} {
+			if u.url == "" {
+				continue
+			}
+			value := u.url
+			if u.url == selected {
+				// The URL o opens and y copies.
+				value = selectedURLStyle.Render(u.url) + cli.Dim.Render(" ◂")
+			}
 			rows = append(rows, struct {
 				label string
 				value string
-			}{"DNS", item.DNSURL})
+			}{u.label, value})
 		}
 	}
 
+	if item.ExitReason != "" {
+		rows = append(rows, struct {
+			label string
+			value string
+		}{"Exited", item.ExitReason})
+	}
+
+	if item.Running && item.Usage != nil {
+		rows = append(rows, []struct {
+			label string
+			value string
+		}{
+			{"CPU", fmt.Sprintf("%-9s %s", cli.FormatCPU(item.Usage), sparkStyle.Render(cli.Sparkline(cli.CPUHistory(item.History), detailSparkWidth)))},
+			{"Memory", fmt.Sprintf("%-9s %s", cli.FormatMem(item.Usage), sparkStyle.Render(cli.Sparkline(cli.MemHistory(item.History), detailSparkWidth)))},
+			{"Threads", fmt.Sprintf("%d", item.Usage.Threads)},
+			{"FDs", fmt.Sprintf("%d", item.Usage.FDs)},
+		}...)
+	}
+
 	// Add configured status
 	if item.Configured {
 		rows = append(rows, struct {
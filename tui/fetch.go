@@ -6,13 +6,14 @@ import (
 	"github.com/jaiir320/devserve/protocol"
 	"fmt"
 	"sort"
+	"time"
 )
 
 // fetchItems queries the daemon and config to build a unified list of processes.
 // Configured items come first, followed by ephemeral (running but not configured) items.
 func fetchItems() ([]listItem, error) {
 	// Fetch running processes from daemon
-	lr, err := client.List()
+	lr, err := client.ListWithHistory()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list: %w", err)
 	}
@@ -38,7 +39,12 @@ func buildItems(processes []protocol.ListEntry, hostname, ip string, configs []c
 			Command:  e.Command,
 			Dir:      e.Dir,
 			LocalURL: fmt.Sprintf("http://localhost:%d", e.Port),
+			Tags:     e.Tags,
+			Started:  e.Started,
+			Usage:    e.Usage,
+			History:  e.History,
 		}
+		info.ExitReason = e.ExitReason
 		if ip != "" {
 			info.IPURL = fmt.Sprintf("http://%s:%d", ip, e.Port)
 		}
@@ -58,8 +64,9 @@ func buildItems(processes []protocol.ListEntry, hostname, ip string, configs []c
 		item := listItem{
 			Name:       cfg.Name,
 			Port:       cfg.Port,
-			Command:    cfg.Command,
+			Command:    cfg.CommandLine(),
 			Dir:        cfg.Directory,
+			Tags:       cfg.Tags,
 			Configured: true,
 		}
 
@@ -69,6 +76,10 @@ func buildItems(processes []protocol.ListEntry, hostname, ip string, configs []c
 			item.LocalURL = proc.LocalURL
 			item.IPURL = proc.IPURL
 			item.DNSURL = proc.DNSURL
+			item.ExitReason = proc.ExitReason
+			item.Usage = proc.Usage
+			item.History = proc.History
+			item.Started = proc.Started
 			// Update with live command/dir from running process
 			item.Command = proc.Command
 			item.Dir = proc.Dir
@@ -97,6 +108,11 @@ func buildItems(processes []protocol.ListEntry, hostname, ip string, configs []c
 				LocalURL:   proc.LocalURL,
 				IPURL:      proc.IPURL,
 				DNSURL:     proc.DNSURL,
+				ExitReason: proc.ExitReason,
+				Tags:       proc.Tags,
+				Started:    proc.Started,
+				Usage:      proc.Usage,
+				History:    proc.History,
 			})
 		}
 	}
@@ -114,13 +130,18 @@ func buildItems(processes []protocol.ListEntry, hostname, ip string, configs []c
 
 // processInfo holds temporary process data during fetch
 type processInfo struct {
-	Name     string
-	Port     int
-	Command  string
-	Dir      string
-	LocalURL string
-	IPURL    string
-	DNSURL   string
+	Name       string
+	Port       int
+	Command    string
+	Dir        string
+	LocalURL   string
+	IPURL      string
+	DNSURL     string
+	ExitReason string
+	Tags       []string
+	Started    time.Time
+	Usage      *protocol.Usage
+	History    []protocol.Usage
 }
 
 // stopProcess sends a stop request to the daemon for the named process.
@@ -128,21 +149,29 @@ func stopProcess(name string) error {
 	return client.Stop(name)
 }
 
-// startItem starts a configured process.
+func signalProcess(name, signal string, group bool) (string, error) {
+	return client.Signal(name, signal, group)
+}
+
+// startItem starts a configured process from its saved config, so every
+// saved setting and the config file's defaults are applied.
 func startItem(item listItem) error {
-	_, err := client.Serve(item.Name, item.Port, item.Command, item.Dir)
+	cfg, err := config.ResolveConfig(config.ConfigFile, item.Name)
+	if err != nil {
+		return err
+	}
+	_, err = client.Serve(*cfg)
 	return err
 }
 
-// saveToConfig saves an ephemeral process to the config file.
+// saveToConfig saves an ephemeral process to the config file, including
+// settings it was started with.
 func saveToConfig(item listItem) error {
-	newConfig := config.ProcessConfig{
-		Name:      item.Name,
-		Port:      item.Port,
-		Command:   item.Command,
-		Directory: item.Dir,
+	info, err := client.Get(item.Name)
+	if err != nil {
+		return err
 	}
-	return config.SaveConfig(config.ConfigFile, newConfig)
+	return config.SaveProcessInfo(config.ConfigFile, info)
 }
 
 // removeFromConfig removes a process from the config file.
@@ -4,6 +4,7 @@ import (
 	"github.com/jaiir320/devserve/config"
 	"github.com/jaiir320/devserve/protocol"
 	"testing"
+	"time"
 )
 
 func TestBuildItemsEmpty(t *testing.T) {
@@ -182,3 +183,52 @@ func TestBuildItemsNoHostname(t *testing.T) {
 		t.Error("expected DNSURL to be empty when no hostname provided")
 	}
 }
+
+func TestBuildItemsCarriesUsage(t *testing.T) {
+	usage := &protocol.Usage{CPUPercent: 42, RSSBytes: 1 << 20}
+	history := []protocol.Usage{{CPUPercent: 10}, {CPUPercent: 42}}
+	processes := []protocol.ListEntry{
+		{Name: "web", Port: 3000, Usage: usage, History: history},
+		{Name: "api", Port: 4000, Usage: usage, History: history},
+	}
+	configs := []config.ProcessConfig{
+		{Name: "web", Port: 3000, Command: "npm start", Directory: "/projects/web"},
+	}
+
+	items := buildItems(processes, "", "", configs)
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	for _, item := range items {
+		if item.Usage == nil || item.Usage.CPUPercent != 42 {
+			t.Errorf("expected %s to carry usage, got %+v", item.Name, item.Usage)
+		}
+		if len(item.History) != 2 {
+			t.Errorf("expected %s to carry 2 history samples, got %d", item.Name, len(item.History))
+		}
+	}
+}
+
+func TestBuildItemsTagsAndStarted(t *testing.T) {
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	processes := []protocol.ListEntry{
+		{Name: "web", Port: 3000, Started: started, Tags: []string{"old"}},
+		{Name: "tmp", Port: 9000, Started: started, Tags: []string{"scratch"}},
+	}
+	configs := []config.ProcessConfig{
+		{Name: "web", Port: 3000, Tags: []string{"frontend"}},
+	}
+
+	items := buildItems(processes, "", "", configs)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	// Saved tags win over those the process was started with.
+	if len(items[0].Tags) != 1 || items[0].Tags[0] != "frontend" || !items[0].Started.Equal(started) {
+		t.Errorf("unexpected configured item %+v", items[0])
+	}
+	if len(items[1].Tags) != 1 || items[1].Tags[0] != "scratch" || !items[1].Started.Equal(started) {
+		t.Errorf("unexpected ephemeral item %+v", items[1])
+	}
+}
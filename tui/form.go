@@ -0,0 +1,322 @@
+package tui
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// formAction is what a config form does once submitted.
+type formAction int
+
+const (
+	formCreate formAction = iota
+	formEdit
+	formDuplicate
+	formDelete
+)
+
+// configForm is an open config form. The huh fields write to values as
+// they are edited.
+type configForm struct {
+	action  formAction
+	form    *huh.Form
+	base    config.ProcessConfig // the config being edited, copied or deleted
+	others  []config.ProcessConfig
+	values  *formValues
+	confirm *bool // the delete confirmation
+}
+
+// formValues holds the editable fields as the form shows them.
+type formValues struct {
+	name    string
+	port    string
+	command string
+	dir     string
+	env     string // NAME=value per line
+	tags    string // comma separated
+}
+
+// newConfigForm builds the form for action on base, validating against
+// the other saved configs. base is the zero config when creating.
+func newConfigForm(action formAction, base config.ProcessConfig, configs []config.ProcessConfig, width int) *configForm {
+	f := &configForm{action: action, base: base}
+	for _, c := range configs {
+		if action == formEdit && c.Name == base.Name {
+			continue
+		}
+		f.others = append(f.others, c)
+	}
+
+	var title string
+	switch action {
+	case formCreate:
+		title = "New process"
+	case formEdit:
+		title = fmt.Sprintf("Edit '%s'", base.Name)
+	case formDuplicate:
+		title = fmt.Sprintf("Duplicate '%s'", base.Name)
+	case formDelete:
+		confirm := false
+		f.confirm = &confirm
+		f.form = newForm(width, huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Delete the config for '%s'?", base.Name)).
+				Description("A running process keeps running.").
+				Affirmative("Delete").
+				Negative("Cancel").
+				Value(f.confirm),
+		))
+		return f
+	}
+
+	f.values = &formValues{
+		name:    base.Name,
+		command: base.CommandLine(),
+		dir:     base.Directory,
+		env:     formatEnv(base.Env),
+		tags:    strings.Join(base.Tags, ", "),
+	}
+	if base.Port != 0 {
+		f.values.port = strconv.Itoa(base.Port)
+	}
+	if action == formDuplicate {
+		f.values.name = base.Name + "-copy"
+		f.values.port = ""
+	}
+	if f.values.dir == "" {
+		f.values.dir, _ = os.Getwd()
+	}
+
+	f.form = newForm(width, huh.NewGroup(
+		huh.NewInput().Title("Name").Value(&f.values.name).Validate(f.validateName),
+		huh.NewInput().Title("Port").Value(&f.values.port).Validate(f.validatePort),
+		huh.NewInput().Title("Command").Placeholder("npm run dev").Value(&f.values.command).Validate(validateCommand),
+		huh.NewInput().Title("Directory").Value(&f.values.dir).Validate(validateDir),
+		huh.NewInput().Title("Tags").Placeholder("frontend, team-a").Value(&f.values.tags),
+		huh.NewText().Title("Environment").Description("NAME=value, one per line").Lines(4).Value(&f.values.env).Validate(validateEnv),
+	).Title(title))
+	return f
+}
+
+// newForm returns a form for embedding in the TUI: esc cancels it, and
+// submitting or cancelling leaves the program running.
+func newForm(width int, groups ...*huh.Group) *huh.Form {
+	keymap := huh.NewDefaultKeyMap()
+	keymap.Quit = key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "cancel"))
+	return huh.NewForm(groups...).
+		WithKeyMap(keymap).
+		WithShowHelp(true).
+		WithWidth(min(width, 80))
+}
+
+func (f *configForm) validateName(s string) error {
+	name := strings.TrimSpace(s)
+	if err := config.ValidateName(name); err != nil {
+		return err
+	}
+	for _, c := range f.others {
+		if c.Name == name {
+			return fmt.Errorf("'%s' already exists", name)
+		}
+	}
+	return nil
+}
+
+func (f *configForm) validatePort(s string) error {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("port must be a number")
+	}
+	if err := config.ValidatePort(port); err != nil {
+		return err
+	}
+	for _, c := range f.others {
+		if c.Port == port {
+			return fmt.Errorf("port %d is used by '%s'", port, c.Name)
+		}
+	}
+	return nil
+}
+
+func validateCommand(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("command is required")
+	}
+	return nil
+}
+
+func validateDir(s string) error {
+	return config.ValidateDirectory(expandHome(strings.TrimSpace(s)))
+}
+
+func validateEnv(s string) error {
+	_, err := config.ParseEnv(strings.Split(s, "\n"))
+	return err
+}
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(dir string) string {
+	if dir != "~" && !strings.HasPrefix(dir, "~/") {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return dir
+	}
+	return filepath.Join(home, dir[1:])
+}
+
+// formatEnv formats env as NAME=value lines, sorted by name.
+func formatEnv(env map[string]string) string {
+	lines := make([]string, 0, len(env))
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		lines = append(lines, name+"="+env[name])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// result returns the config the form describes: the base config, so
+// settings the form does not show are kept, with the edited fields.
+func (f *configForm) result() (config.ProcessConfig, error) {
+	cfg := f.base
+	v := f.values
+	cfg.Name = strings.TrimSpace(v.name)
+	port, err := strconv.Atoi(strings.TrimSpace(v.port))
+	if err != nil {
+		return cfg, fmt.Errorf("invalid port: %w", err)
+	}
+	cfg.Port = port
+	cfg.Directory = expandHome(strings.TrimSpace(v.dir))
+	// Keep the argv form unless the command was changed.
+	if command := strings.TrimSpace(v.command); command != f.base.CommandLine() {
+		cfg.Command, cfg.Args = command, nil
+	}
+	env, err := config.ParseEnv(strings.Split(v.env, "\n"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Env = env
+	cfg.Tags = parseTags(v.tags)
+	return cfg, nil
+}
+
+// parseTags splits a comma or space separated list of tags.
+func parseTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+}
+
+// openForm opens a config form for action on the selected item.
+func (m model) openForm(action formAction) (model, tea.Cmd) {
+	configs, err := config.LoadConfigs(config.ConfigFile)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("failed to load configs: %s", err)
+		m.statusErr = true
+		return m, nil
+	}
+
+	var base config.ProcessConfig
+	if action != formCreate {
+		if len(m.items) == 0 {
+			return m, nil
+		}
+		item := m.items[m.cursor]
+		if !item.Configured {
+			m.statusMsg = fmt.Sprintf("'%s' is not saved: save it first (press 's')", item.Name)
+			m.statusErr = true
+			return m, nil
+		}
+		cfg, err := config.GetConfig(config.ConfigFile, item.Name)
+		if err != nil {
+			m.statusMsg = err.Error()
+			m.statusErr = true
+			return m, nil
+		}
+		base = *cfg
+	}
+
+	m.form = newConfigForm(action, base, configs, m.width)
+	m.statusMsg = ""
+	return m, m.form.form.Init()
+}
+
+// updateForm passes a message to the open form, writing the config once
+// it is submitted.
+func (m model) updateForm(msg tea.Msg) (model, tea.Cmd) {
+	form, cmd := m.form.form.Update(msg)
+	if form, ok := form.(*huh.Form); ok {
+		m.form.form = form
+	}
+
+	switch m.form.form.State {
+	case huh.StateAborted:
+		m.form = nil
+		return m, nil
+	case huh.StateCompleted:
+		f := m.form
+		m.form = nil
+		if err := f.submit(); err != nil {
+			m.statusMsg = err.Error()
+			m.statusErr = true
+			return m, nil
+		}
+		m.statusMsg = f.done()
+		m.statusErr = false
+		return m, loadItems
+	}
+	return m, cmd
+}
+
+// submit writes the form's result through the config package.
+func (f *configForm) submit() error {
+	if f.action == formDelete {
+		if !*f.confirm {
+			return nil
+		}
+		if err := config.DeleteConfig(config.ConfigFile, f.base.Name); err != nil {
+			return fmt.Errorf("failed to delete '%s': %w", f.base.Name, err)
+		}
+		return nil
+	}
+
+	cfg, err := f.result()
+	if err != nil {
+		return err
+	}
+	oldName := ""
+	if f.action == formEdit {
+		oldName = f.base.Name
+	}
+	if err := config.ReplaceConfig(config.ConfigFile, oldName, cfg); err != nil {
+		return fmt.Errorf("failed to save '%s': %w", cfg.Name, err)
+	}
+	return nil
+}
+
+// done returns the status message after a successful submit.
+func (f *configForm) done() string {
+	switch f.action {
+	case formDelete:
+		if !*f.confirm {
+			return ""
+		}
+		return fmt.Sprintf("'%s' deleted from config", f.base.Name)
+	case formEdit:
+		msg := fmt.Sprintf("'%s' saved", strings.TrimSpace(f.values.name))
+		if name := strings.TrimSpace(f.values.name); name != f.base.Name {
+			msg += fmt.Sprintf(" (was '%s')", f.base.Name)
+		}
+		return msg + "; restart it to apply"
+	}
+	return fmt.Sprintf("'%s' saved to config", strings.TrimSpace(f.values.name))
+}
@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"github.com/jaiir320/devserve/config"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// swapConfigFile points the TUI at a config file holding configs, and
+// away from any running daemon.
+func swapConfigFile(t *testing.T, configs ...config.ProcessConfig) string {
+	t.Helper()
+	oldFile, oldSocket := config.ConfigFile, config.Socket
+	dir := t.TempDir()
+	config.ConfigFile = filepath.Join(dir, "config.json")
+	config.Socket = filepath.Join(dir, "daemon.sock")
+	t.Cleanup(func() { config.ConfigFile, config.Socket = oldFile, oldSocket })
+	for _, c := range configs {
+		if err := config.SaveConfig(config.ConfigFile, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return config.ConfigFile
+}
+
+// updateAll passes msg to the model, then the messages of the commands
+// it returns, as the program would.
+func updateAll(m model, msg tea.Msg) model {
+	msgs := []tea.Msg{msg}
+	for len(msgs) > 0 && len(msgs) < 100 {
+		var next tea.Model
+		var cmd tea.Cmd
+		next, cmd = m.Update(msgs[0])
+		m = next.(model)
+		msgs = msgs[1:]
+		msgs = append(msgs, runCmd(cmd)...)
+	}
+	return m
+}
+
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case nil:
+		return nil
+	case tea.BatchMsg:
+		var msgs []tea.Msg
+		for _, c := range msg {
+			msgs = append(msgs, runCmd(c)...)
+		}
+		return msgs
+	default:
+		return []tea.Msg{msg}
+	}
+}
+
+func TestConfigFormValidation(t *testing.T) {
+	dir := t.TempDir()
+	web := config.ProcessConfig{Name: "web", Port: 3000, Command: "npm run dev", Directory: dir}
+	api := config.ProcessConfig{Name: "api", Port: 4000, Command: "go run .", Directory: dir}
+	f := newConfigForm(formEdit, web, []config.ProcessConfig{web, api}, 80)
+
+	if err := f.validateName("web"); err != nil {
+		t.Errorf("keeping the name: %v", err)
+	}
+	if err := f.validateName("api"); err == nil {
+		t.Error("expected an error renaming onto another config")
+	}
+	if err := f.validatePort("3000"); err != nil {
+		t.Errorf("keeping the port: %v", err)
+	}
+	for _, port := range []string{"4000", "0", "70000", "http"} {
+		if err := f.validatePort(port); err == nil {
+			t.Errorf("validatePort(%q): expected an error", port)
+		}
+	}
+	if err := validateDir("relative"); err == nil {
+		t.Error("expected an error for a relative directory")
+	}
+	if err := validateEnv("A=1\nnot a pair"); err == nil {
+		t.Error("expected an error for a malformed env line")
+	}
+
+	dup := newConfigForm(formDuplicate, web, []config.ProcessConfig{web, api}, 80)
+	if dup.values.name != "web-copy" || dup.values.port != "" {
+		t.Errorf("expected a renamed copy without a port, got %q %q", dup.values.name, dup.values.port)
+	}
+	if err := dup.validateName("web"); err == nil {
+		t.Error("expected a copy to need a new name")
+	}
+}
+
+func TestConfigFormResult(t *testing.T) {
+	dir := t.TempDir()
+	base := config.ProcessConfig{
+		Name: "web", Port: 3000, Directory: dir,
+		Args:      []string{"node", "server.js", "--title", "my app"},
+		MemoryMax: "512M",
+	}
+
+	f := newConfigForm(formEdit, base, []config.ProcessConfig{base}, 80)
+	f.values.port = " 3001 "
+	f.values.env = "NODE_ENV=development\n\nDEBUG=app:*\n"
+	cfg, err := f.result()
+	if err != nil {
+		t.Fatalf("result failed: %v", err)
+	}
+	if cfg.Port != 3001 || cfg.MemoryMax != "512M" || len(cfg.Args) != 4 || cfg.Command != "" {
+		t.Errorf("expected the port changed and the rest kept, got %+v", cfg)
+	}
+	if len(cfg.Env) != 2 || cfg.Env["DEBUG"] != "app:*" {
+		t.Errorf("unexpected env %v", cfg.Env)
+	}
+
+	// A changed command replaces the argv form.
+	f.values.command = "npm start"
+	cfg, err = f.result()
+	if err != nil {
+		t.Fatalf("result failed: %v", err)
+	}
+	if cfg.Command != "npm start" || cfg.Args != nil {
+		t.Errorf("expected the command to replace args, got %q %v", cfg.Command, cfg.Args)
+	}
+}
+
+func TestConfigFormSubmit(t *testing.T) {
+	dir := t.TempDir()
+	web := config.ProcessConfig{Name: "web", Port: 3000, Command: "npm run dev", Directory: dir, StopSignal: "SIGINT"}
+	api := config.ProcessConfig{Name: "api", Port: 4000, Command: "go run .", Directory: dir}
+	path := swapConfigFile(t, web, api)
+
+	f := newConfigForm(formEdit, web, []config.ProcessConfig{web, api}, 80)
+	f.values.name = "frontend"
+	if err := f.submit(); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	dup := newConfigForm(formDuplicate, api, []config.ProcessConfig{web, api}, 80)
+	dup.values.port = "4001"
+	if err := dup.submit(); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+
+	configs, err := config.LoadConfigs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 3 || configs[0].Name != "frontend" || configs[2].Name != "api-copy" {
+		t.Fatalf("expected [frontend api api-copy], got %+v", configs)
+	}
+	if configs[0].StopSignal != "SIGINT" {
+		t.Errorf("expected settings outside the form to be kept, got %+v", configs[0])
+	}
+	if configs[2].Port != 4001 || configs[2].Command != "go run ." {
+		t.Errorf("unexpected copy %+v", configs[2])
+	}
+}
+
+func TestConfigFormDelete(t *testing.T) {
+	web := config.ProcessConfig{Name: "web", Port: 3000, Command: "npm run dev", Directory: "/app"}
+	path := swapConfigFile(t, web)
+	m := model{width: 80, height: 24, items: []listItem{{Name: "web", Port: 3000, Configured: true}}}
+
+	// esc cancels the form without quitting or deleting anything.
+	m = updateAll(m, keyMsg("d"))
+	if m.form == nil || m.form.action != formDelete {
+		t.Fatal("expected the delete form to open")
+	}
+	m = updateAll(m, keyMsg("esc"))
+	if m.form != nil {
+		t.Fatal("expected esc to close the form")
+	}
+	if c, _ := config.GetConfig(path, "web"); c == nil {
+		t.Fatal("expected the config to be kept")
+	}
+
+	m = updateAll(m, keyMsg("d"))
+	m = updateAll(m, keyMsg("y"))
+	if m.form != nil {
+		t.Fatalf("expected the form to close, state %v", m.form.form.State)
+	}
+	if c, _ := config.GetConfig(path, "web"); c != nil {
+		t.Error("expected the config to be deleted")
+	}
+}
+
+func TestConfigFormNeedsSavedItem(t *testing.T) {
+	swapConfigFile(t)
+	m := model{width: 80, items: []listItem{{Name: "tmp", Port: 3000}}}
+	for _, key := range []string{"e", "c", "d"} {
+		m = updateAll(m, keyMsg(key))
+		if m.form != nil || !m.statusErr {
+			t.Errorf("%s: expected an error for an unsaved process", key)
+		}
+	}
+	m = updateAll(m, keyMsg("n"))
+	if m.form == nil || m.form.form.State != huh.StateNormal {
+		t.Error("expected n to open the create form")
+	}
+}
@@ -1,8 +1,75 @@
 package tui
 
-import "github.com/jaiir320/devserve/cli"
+import (
+	"github.com/jaiir320/devserve/cli"
+	"fmt"
+	"strings"
 
-// renderHelp returns the bottom help bar string.
-func renderHelp() string {
-	return cli.Dim.Render("  ↑/↓ navigate • enter start/stop • s save/unsave • q quit")
+	"github.com/charmbracelet/lipgloss"
+)
+
+// signalKeys maps keys in signal mode to the signal they send.
+var signalKeys = map[string]string{
+	"h": "SIGHUP",
+	"i": "SIGINT",
+	"q": "SIGQUIT",
+	"1": "SIGUSR1",
+	"2": "SIGUSR2",
+	"t": "SIGTERM",
+	"k": "SIGKILL",
+}
+
+// helpKeys are the keys listed in the help bar under the list.
+var helpKeys = []string{
+	"↑/↓ navigate", "enter start/stop", "/ filter", "S sort", "G group", "space mark",
+	"s save/unsave", "n new", "e edit", "c duplicate", "d delete",
+	"o open URL", "y copy URL", "u next URL", "Q QR code", "l logs", "x signal", "q quit",
+}
+
+// renderHelp returns the bottom help bar string, wrapped to width.
+func renderHelp(width int) string {
+	return wrapHelp(helpKeys, width)
+}
+
+// renderMarkedHelp returns the help bar shown while items are marked.
+func renderMarkedHelp(marked, width int) string {
+	return wrapHelp([]string{fmt.Sprintf("%d marked", marked), "enter start/stop marked", "space mark/unmark", "A mark all", "esc clear marks"}, width)
+}
+
+// wrapHelp joins keys into help bar lines no wider than width, breaking
+// between keys. A width of 0 means one line.
+func wrapHelp(keys []string, width int) string {
+	const indent, sep = "  ", " • "
+	var lines []string
+	line := indent
+	for _, k := range keys {
+		switch {
+		case line == indent:
+			line += k
+		case width > 0 && lipgloss.Width(line+sep+k) > width:
+			lines = append(lines, line)
+			line = indent + k
+		default:
+			line += sep + k
+		}
+	}
+	lines = append(lines, line)
+	return cli.Dim.Render(strings.Join(lines, "\n"))
+}
+
+// renderLogHelp returns the help bar shown in the log viewer.
+func renderLogHelp(selecting bool) string {
+	if selecting {
+		return cli.Dim.Render("  ↑/↓ extend selection • y copy • v/esc cancel")
+	}
+	return cli.Dim.Render("  tab stdout/stderr • ↑/↓ pgup/pgdn g/G scroll • / search • n/N next/prev • f follow • v select • y copy • esc back")
+}
+
+// renderSignalHelp returns the help bar shown while choosing a signal.
+func renderSignalHelp(name string, group bool, width int) string {
+	target := "process"
+	if group {
+		target = "process group"
+	}
+	return wrapHelp([]string{fmt.Sprintf("signal '%s' %s: h HUP", name, target), "i INT", "q QUIT", "1 USR1", "2 USR2", "t TERM", "k KILL", "g toggle group", "esc cancel"}, width)
 }
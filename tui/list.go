@@ -0,0 +1,378 @@
+package tui
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sortMode orders the list within each group.
+type sortMode int
+
+const (
+	sortPort sortMode = iota
+	sortName
+	sortStatus // running first
+	sortRecent // most recently started first
+	numSortModes
+)
+
+var sortModeNames = [numSortModes]string{"port", "name", "status", "recently started"}
+
+// groupMode splits the list into sections.
+type groupMode int
+
+const (
+	groupSaved groupMode = iota // saved configs, then unsaved processes
+	groupDir
+	groupTag // by first tag
+	numGroupModes
+)
+
+var groupModeNames = [numGroupModes]string{"saved/unsaved", "directory", "tag"}
+
+// untaggedGroup is the tag group of items without tags, listed last.
+const untaggedGroup = "untagged"
+
+// applyView rebuilds the visible list from all items: filtered, grouped
+// and sorted, with the cursor kept on the same process when it is still
+// shown.
+func (m model) applyView() model {
+	selected := ""
+	if m.cursor < len(m.items) {
+		selected = m.items[m.cursor].Name
+	}
+
+	items := make([]listItem, 0, len(m.all))
+	for _, item := range m.all {
+		if matchesFilter(item, m.filter) {
+			items = append(items, item)
+		}
+	}
+	sortItems(items, m.sort)
+	slices.SortStableFunc(items, func(a, b listItem) int {
+		return compareGroups(groupKey(a, m.group), groupKey(b, m.group), m.group)
+	})
+	m.items = items
+
+	for i, item := range items {
+		if item.Name == selected {
+			m.cursor = i
+			return m
+		}
+	}
+	m.moveCursor(0)
+	return m
+}
+
+// sortItems sorts items by mode, falling back to port order.
+func sortItems(items []listItem, mode sortMode) {
+	slices.SortStableFunc(items, func(a, b listItem) int {
+		switch mode {
+		case sortName:
+			if c := strings.Compare(a.Name, b.Name); c != 0 {
+				return c
+			}
+		case sortStatus:
+			if a.Running != b.Running {
+				if a.Running {
+					return -1
+				}
+				return 1
+			}
+		case sortRecent:
+			if c := b.Started.Compare(a.Started); c != 0 {
+				return c
+			}
+		}
+		return a.Port - b.Port
+	})
+}
+
+// groupKey returns the group item belongs to under mode.
+func groupKey(item listItem, mode groupMode) string {
+	switch mode {
+	case groupDir:
+		return item.Dir
+	case groupTag:
+		if len(item.Tags) == 0 {
+			return untaggedGroup
+		}
+		return item.Tags[0]
+	}
+	if item.Configured {
+		return "saved"
+	}
+	return "unsaved"
+}
+
+// compareGroups orders group keys: saved before unsaved, tags and
+// directories alphabetically with untagged items last.
+func compareGroups(a, b string, mode groupMode) int {
+	if a == b {
+		return 0
+	}
+	switch mode {
+	case groupSaved:
+		if a == "saved" {
+			return -1
+		}
+		return 1
+	case groupTag:
+		if a == untaggedGroup {
+			return 1
+		}
+		if b == untaggedGroup {
+			return -1
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// groupTitle returns the header shown above a group.
+func groupTitle(key string, mode groupMode) string {
+	if mode == groupDir {
+		if key == "" {
+			return "no directory"
+		}
+		if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(key, home+string(filepath.Separator)) {
+			return "~" + key[len(home):]
+		}
+	}
+	return key
+}
+
+// matchesFilter reports whether item matches a fuzzy filter on its name
+// or tags, or a port number prefix.
+func matchesFilter(item listItem, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	if fuzzyMatch(filter, item.Name) || strings.HasPrefix(strconv.Itoa(item.Port), filter) {
+		return true
+	}
+	for _, tag := range item.Tags {
+		if fuzzyMatch(filter, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in s in
+// order, ignoring case and spaces in pattern: "wfe" matches "web-frontend".
+func fuzzyMatch(pattern, s string) bool {
+	rs := []rune(strings.ToLower(s))
+	i := 0
+	for _, p := range strings.ToLower(pattern) {
+		if unicode.IsSpace(p) {
+			continue
+		}
+		for i < len(rs) && rs[i] != p {
+			i++
+		}
+		if i == len(rs) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// listLines returns the lines of the list, group headers included, each
+// ending in a newline, and the line the cursor is on.
+func (m model) listLines() (lines []string, cursorLine int) {
+	group := ""
+	for i, item := range m.items {
+		if key := groupKey(item, m.group); i == 0 || key != group {
+			switch {
+			case m.group != groupSaved:
+				lines = append(lines, " "+cli.Dim.Render(truncate(groupTitle(key, m.group), leftContentWidth))+"\n")
+			case i > 0:
+				// Separate unsaved processes from saved configs.
+				lines = append(lines, " "+cli.Dim.Render(strings.Repeat("─", leftContentWidth))+"\n")
+			}
+			group = key
+		}
+		if i == m.cursor {
+			cursorLine = len(lines)
+		}
+		lines = append(lines, renderItemRow(m, item, i))
+	}
+	return lines, cursorLine
+}
+
+// listHeight returns the number of list lines that fit in the left pane,
+// or 0 when the window height is not known yet.
+func (m model) listHeight() int {
+	if m.height == 0 {
+		return 0
+	}
+	h := m.height - 2 - lipgloss.Height(m.helpBar()) // pane borders and the help bar
+	if m.statusMsg != "" {
+		h--
+	}
+	if m.filter != "" || m.filterTyping {
+		h--
+	}
+	return max(h, 3)
+}
+
+// scrollList keeps the cursor line within the visible part of the list.
+func (m *model) scrollList() {
+	h := m.listHeight()
+	if h == 0 {
+		m.offset = 0
+		return
+	}
+	lines, cursorLine := m.listLines()
+	if len(lines) > h {
+		h-- // the position line
+	}
+	if m.cursor == 0 {
+		cursorLine = 0 // show the first group's header too
+	}
+	m.offset = min(m.offset, max(len(lines)-h, 0))
+	if cursorLine < m.offset {
+		m.offset = cursorLine
+	}
+	if cursorLine >= m.offset+h {
+		m.offset = cursorLine - h + 1
+	}
+}
+
+// truncate shortens s to width runes, marking the cut with an ellipsis.
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
+
+// handleFilterKey edits the filter while it is being typed. The list
+// narrows as you type; enter keeps the filter and esc clears it.
+func (m model) handleFilterKey(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filterTyping = false
+	case tea.KeyEsc:
+		m.filterTyping = false
+		m.filter = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.filter); len(r) > 0 {
+			m.filter = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	case tea.KeyUp, tea.KeyDown:
+		if msg.Type == tea.KeyUp {
+			m.moveCursor(-1)
+		} else {
+			m.moveCursor(1)
+		}
+		return m, nil
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	}
+	return m.applyView(), nil
+}
+
+// toggleMark marks or unmarks the selected item for a bulk action and
+// moves to the next one.
+func (m model) toggleMark() model {
+	if len(m.items) == 0 {
+		return m
+	}
+	name := m.items[m.cursor].Name
+	if m.marked[name] {
+		delete(m.marked, name)
+	} else {
+		if m.marked == nil {
+			m.marked = make(map[string]bool)
+		}
+		m.marked[name] = true
+	}
+	m.moveCursor(1)
+	return m
+}
+
+// toggleMarkAll marks every visible item, or clears the marks if they
+// are all marked already.
+func (m model) toggleMarkAll() model {
+	all := len(m.items) > 0
+	for _, item := range m.items {
+		all = all && m.marked[item.Name]
+	}
+	if all {
+		m.marked = nil
+		return m
+	}
+	if m.marked == nil {
+		m.marked = make(map[string]bool)
+	}
+	for _, item := range m.items {
+		m.marked[item.Name] = true
+	}
+	return m
+}
+
+// bulkStartStop starts the marked processes that are stopped, or, when
+// all are running, stops them. Unsaved processes cannot be started and
+// are skipped.
+func (m model) bulkStartStop() (model, tea.Cmd) {
+	var targets []listItem
+	start := false
+	for _, item := range m.all {
+		if m.marked[item.Name] {
+			targets = append(targets, item)
+			start = start || !item.Running
+		}
+	}
+	m.marked = nil
+
+	var cmds []tea.Cmd
+	skipped := 0
+	for _, item := range targets {
+		if _, busy := m.pending[item.Name]; busy || item.Running == start {
+			continue
+		}
+		if start && !item.Configured {
+			skipped++
+			continue
+		}
+		var cmd tea.Cmd
+		if start {
+			m, cmd = m.startAction(item)
+		} else {
+			m, cmd = m.stopAction(item)
+		}
+		cmds = append(cmds, cmd)
+	}
+
+	verb := "stopping"
+	if start {
+		verb = "starting"
+	}
+	m.statusMsg = fmt.Sprintf("%s %d %s", verb, len(cmds), plural(len(cmds), "process", "processes"))
+	m.statusErr = false
+	if skipped > 0 {
+		m.statusMsg += fmt.Sprintf(" (%d unsaved skipped)", skipped)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
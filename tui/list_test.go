@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func names(items []listItem) []string {
+	var out []string
+	for _, item := range items {
+		out = append(out, item.Name)
+	}
+	return out
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "web", true},
+		{"web", "web-frontend", true},
+		{"wfe", "web-frontend", true},
+		{"WFE", "web-frontend", true},
+		{"w f", "web-frontend", true},
+		{"fw", "web-frontend", false},
+		{"api", "apps", false},
+	}
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func testItems() []listItem {
+	now := time.Now()
+	return []listItem{
+		{Name: "web", Port: 3000, Dir: "/p/shop", Configured: true, Running: true, Started: now.Add(-time.Hour), Tags: []string{"frontend"}},
+		{Name: "api", Port: 4000, Dir: "/p/shop", Configured: true, Running: true, Started: now, Tags: []string{"backend"}},
+		{Name: "admin", Port: 3100, Dir: "/p/admin", Configured: true, Tags: []string{"frontend"}},
+		{Name: "scratch", Port: 8080, Dir: "/tmp", Running: true, Started: now.Add(-time.Minute)},
+	}
+}
+
+func TestApplyViewSortAndGroup(t *testing.T) {
+	tests := []struct {
+		sort  sortMode
+		group groupMode
+		want  []string
+	}{
+		{sortPort, groupSaved, []string{"web", "admin", "api", "scratch"}},
+		{sortName, groupSaved, []string{"admin", "api", "web", "scratch"}},
+		{sortStatus, groupSaved, []string{"web", "api", "admin", "scratch"}},
+		{sortRecent, groupSaved, []string{"api", "web", "admin", "scratch"}},
+		{sortPort, groupDir, []string{"admin", "web", "api", "scratch"}},
+		{sortName, groupTag, []string{"api", "admin", "web", "scratch"}},
+	}
+	for _, tt := range tests {
+		m := model{sort: tt.sort, group: tt.group}.setItems(testItems())
+		if got := names(m.items); !slices.Equal(got, tt.want) {
+			t.Errorf("sort %s, group %s: got %v, want %v", sortModeNames[tt.sort], groupModeNames[tt.group], got, tt.want)
+		}
+	}
+}
+
+func TestApplyViewFilter(t *testing.T) {
+	m := model{}.setItems(testItems())
+	m.cursor = 2 // api
+
+	m = pressListKeys(m, "/", "a")
+	if got := names(m.items); !slices.Equal(got, []string{"admin", "api", "scratch"}) {
+		t.Errorf("filter a: got %v", got)
+	}
+	if m.items[m.cursor].Name != "api" {
+		t.Errorf("expected the cursor to stay on api, got %s", m.items[m.cursor].Name)
+	}
+
+	// Tags and port prefixes match too.
+	m = pressListKeys(m, "backspace", "f", "r", "n", "t")
+	if got := names(m.items); !slices.Equal(got, []string{"web", "admin"}) {
+		t.Errorf("filter frnt: got %v", got)
+	}
+	m = pressListKeys(m, "backspace", "backspace", "backspace", "backspace", "8", "0")
+	if got := names(m.items); !slices.Equal(got, []string{"scratch"}) {
+		t.Errorf("filter 80: got %v", got)
+	}
+
+	// enter keeps the filter and returns to the list; esc clears it.
+	m = pressListKeys(m, "enter")
+	if m.filterTyping || m.filter != "80" {
+		t.Errorf("expected the filter kept, got %q typing=%v", m.filter, m.filterTyping)
+	}
+	m = pressListKeys(m, "esc")
+	if m.filter != "" || len(m.items) != 4 {
+		t.Errorf("expected the filter cleared, got %q with %d items", m.filter, len(m.items))
+	}
+}
+
+func pressListKeys(m model, keys ...string) model {
+	for _, k := range keys {
+		next, _ := m.Update(keyMsg(k))
+		m = next.(model)
+	}
+	return m
+}
+
+func TestListLinesGroupHeaders(t *testing.T) {
+	m := model{group: groupTag}.setItems(testItems())
+	lines, cursor := m.listLines()
+	var text []string
+	for _, line := range lines {
+		text = append(text, strings.TrimSpace(line))
+	}
+	joined := strings.Join(text, "|")
+	for _, want := range []string{"backend", "frontend", "untagged"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected a %q header in %q", want, joined)
+		}
+	}
+	if len(lines) != 7 || cursor != 1 {
+		t.Errorf("expected 3 headers and 4 rows with the cursor under the first header, got %d lines, cursor %d", len(lines), cursor)
+	}
+}
+
+func TestListScrollsToCursor(t *testing.T) {
+	var items []listItem
+	for i := range 30 {
+		items = append(items, listItem{Name: strings.Repeat("x", i%5+1) + string(rune('a'+i%26)), Port: 3000 + i, Configured: true})
+	}
+	m := model{width: 80, height: 12}.setItems(items)
+	h := m.listHeight()
+
+	for range 20 {
+		next, _ := m.Update(keyMsg("j"))
+		m = next.(model)
+	}
+	if m.cursor != 20 {
+		t.Fatalf("expected the cursor on 20, got %d", m.cursor)
+	}
+	if m.offset > 20 || 20 >= m.offset+h-1 {
+		t.Errorf("expected line 20 visible, offset %d height %d", m.offset, h)
+	}
+	if pane := renderLeftPane(m); !strings.Contains(pane, "21/30") || strings.Count(pane, "\n") != h {
+		t.Errorf("expected %d lines ending in the position, got:\n%s", h, pane)
+	}
+
+	next, _ := m.Update(keyMsg("home"))
+	m = next.(model)
+	if m.offset != 0 {
+		t.Errorf("expected the list back at the top, offset %d", m.offset)
+	}
+}
+
+func TestBulkStartStop(t *testing.T) {
+	m := model{spinner: newSpinner()}.setItems(testItems())
+
+	// Marking a stopped process starts the stopped, saved ones.
+	m = m.toggleMarkAll()
+	m, cmd := m.bulkStartStop()
+	if cmd == nil || len(m.pending) != 1 || m.pending["admin"] != "starting" {
+		t.Errorf("expected only admin to start, got %v", m.pending)
+	}
+	if len(m.marked) != 0 {
+		t.Error("expected the marks cleared")
+	}
+
+	// When all marked processes run, they are stopped.
+	m.pending = nil
+	m.marked = map[string]bool{"web": true, "scratch": true}
+	m, _ = m.bulkStartStop()
+	if len(m.pending) != 2 || m.pending["web"] != "stopping" || m.pending["scratch"] != "stopping" {
+		t.Errorf("expected web and scratch to stop, got %v", m.pending)
+	}
+
+	// Unsaved processes cannot be started.
+	m.pending = nil
+	m.all[3].Running = false
+	m.marked = map[string]bool{"scratch": true}
+	m, _ = m.bulkStartStop()
+	if len(m.pending) != 0 || !strings.Contains(m.statusMsg, "1 unsaved skipped") {
+		t.Errorf("expected scratch skipped, got %v %q", m.pending, m.statusMsg)
+	}
+}
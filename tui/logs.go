@@ -0,0 +1,467 @@
+package tui
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/protocol"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// logTab is the log shown in the log viewer.
+type logTab int
+
+const (
+	tabStdout logTab = iota
+	tabStderr
+)
+
+// logViewer is the full-screen log pane for one process. It opens
+// following the output, like tail -f; scrolling up stops following.
+type logViewer struct {
+	name    string
+	stdout  []string
+	stderr  []string
+	tab     logTab
+	cursor  int  // selected line
+	offset  int  // first visible line
+	anchor  int  // other end of the selected range, -1 without one
+	follow  bool // keep the cursor on the last line as output arrives
+	pending bool // a fetch or refresh tick is outstanding
+	err     error
+
+	search *regexp.Regexp // applied search, case-insensitive
+	query  string         // the applied search as typed
+	typing bool           // reading a search on the bottom line
+	input  string         // the search being typed
+	notice string         // result of the last copy
+}
+
+// logsMsg carries a process's logs from the daemon for the viewer that
+// asked for them; results for a viewer since closed are dropped.
+type logsMsg struct {
+	viewer *logViewer
+	result *protocol.LogsResult
+	err    error
+}
+
+// logsTickMsg triggers the viewer's next refresh while following.
+type logsTickMsg struct {
+	viewer *logViewer
+}
+
+func newLogViewer(name string) *logViewer {
+	return &logViewer{name: name, anchor: -1, follow: true, pending: true}
+}
+
+func fetchLogs(v *logViewer) tea.Cmd {
+	name := v.name
+	return func() tea.Msg {
+		lr, err := client.Logs(name, config.LogViewerLines)
+		return logsMsg{viewer: v, result: lr, err: err}
+	}
+}
+
+// copyToClipboard copies text with an OSC 52 escape sequence, which the
+// terminal handles even over SSH.
+func copyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		termenv.Copy(text)
+		return nil
+	}
+}
+
+// lines returns the lines of the current tab.
+func (v *logViewer) lines() []string {
+	if v.tab == tabStderr {
+		return v.stderr
+	}
+	return v.stdout
+}
+
+// setLogs replaces the logs, keeping the cursor on the last line while
+// following and within range otherwise.
+func (v *logViewer) setLogs(lr *protocol.LogsResult) {
+	v.stdout, v.stderr = lr.Stdout, lr.Stderr
+	if v.follow {
+		v.cursor = len(v.lines()) - 1
+	}
+	v.clamp()
+}
+
+// clamp keeps the cursor and the selection anchor within the lines.
+func (v *logViewer) clamp() {
+	n := len(v.lines())
+	v.cursor = min(max(v.cursor, 0), max(n-1, 0))
+	if v.anchor >= n {
+		v.anchor = -1
+	}
+}
+
+// move moves the cursor by delta lines. Moving up stops following.
+func (v *logViewer) move(delta int) {
+	if delta < 0 {
+		v.follow = false
+	}
+	v.cursor += delta
+	v.clamp()
+}
+
+// scroll adjusts the offset so the cursor is within height visible lines.
+func (v *logViewer) scroll(height int) {
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+height {
+		v.offset = v.cursor - height + 1
+	}
+	v.offset = min(max(v.offset, 0), max(len(v.lines())-height, 0))
+}
+
+// matches returns the indexes of the lines matching the search.
+func (v *logViewer) matches() []int {
+	if v.search == nil {
+		return nil
+	}
+	var idx []int
+	for i, line := range v.lines() {
+		if v.search.MatchString(line) {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// jump moves the cursor to the next match below it (dir 1) or above it
+// (dir -1), wrapping around. With inclusive set the cursor's own line
+// counts. It reports whether there was a match.
+func (v *logViewer) jump(dir int, inclusive bool) bool {
+	idx := v.matches()
+	if len(idx) == 0 {
+		return false
+	}
+	v.follow = false
+	from := v.cursor
+	if !inclusive {
+		from += dir
+	}
+	if dir > 0 {
+		for _, i := range idx {
+			if i >= from {
+				v.cursor = i
+				return true
+			}
+		}
+		v.cursor = idx[0]
+		return true
+	}
+	for j := len(idx) - 1; j >= 0; j-- {
+		if idx[j] <= from {
+			v.cursor = idx[j]
+			return true
+		}
+	}
+	v.cursor = idx[len(idx)-1]
+	return true
+}
+
+// selection returns the first and last line of the selected range: the
+// lines between the anchor and the cursor, or the cursor's line.
+func (v *logViewer) selection() (int, int) {
+	if v.anchor < 0 {
+		return v.cursor, v.cursor
+	}
+	return min(v.anchor, v.cursor), max(v.anchor, v.cursor)
+}
+
+// selectedText returns the selected lines joined by newlines.
+func (v *logViewer) selectedText() (string, int) {
+	lines := v.lines()
+	if len(lines) == 0 {
+		return "", 0
+	}
+	first, last := v.selection()
+	return strings.Join(lines[first:last+1], "\n"), last - first + 1
+}
+
+// logHeight returns how many log lines fit on screen, leaving room for
+// the tab bar, the status line and the help bar.
+func (m model) logHeight() int {
+	if m.height == 0 {
+		return 20
+	}
+	return max(m.height-3, 1)
+}
+
+// updateLogs handles a fetch result or refresh tick for the log viewer,
+// scheduling the next refresh while following.
+func (m model) updateLogs(msg tea.Msg) (model, tea.Cmd) {
+	v := m.logs
+	switch msg := msg.(type) {
+	case logsMsg:
+		if v == nil || msg.viewer != v {
+			return m, nil
+		}
+		v.pending = false
+		v.err = msg.err
+		if msg.err != nil {
+			// The process is gone; there is nothing more to follow.
+			if errors.Is(msg.err, protocol.ErrNotFound) {
+				v.follow = false
+			}
+		} else {
+			v.setLogs(msg.result)
+			v.scroll(m.logHeight())
+		}
+		if !v.follow {
+			return m, nil
+		}
+		v.pending = true
+		return m, tea.Tick(config.LogFollowInterval, func(time.Time) tea.Msg {
+			return logsTickMsg{viewer: v}
+		})
+
+	case logsTickMsg:
+		if v == nil || msg.viewer != v {
+			return m, nil
+		}
+		if !v.follow {
+			v.pending = false
+			return m, nil
+		}
+		return m, fetchLogs(v)
+	}
+	return m, nil
+}
+
+// handleLogKey handles a key press while the log viewer is open.
+func (m model) handleLogKey(msg tea.KeyMsg) (model, tea.Cmd) {
+	v := m.logs
+	v.notice = ""
+	if v.typing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			v.typing = false
+			v.search, v.query = nil, v.input
+			if v.input != "" {
+				v.search = regexp.MustCompile("(?i)" + regexp.QuoteMeta(v.input))
+				// Start from the newest output, where the viewer opens.
+				v.jump(-1, true)
+			}
+		case tea.KeyEsc:
+			v.typing = false
+		case tea.KeyBackspace:
+			if r := []rune(v.input); len(r) > 0 {
+				v.input = string(r[:len(r)-1])
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			v.input += string(msg.Runes)
+		case tea.KeyCtrlC:
+			return m, tea.Quit
+		}
+		v.scroll(m.logHeight())
+		return m, nil
+	}
+
+	page := m.logHeight()
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q":
+		m.logs = nil
+		return m, nil
+	case "esc":
+		// Clear the selection, then the search, then close.
+		switch {
+		case v.anchor >= 0:
+			v.anchor = -1
+		case v.search != nil:
+			v.search, v.query = nil, ""
+		default:
+			m.logs = nil
+			return m, nil
+		}
+	case "tab":
+		v.tab = 1 - v.tab
+		v.anchor = -1
+		v.cursor = len(v.lines()) - 1
+		v.offset = 0
+		v.clamp()
+	case "up", "k":
+		v.move(-1)
+	case "down", "j":
+		v.move(1)
+	case "pgup", "ctrl+b":
+		v.move(-page)
+	case "pgdown", "ctrl+f", " ":
+		v.move(page)
+	case "home", "g":
+		v.move(-len(v.lines()))
+	case "end", "G":
+		v.move(len(v.lines()))
+	case "/":
+		v.typing = true
+		v.input = ""
+	case "n":
+		v.jump(1, false)
+	case "N":
+		v.jump(-1, false)
+	case "f":
+		v.follow = !v.follow
+		if v.follow {
+			v.cursor = len(v.lines()) - 1
+			v.clamp()
+			if !v.pending {
+				v.pending = true
+				cmd = fetchLogs(v)
+			}
+		}
+	case "v":
+		if v.anchor >= 0 {
+			v.anchor = -1
+		} else {
+			v.anchor = v.cursor
+		}
+	case "y":
+		text, n := v.selectedText()
+		if n > 0 {
+			v.anchor = -1
+			v.notice = fmt.Sprintf("copied %d line(s)", n)
+			cmd = copyToClipboard(text)
+		}
+	}
+	v.scroll(page)
+	return m, cmd
+}
+
+// Log viewer styles.
+var (
+	activeTabStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+	cursorStyle    = lipgloss.NewStyle().Bold(true)
+	selectedLine   = lipgloss.NewStyle().Reverse(true)
+	matchStyle     = lipgloss.NewStyle().Reverse(true)
+	warnStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+)
+
+// Patterns for the level of a log line, matched as whole words so that
+// "errors: 0" in a summary line is not an error.
+var (
+	errorLevel = regexp.MustCompile(`(?i)\b(err|error|fatal|panic|crit|critical|exception)\b`)
+	warnLevel  = regexp.MustCompile(`(?i)\b(warn|warning|deprecated)\b`)
+	debugLevel = regexp.MustCompile(`(?i)\b(debug|trace)\b`)
+)
+
+// logLevel returns the level a log line mentions: "error", "warn",
+// "debug" or "".
+func logLevel(line string) string {
+	switch {
+	case errorLevel.MatchString(line):
+		return "error"
+	case warnLevel.MatchString(line):
+		return "warn"
+	case debugLevel.MatchString(line):
+		return "debug"
+	}
+	return ""
+}
+
+var levelStyles = map[string]lipgloss.Style{
+	"error": cli.Red,
+	"warn":  warnStyle,
+	"debug": cli.Dim,
+}
+
+// renderLogView renders the log viewer: the tab bar, the visible lines
+// and the status and help lines.
+func (m model) renderLogView() string {
+	v := m.logs
+	height := m.logHeight()
+	var b strings.Builder
+
+	// Tab bar
+	tabs := []string{fmt.Sprintf("stdout (%d)", len(v.stdout)), fmt.Sprintf("stderr (%d)", len(v.stderr))}
+	for i := range tabs {
+		if logTab(i) == v.tab {
+			tabs[i] = activeTabStyle.Render(tabs[i])
+		} else {
+			tabs[i] = cli.Dim.Render(tabs[i])
+		}
+	}
+	b.WriteString(" " + cli.Bold.Render(v.name) + "  " + strings.Join(tabs, "  "))
+	if v.follow {
+		b.WriteString("  " + cli.Green.Render("● following"))
+	}
+	if v.search != nil {
+		b.WriteString("  " + cli.Cyan.Render(fmt.Sprintf("/%s (%d matches)", v.query, len(v.matches()))))
+	}
+	b.WriteString("\n")
+
+	// Lines
+	lines := v.lines()
+	first, last := v.selection()
+	lineStyle := lipgloss.NewStyle().MaxWidth(max(m.width, 20))
+	for i := v.offset; i < v.offset+height; i++ {
+		if i >= len(lines) {
+			if len(lines) == 0 && i == 0 {
+				b.WriteString(" " + cli.Dim.Render("No output"))
+			}
+			b.WriteString("\n")
+			continue
+		}
+		gutter := "  "
+		if i == v.cursor {
+			gutter = cursorStyle.Render("> ")
+		}
+		line := v.renderLine(lines[i])
+		if v.anchor >= 0 && i >= first && i <= last {
+			line = selectedLine.Render(strings.ReplaceAll(lines[i], "\t", "    "))
+		}
+		b.WriteString(lineStyle.Render(gutter+line) + "\n")
+	}
+
+	// Status line
+	switch {
+	case v.typing:
+		b.WriteString(" /" + v.input + "█")
+	case v.err != nil:
+		b.WriteString("  " + cli.Error(v.err.Error()))
+	case v.notice != "":
+		b.WriteString("  " + cli.Success(v.notice))
+	}
+	b.WriteString("\n")
+	b.WriteString(renderLogHelp(v.anchor >= 0))
+	return b.String()
+}
+
+// renderLine colors a line by its level, highlighting search matches.
+func (v *logViewer) renderLine(line string) string {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	style, ok := levelStyles[logLevel(line)]
+	if !ok {
+		style = normalStyle
+	}
+	if v.search == nil {
+		return style.Render(line)
+	}
+	var b strings.Builder
+	pos := 0
+	for _, m := range v.search.FindAllStringIndex(line, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		b.WriteString(style.Render(line[pos:m[0]]))
+		b.WriteString(matchStyle.Render(line[m[0]:m[1]]))
+		pos = m[1]
+	}
+	b.WriteString(style.Render(line[pos:]))
+	return b.String()
+}
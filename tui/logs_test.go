@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"github.com/jaiir320/devserve/protocol"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "home":
+		return tea.KeyMsg{Type: tea.KeyHome}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// openLogs returns a model with the log viewer open on the given logs.
+func openLogs(stdout, stderr []string) model {
+	m := model{width: 80, height: 10}
+	m.logs = newLogViewer("web")
+	m, _ = m.updateLogs(logsMsg{viewer: m.logs, result: &protocol.LogsResult{Stdout: stdout, Stderr: stderr}})
+	return m
+}
+
+func pressKeys(m model, keys ...string) model {
+	for _, k := range keys {
+		m, _ = m.handleLogKey(keyMsg(k))
+	}
+	return m
+}
+
+func TestLogLevel(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"[ERROR] connection refused", "error"},
+		{`{"level":"error","msg":"boom"}`, "error"},
+		{"panic: runtime error", "error"},
+		{"WARN deprecated option", "warn"},
+		{"Warning: peer dependency missing", "warn"},
+		{"debug: cache hit", "debug"},
+		{"compiled successfully, 0 errors", ""},
+		{"GET /api 200", ""},
+	}
+	for _, tt := range tests {
+		if got := logLevel(tt.line); got != tt.want {
+			t.Errorf("logLevel(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestLogViewerFollow(t *testing.T) {
+	m := openLogs([]string{"a", "b", "c"}, nil)
+	v := m.logs
+	if v.cursor != 2 || !v.follow {
+		t.Fatalf("expected to follow from the last line, got cursor %d follow %v", v.cursor, v.follow)
+	}
+
+	m, _ = m.updateLogs(logsMsg{viewer: v, result: &protocol.LogsResult{Stdout: []string{"a", "b", "c", "d"}}})
+	if v.cursor != 3 {
+		t.Errorf("expected the cursor to follow new output, got %d", v.cursor)
+	}
+
+	m = pressKeys(m, "k")
+	if v.follow {
+		t.Error("expected scrolling up to stop following")
+	}
+	m, _ = m.updateLogs(logsMsg{viewer: v, result: &protocol.LogsResult{Stdout: []string{"a", "b", "c", "d", "e"}}})
+	if v.cursor != 2 {
+		t.Errorf("expected the cursor to stay put, got %d", v.cursor)
+	}
+
+	pressKeys(m, "f")
+	if !v.follow || v.cursor != 4 {
+		t.Errorf("expected f to follow from the last line, got cursor %d follow %v", v.cursor, v.follow)
+	}
+}
+
+func TestLogViewerIgnoresClosedViewer(t *testing.T) {
+	m := openLogs([]string{"a"}, nil)
+	stale := newLogViewer("web")
+
+	m, cmd := m.updateLogs(logsMsg{viewer: stale, result: &protocol.LogsResult{Stdout: []string{"x", "y"}}})
+	if cmd != nil || len(m.logs.stdout) != 1 {
+		t.Errorf("expected logs for a closed viewer to be dropped, got %q", m.logs.stdout)
+	}
+}
+
+func TestLogViewerSearch(t *testing.T) {
+	m := openLogs([]string{"GET /", "error one", "GET /a", "Error two", "GET /b"}, nil)
+
+	m = pressKeys(m, "/", "e", "r", "r", "enter")
+	v := m.logs
+	if v.cursor != 3 {
+		t.Fatalf("expected the newest match, line 3, got %d", v.cursor)
+	}
+	if v.follow {
+		t.Error("expected a search to stop following")
+	}
+	if got := v.matches(); len(got) != 2 {
+		t.Errorf("expected 2 case-insensitive matches, got %v", got)
+	}
+
+	pressKeys(m, "N")
+	if v.cursor != 1 {
+		t.Errorf("expected N to go to line 1, got %d", v.cursor)
+	}
+	pressKeys(m, "N")
+	if v.cursor != 3 {
+		t.Errorf("expected N to wrap to line 3, got %d", v.cursor)
+	}
+	pressKeys(m, "n")
+	if v.cursor != 1 {
+		t.Errorf("expected n to wrap to line 1, got %d", v.cursor)
+	}
+
+	pressKeys(m, "esc")
+	if v.search != nil || m.logs == nil {
+		t.Error("expected esc to clear the search and keep the viewer open")
+	}
+	if m = pressKeys(m, "esc"); m.logs != nil {
+		t.Error("expected a second esc to close the viewer")
+	}
+}
+
+func TestLogViewerCopyRange(t *testing.T) {
+	m := openLogs([]string{"one", "two", "three", "four"}, nil)
+
+	m = pressKeys(m, "k", "k", "v", "j")
+	text, n := m.logs.selectedText()
+	if n != 2 || text != "two\nthree" {
+		t.Errorf("expected lines two and three selected, got %d: %q", n, text)
+	}
+
+	m, cmd := m.handleLogKey(keyMsg("y"))
+	if cmd == nil {
+		t.Fatal("expected a copy command")
+	}
+	if m.logs.anchor != -1 || m.logs.notice != "copied 2 line(s)" {
+		t.Errorf("expected the selection to be cleared after copying, got anchor %d notice %q", m.logs.anchor, m.logs.notice)
+	}
+}
+
+func TestLogViewerTabs(t *testing.T) {
+	m := openLogs([]string{"out"}, []string{"err 1", "err 2"})
+
+	m = pressKeys(m, "tab")
+	if m.logs.tab != tabStderr || m.logs.cursor != 1 {
+		t.Errorf("expected the stderr tab at its last line, got tab %d cursor %d", m.logs.tab, m.logs.cursor)
+	}
+}
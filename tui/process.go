@@ -14,57 +14,46 @@ var (
 	normalStyle   = lipgloss.NewStyle()
 	runningDot    = cli.Green.Render("●")
 	stoppedDot    = cli.Dim.Render("○")
+	markedStyle   = cli.Green
 )
 
-// renderLeftPane renders the unified list view with sections.
+// renderLeftPane renders the list, scrolled to the cursor, under the
+// filter when one is set.
 func renderLeftPane(m model) string {
 	var b strings.Builder
 
-	if len(m.items) == 0 {
-		b.WriteString(" " + cli.Dim.Render("No processes") + "\n")
-		return b.String()
+	if m.filterTyping {
+		b.WriteString(" /" + m.filter + "█\n")
+	} else if m.filter != "" {
+		b.WriteString(" " + cli.Dim.Render("/"+m.filter) + "\n")
 	}
 
-	// Separate items into configured and ephemeral
-	var configured, ephemeral []listItem
-	for _, item := range m.items {
-		if item.Configured {
-			configured = append(configured, item)
+	if len(m.items) == 0 {
+		if m.filter != "" {
+			b.WriteString(" " + cli.Dim.Render("No matches") + "\n")
 		} else {
-			ephemeral = append(ephemeral, item)
-		}
-	}
-
-	// Calculate column widths
-	nameW, portW := 0, 0 // kept for compatibility, but not used with right-aligned layout
-
-	// Configured section
-	if len(configured) > 0 {
-		for i, item := range configured {
-			b.WriteString(renderItemRow(m, item, i, nameW, portW))
+			b.WriteString(" " + cli.Dim.Render("No processes") + "\n")
 		}
+		return b.String()
 	}
 
-	// Separator if both sections exist
-	if len(configured) > 0 && len(ephemeral) > 0 {
-		separator := cli.Dim.Render(strings.Repeat("─", 22)) // match content width
-		b.WriteString(" " + separator + "\n")
-	}
-
-	// Ephemeral section
-	if len(ephemeral) > 0 {
-		// Calculate offset for ephemeral items
-		offset := len(configured)
-		for i, item := range ephemeral {
-			b.WriteString(renderItemRow(m, item, offset+i, nameW, portW))
-		}
+	lines, _ := m.listLines()
+	h := m.listHeight()
+	if h == 0 || len(lines) <= h {
+		b.WriteString(strings.Join(lines, ""))
+		return b.String()
 	}
 
+	// Scrolled: the visible lines and where they are in the list.
+	h--
+	end := min(m.offset+h, len(lines))
+	b.WriteString(strings.Join(lines[m.offset:end], ""))
+	b.WriteString(" " + cli.Dim.Render(fmt.Sprintf("%d/%d", m.cursor+1, len(m.items))) + "\n")
 	return b.String()
 }
 
 // renderItemRow renders a single item row with right-aligned port.
-func renderItemRow(m model, item listItem, index, nameW, portW int) string {
+func renderItemRow(m model, item listItem, index int) string {
 	// Fixed content width for left pane
 	const contentWidth = 22
 
@@ -86,13 +75,21 @@ func renderItemRow(m model, item listItem, index, nameW, portW int) string {
 	if item.Running {
 		dot = runningDot
 	}
+	if _, ok := m.pending[item.Name]; ok {
+		dot = m.spinner.View()
+	}
+
+	mark := " "
+	if m.marked[item.Name] {
+		mark = markedStyle.Render("✓")
+	}
 
 	if index == m.cursor {
 		// Selected row
-		return " " + dot + " " + selectedStyle.Render(row) + "\n"
+		return mark + dot + " " + selectedStyle.Render(row) + "\n"
 	}
 	// Normal row
-	return " " + dot + " " + normalStyle.Render(row) + "\n"
+	return mark + dot + " " + normalStyle.Render(row) + "\n"
 }
 
 // columnWidths calculates NAME and PORT column widths.
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/config"
+	"github.com/jaiir320/devserve/protocol"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// topModel is a live, auto-refreshing resource usage table.
+type topModel struct {
+	result *protocol.ListResult
+	err    error
+}
+
+// topResultMsg carries a fresh process list from the daemon.
+type topResultMsg struct {
+	result *protocol.ListResult
+	err    error
+}
+
+// topTickMsg triggers the next refresh.
+type topTickMsg struct{}
+
+// RunTop launches the live resource usage view.
+func RunTop() error {
+	_, err := tea.NewProgram(topModel{}, tea.WithAltScreen()).Run()
+	return err
+}
+
+func fetchTop() tea.Msg {
+	lr, err := client.ListWithHistory()
+	return topResultMsg{result: lr, err: err}
+}
+
+func (m topModel) Init() tea.Cmd {
+	return fetchTop
+}
+
+func (m topModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case topResultMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.result = msg.result
+		}
+		return m, tea.Tick(config.UsageSampleInterval, func(time.Time) tea.Msg {
+			return topTickMsg{}
+		})
+
+	case topTickMsg:
+		return m, fetchTop
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m topModel) View() string {
+	var b strings.Builder
+	b.WriteString(cli.RenderTop(m.result))
+	b.WriteString("\n\n")
+	if m.err != nil {
+		b.WriteString("  " + cli.Error(m.err.Error()) + "\n")
+	}
+	b.WriteString(cli.Dim.Render("  refreshes every " + config.UsageSampleInterval.String() + " • q quit"))
+	return b.String()
+}
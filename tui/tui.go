@@ -3,9 +3,13 @@ package tui
 import (
 	"github.com/jaiir320/devserve/cli"
 	"github.com/jaiir320/devserve/client"
+	"github.com/jaiir320/devserve/protocol"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -21,14 +25,40 @@ type listItem struct {
 	LocalURL   string
 	IPURL      string
 	DNSURL     string
+	Tags       []string         // from the config, or the running process if unsaved
+	Started    time.Time        // when the running process was started
+	ExitReason string           // set when a running process has exited on its own
+	Usage      *protocol.Usage  // latest resource sample, nil if not running
+	History    []protocol.Usage // recent samples, oldest first
 }
 
 type model struct {
-	items     []listItem
+	all       []listItem // every process, as fetched
+	items     []listItem // the list as shown: filtered, grouped and sorted
 	cursor    int
+	offset    int // first visible line of the list
 	width     int
+	height    int
 	statusMsg string
 	statusErr bool
+
+	filter       string // fuzzy filter on names and tags
+	filterTyping bool   // reading the filter at the top of the list
+	sort         sortMode
+	group        groupMode
+	marked       map[string]bool // names marked for a bulk start/stop
+
+	pending map[string]string // item name to the action in progress on it
+	spinner spinner.Model
+
+	signalMode  bool // waiting for a signal key, see signalKeys
+	signalGroup bool // signal the whole process group
+
+	urlIndex int // which of the selected item's URLs o and y act on
+
+	qr   *qrView     // the QR code shown, nil when showing the list
+	logs *logViewer  // the open log viewer, nil when showing the list
+	form *configForm // the open config form, nil when showing the list
 }
 
 // Run launches the TUI. It ensures the daemon is running, fetches the
@@ -42,9 +72,7 @@ func Run() error {
 		items = nil
 	}
 
-	m := model{
-		items: items,
-	}
+	m := model{spinner: newSpinner()}.setItems(items)
 
 	p := tea.NewProgram(m)
 	_, err = p.Run()
@@ -54,20 +82,126 @@ func Run() error {
 // -- bubbletea interface --
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return refreshTick()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+	m.scrollList()
+	return m, cmd
+}
+
+func (m model) update(msg tea.Msg) (model, tea.Cmd) {
+	// Background updates arrive whatever is showing.
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
-		return m, nil
+		m.height = msg.Height
+		if m.logs != nil {
+			m.logs.scroll(m.logHeight())
+		}
+
+	case itemsMsg:
+		if msg.err != nil {
+			m.statusMsg = fmt.Sprintf("failed to reload: %s", msg.err)
+			m.statusErr = true
+			return m, nil
+		}
+		return m.setItems(msg.items), nil
 
+	case refreshTickMsg:
+		return m, tea.Batch(loadItems, refreshTick())
+
+	case actionMsg:
+		return m.finishAction(msg)
+
+	case spinner.TickMsg:
+		if len(m.pending) == 0 {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case logsMsg, logsTickMsg:
+		return m.updateLogs(msg)
+	}
+
+	if m.form != nil {
+		return m.updateForm(msg)
+	}
+
+	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.logs != nil {
+			return m.handleLogKey(msg)
+		}
+		if m.qr != nil {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			m.qr = nil
+			return m, nil
+		}
+		if m.signalMode {
+			return m.handleSignalKey(msg.String())
+		}
+		if m.filterTyping {
+			return m.handleFilterKey(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 
+		case "esc":
+			// Clear marks first, then the filter.
+			if len(m.marked) > 0 {
+				m.marked = nil
+			} else if m.filter != "" {
+				m.filter = ""
+				m = m.applyView()
+			}
+			return m, nil
+
+		case "/":
+			m.filterTyping = true
+			m.statusMsg = ""
+			return m, nil
+
+		case "S":
+			m.sort = (m.sort + 1) % numSortModes
+			m.statusMsg = "sorted by " + sortModeNames[m.sort]
+			m.statusErr = false
+			return m.applyView(), nil
+
+		case "G":
+			m.group = (m.group + 1) % numGroupModes
+			m.statusMsg = "grouped by " + groupModeNames[m.group]
+			m.statusErr = false
+			return m.applyView(), nil
+
+		case " ":
+			return m.toggleMark(), nil
+
+		case "A":
+			return m.toggleMarkAll(), nil
+
+		case "pgup", "pgdown":
+			page := max(m.listHeight()-1, 1)
+			if msg.String() == "pgup" {
+				page = -page
+			}
+			m.moveCursor(page)
+			return m, nil
+
+		case "home":
+			m.cursor = 0
+			return m, nil
+
+		case "end":
+			m.moveCursor(len(m.items))
+			return m, nil
+
 		case "up", "k":
 			m.moveCursor(-1)
 			m.statusMsg = ""
@@ -79,10 +213,64 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case "enter":
+			if len(m.marked) > 0 {
+				return m.bulkStartStop()
+			}
 			return m.toggleStartStop()
 
 		case "s":
 			return m.toggleSave()
+
+		case "n":
+			return m.openForm(formCreate)
+
+		case "e":
+			return m.openForm(formEdit)
+
+		case "c":
+			return m.openForm(formDuplicate)
+
+		case "d":
+			return m.openForm(formDelete)
+
+		case "l":
+			if len(m.items) == 0 {
+				return m, nil
+			}
+			item := m.items[m.cursor]
+			if !item.Running {
+				m.statusMsg = fmt.Sprintf("'%s' is not running", item.Name)
+				m.statusErr = true
+				return m, nil
+			}
+			m.logs = newLogViewer(item.Name)
+			m.statusMsg = ""
+			return m, fetchLogs(m.logs)
+
+		case "u":
+			m.urlIndex++
+			if url := m.selectedURL(); url != "" {
+				m.statusMsg = "selected " + url
+				m.statusErr = false
+			}
+			return m, nil
+
+		case "o":
+			return m.openURL()
+
+		case "y":
+			return m.copyURL()
+
+		case "Q":
+			return m.showQR()
+
+		case "x":
+			if len(m.items) > 0 && m.items[m.cursor].Running {
+				m.signalMode = true
+				m.signalGroup = false
+				m.statusMsg = ""
+			}
+			return m, nil
 		}
 	}
 	return m, nil
@@ -130,6 +318,15 @@ func (m model) View() string {
 	if m.width == 0 {
 		return ""
 	}
+	if m.logs != nil {
+		return m.renderLogView()
+	}
+	if m.form != nil {
+		return "\n" + m.form.form.View()
+	}
+	if m.qr != nil {
+		return m.renderQRView()
+	}
 
 	// Fixed left pane width, give rest to right pane
 	leftW := leftContentWidth + leftPaneBorderPadding
@@ -139,8 +336,8 @@ func (m model) View() string {
 	}
 
 	// Render pane contents first (without styling)
-	leftContent := renderLeftPane(m)
-	rightContent := renderRightPane(m)
+	leftContent := strings.TrimSuffix(renderLeftPane(m), "\n")
+	rightContent := strings.TrimSuffix(renderRightPane(m), "\n")
 
 	// Calculate content heights
 	leftContentHeight := lipgloss.Height(leftContent)
@@ -150,8 +347,9 @@ func (m model) View() string {
 		contentHeight = rightContentHeight
 	}
 
-	// Total height includes borders (2 rows) but padding is internal
-	paneHeight := contentHeight + 2 // +2 for top/bottom borders only
+	// The borders are drawn outside this height, so the panes fit the
+	// scrolled list.
+	paneHeight := contentHeight
 
 	// Apply border styles with calculated dimensions
 	leftPane := leftPaneStyle.
@@ -175,6 +373,8 @@ func (m model) View() string {
 		} else {
 			statusLine = "  " + cli.Success(m.statusMsg)
 		}
+		// Keep to one line; start failures carry the process's last output.
+		statusLine = lipgloss.NewStyle().MaxWidth(m.width).Render(statusLine)
 	}
 
 	// Stack: body + status + help
@@ -183,13 +383,26 @@ func (m model) View() string {
 	if statusLine != "" {
 		b.WriteString(statusLine + "\n")
 	}
-	b.WriteString(renderHelp())
+	b.WriteString(m.helpBar())
 
 	return b.String()
 }
 
+// helpBar returns the help bar for the current mode.
+func (m model) helpBar() string {
+	switch {
+	case m.signalMode && len(m.items) > 0:
+		return renderSignalHelp(m.items[m.cursor].Name, m.signalGroup, m.width)
+	case len(m.marked) > 0:
+		return renderMarkedHelp(len(m.marked), m.width)
+	}
+	return renderHelp(m.width)
+}
+
 // -- actions --
 
+// toggleStartStop stops the selected process if it is running, or starts
+// it from its saved config, in the background.
 func (m model) toggleStartStop() (model, tea.Cmd) {
 	if len(m.items) == 0 {
 		return m, nil
@@ -198,95 +411,89 @@ func (m model) toggleStartStop() (model, tea.Cmd) {
 	item := m.items[m.cursor]
 
 	if item.Running {
-		// Stop the process
-		err := stopProcess(item.Name)
-		if err != nil {
-			m.statusMsg = fmt.Sprintf("failed to stop '%s': %s", item.Name, err)
-			m.statusErr = true
-			return m, nil
-		}
-		m.statusMsg = fmt.Sprintf("process '%s' stopped", item.Name)
-		m.statusErr = false
-	} else {
-		// Start the process (only configured items can be started)
-		if !item.Configured {
-			m.statusMsg = fmt.Sprintf("cannot start '%s': save to config first (press 's')", item.Name)
-			m.statusErr = true
-			return m, nil
-		}
-		err := startItem(item)
-		if err != nil {
-			m.statusMsg = fmt.Sprintf("failed to start '%s': %s", item.Name, err)
-			m.statusErr = true
-			return m, nil
-		}
-		m.statusMsg = fmt.Sprintf("process '%s' started", item.Name)
-		m.statusErr = false
+		return m.stopAction(item)
+	}
+
+	// Only configured items can be started
+	if !item.Configured {
+		m.statusMsg = fmt.Sprintf("cannot start '%s': save to config first (press 's')", item.Name)
+		m.statusErr = true
+		return m, nil
 	}
+	return m.startAction(item)
+}
 
-	// Reload data to reflect changes
-	return m.reload()
+// startAction starts a configured item in the background.
+func (m model) startAction(item listItem) (model, tea.Cmd) {
+	return m.runAction(item.Name, "starting", func() (string, error) {
+		if err := startItem(item); err != nil {
+			msg := fmt.Sprintf("failed to start '%s': %s", item.Name, err)
+			var de *protocol.DiagnosticsError
+			if errors.As(err, &de) {
+				if summary := cli.DiagnosticsSummary(&de.Diagnostics); summary != "" {
+					msg += " · " + summary
+				}
+			}
+			return "", errors.New(msg)
+		}
+		return fmt.Sprintf("process '%s' started", item.Name), nil
+	})
 }
 
-func (m model) toggleSave() (model, tea.Cmd) {
-	if len(m.items) == 0 {
+// stopAction stops a running item in the background.
+func (m model) stopAction(item listItem) (model, tea.Cmd) {
+	return m.runAction(item.Name, "stopping", func() (string, error) {
+		if err := stopProcess(item.Name); err != nil {
+			return "", fmt.Errorf("failed to stop '%s': %w", item.Name, err)
+		}
+		return fmt.Sprintf("process '%s' stopped", item.Name), nil
+	})
+}
+
+// handleSignalKey picks a signal in signal mode: a signal key sends it,
+// g toggles the whole group, anything else cancels.
+func (m model) handleSignalKey(key string) (model, tea.Cmd) {
+	if key == "g" {
+		m.signalGroup = !m.signalGroup
+		return m, nil
+	}
+	m.signalMode = false
+	sig, ok := signalKeys[key]
+	if !ok || len(m.items) == 0 {
 		return m, nil
 	}
 
 	item := m.items[m.cursor]
-
-	if item.Configured {
-		// Remove from config
-		err := removeFromConfig(item.Name)
+	group := m.signalGroup
+	return m.runAction(item.Name, "signalling", func() (string, error) {
+		msg, err := signalProcess(item.Name, sig, group)
 		if err != nil {
-			m.statusMsg = fmt.Sprintf("failed to remove '%s' from config: %s", item.Name, err)
-			m.statusErr = true
-			return m, nil
-		}
-		m.statusMsg = fmt.Sprintf("'%s' removed from config", item.Name)
-		m.statusErr = false
-	} else {
-		// Save to config
-		err := saveToConfig(item)
-		if err != nil {
-			m.statusMsg = fmt.Sprintf("failed to save '%s' to config: %s", item.Name, err)
-			m.statusErr = true
-			return m, nil
+			return "", fmt.Errorf("failed to signal '%s': %w", item.Name, err)
 		}
-		m.statusMsg = fmt.Sprintf("'%s' saved to config", item.Name)
-		m.statusErr = false
-	}
-
-	// Reload data to reflect changes
-	return m.reload()
+		return msg, nil
+	})
 }
 
-// reload refreshes the data from daemon and config.
-func (m model) reload() (model, tea.Cmd) {
-	items, err := fetchItems()
-	if err != nil {
-		m.statusMsg = fmt.Sprintf("failed to reload: %s", err)
-		m.statusErr = true
+// toggleSave saves the selected process to the config, or removes it.
+func (m model) toggleSave() (model, tea.Cmd) {
+	if len(m.items) == 0 {
 		return m, nil
 	}
 
-	// Preserve cursor position if possible
-	oldCursor := m.cursor
-	m.items = items
-
-	// Adjust cursor if out of bounds
-	if m.cursor >= len(m.items) {
-		m.cursor = len(m.items) - 1
-	}
-	if m.cursor < 0 {
-		m.cursor = 0
-	}
+	item := m.items[m.cursor]
 
-	// If cursor changed dramatically, try to find the same item by name
-	if oldCursor < len(m.items) && oldCursor >= 0 {
-		// Cursor is still valid, keep it
-		m.cursor = oldCursor
+	if item.Configured {
+		return m.runAction(item.Name, "removing", func() (string, error) {
+			if err := removeFromConfig(item.Name); err != nil {
+				return "", fmt.Errorf("failed to remove '%s' from config: %w", item.Name, err)
+			}
+			return fmt.Sprintf("'%s' removed from config", item.Name), nil
+		})
 	}
-
-	return m, nil
+	return m.runAction(item.Name, "saving", func() (string, error) {
+		if err := saveToConfig(item); err != nil {
+			return "", fmt.Errorf("failed to save '%s' to config: %w", item.Name, err)
+		}
+		return fmt.Sprintf("'%s' saved to config", item.Name), nil
+	})
 }
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"github.com/jaiir320/devserve/cli"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// qrView is a full-screen QR code of a process's tailnet URL.
+type qrView struct {
+	name string
+	url  string
+	code string
+}
+
+// urls returns the item's URLs while it runs: local, then its tailnet IP
+// and DNS URLs when known.
+func (item listItem) urls() []string {
+	var urls []string
+	for _, u := range []string{item.LocalURL, item.IPURL, item.DNSURL} {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// selectedURL returns the URL of the selected item that o and y act on,
+// or "" if it has none.
+func (m model) selectedURL() string {
+	if len(m.items) == 0 {
+		return ""
+	}
+	urls := m.items[m.cursor].urls()
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[m.urlIndex%len(urls)]
+}
+
+// noURL sets the status for an item without URLs.
+func (m model) noURL() (model, tea.Cmd) {
+	if len(m.items) > 0 {
+		m.statusMsg = fmt.Sprintf("'%s' is not running", m.items[m.cursor].Name)
+		m.statusErr = true
+	}
+	return m, nil
+}
+
+// openURL opens the selected URL in the browser in the background.
+func (m model) openURL() (model, tea.Cmd) {
+	url := m.selectedURL()
+	if url == "" {
+		return m.noURL()
+	}
+	return m.runAction(m.items[m.cursor].Name, "opening", func() (string, error) {
+		if err := cli.OpenBrowser(url); err != nil {
+			return "", fmt.Errorf("failed to open %s: %w (press y to copy it)", url, err)
+		}
+		return "opened " + url, nil
+	})
+}
+
+// copyURL copies the selected URL to the clipboard.
+func (m model) copyURL() (model, tea.Cmd) {
+	url := m.selectedURL()
+	if url == "" {
+		return m.noURL()
+	}
+	m.statusMsg = "copied " + url
+	m.statusErr = false
+	return m, copyToClipboard(url)
+}
+
+// showQR shows a QR code of the selected item's tailnet URL, the one a
+// phone on the tailnet can reach.
+func (m model) showQR() (model, tea.Cmd) {
+	if len(m.items) == 0 {
+		return m, nil
+	}
+	item := m.items[m.cursor]
+	if !item.Running {
+		return m.noURL()
+	}
+	url := item.DNSURL
+	if url == "" {
+		url = item.IPURL
+	}
+	if url == "" {
+		m.statusMsg = "no tailnet URL: is tailscale running?"
+		m.statusErr = true
+		return m, nil
+	}
+	code, err := cli.RenderQR(url)
+	if err != nil {
+		m.statusMsg = err.Error()
+		m.statusErr = true
+		return m, nil
+	}
+	m.qr = &qrView{name: item.Name, url: url, code: code}
+	return m, nil
+}
+
+// renderQRView renders the open QR code.
+func (m model) renderQRView() string {
+	var b strings.Builder
+	b.WriteString("\n " + cli.Bold.Render(m.qr.name) + " " + urlStyle.Render(m.qr.url) + "\n\n")
+	for _, line := range strings.Split(strings.TrimSuffix(m.qr.code, "\n"), "\n") {
+		b.WriteString("  " + line + "\n")
+	}
+	b.WriteString("\n" + cli.Dim.Render("  scan to open on your phone • any key to go back"))
+	return b.String()
+}
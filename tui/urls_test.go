@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func urlModel() model {
+	return model{width: 100, height: 30, spinner: newSpinner()}.setItems([]listItem{
+		{Name: "web", Port: 3000, Running: true, Configured: true,
+			LocalURL: "http://localhost:3000", IPURL: "http://100.1.2.3:3000", DNSURL: "https://host.example.ts.net:3000"},
+		{Name: "api", Port: 4000, Configured: true},
+	})
+}
+
+func TestSelectedURLCycles(t *testing.T) {
+	m := urlModel()
+	var got []string
+	for range 4 {
+		got = append(got, m.selectedURL())
+		next, _ := m.Update(keyMsg("u"))
+		m = next.(model)
+	}
+	want := []string{"http://localhost:3000", "http://100.1.2.3:3000", "https://host.example.ts.net:3000", "http://localhost:3000"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !strings.Contains(renderRightPane(m), "◂") {
+		t.Error("expected the selected URL marked in the detail pane")
+	}
+}
+
+func TestOpenAndCopyURL(t *testing.T) {
+	m := urlModel()
+	m, cmd := m.openURL()
+	if cmd == nil || m.pending["web"] != "opening" {
+		t.Errorf("expected the browser opened in the background, got %v", m.pending)
+	}
+
+	m, cmd = m.copyURL()
+	if cmd == nil || m.statusMsg != "copied http://localhost:3000" {
+		t.Errorf("expected the URL copied, got %q", m.statusMsg)
+	}
+
+	// A stopped process has no URLs.
+	next, _ := m.Update(keyMsg("j"))
+	m = next.(model)
+	m, cmd = m.copyURL()
+	if cmd != nil || !m.statusErr || !strings.Contains(m.statusMsg, "not running") {
+		t.Errorf("expected an error for a stopped process, got %q", m.statusMsg)
+	}
+}
+
+func TestShowQR(t *testing.T) {
+	m := urlModel()
+	next, _ := m.Update(keyMsg("Q"))
+	m = next.(model)
+	if m.qr == nil || m.qr.url != "https://host.example.ts.net:3000" {
+		t.Fatalf("expected a QR code of the DNS URL, got %+v", m.qr)
+	}
+	if view := m.View(); !strings.Contains(view, "█") || !strings.Contains(view, m.qr.url) {
+		t.Errorf("expected the code and URL in the view, got:\n%s", view)
+	}
+	next, _ = m.Update(keyMsg("q"))
+	m = next.(model)
+	if m.qr != nil {
+		t.Error("expected any key to close the QR code")
+	}
+
+	// Without tailscale there is nothing a phone can reach.
+	m.all[0].DNSURL, m.all[0].IPURL = "", ""
+	m = m.applyView()
+	m, _ = m.showQR()
+	if m.qr != nil || !strings.Contains(m.statusMsg, "tailscale") {
+		t.Errorf("expected an error without a tailnet URL, got %q", m.statusMsg)
+	}
+}
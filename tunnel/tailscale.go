@@ -1,6 +1,7 @@
 package tunnel
 
 import (
+	"github.com/jaiir320/devserve/reaper"
 	"encoding/json"
 	"fmt"
 	"os/exec"
@@ -18,7 +19,7 @@ type CommandRunner func() ([]byte, error)
 
 // DefaultTailscaleRunner runs `tailscale status --json`.
 func DefaultTailscaleRunner() ([]byte, error) {
-	return exec.Command("tailscale", "status", "--json").Output()
+	return reaper.Output(exec.Command("tailscale", "status", "--json"))
 }
 
 // DefaultRunner is the package-level command runner for fetching tailscale status.
@@ -1,27 +1,31 @@
 package tunnel
 
 import (
+	"github.com/jaiir320/devserve/reaper"
+	"fmt"
 	"os/exec"
 	"strconv"
 )
 
 // Tunnel abstracts a tunneling provider (Tailscale, Cloudflare, ngrok, etc.)
 type Tunnel interface {
-	Serve(port int) error
+	// Serve exposes port, proxying to upstream, the host:port address the
+	// server accepts connections on.
+	Serve(port int, upstream string) error
 	Stop(port int) error
 }
 
 // TailscaleTunnel implements Tunnel using tailscale serve.
 type TailscaleTunnel struct{}
 
-func (TailscaleTunnel) Serve(port int) error {
+func (TailscaleTunnel) Serve(port int, upstream string) error {
 	portStr := strconv.Itoa(port)
-	return exec.Command("tailscale", "serve", "--https", portStr, "--bg", "localhost:"+portStr).Run()
+	return reaper.Run(exec.Command("tailscale", "serve", "--https", portStr, "--bg", "http://"+upstream))
 }
 
 func (TailscaleTunnel) Stop(port int) error {
 	portStr := strconv.Itoa(port)
-	return exec.Command("tailscale", "serve", "--https", portStr, "off").Run()
+	return reaper.Run(exec.Command("tailscale", "serve", "--https", portStr, "off"))
 }
 
 // DefaultTunnel is the package-level tunnel used by Process.
@@ -31,3 +35,26 @@ var DefaultTunnel Tunnel = TailscaleTunnel{}
 func SetTunnel(t Tunnel) {
 	DefaultTunnel = t
 }
+
+// NoTunnel exposes nothing, leaving processes reachable only locally.
+type NoTunnel struct{}
+
+func (NoTunnel) Serve(port int, upstream string) error { return nil }
+
+func (NoTunnel) Stop(port int) error { return nil }
+
+// Use selects the tunnel provider by name: "tailscale", or "none" for
+// local URLs only, in which case no tailnet address is reported either.
+func Use(name string) error {
+	switch name {
+	case "tailscale":
+		SetTunnel(TailscaleTunnel{})
+		SetRunner(DefaultTailscaleRunner)
+	case "none":
+		SetTunnel(NoTunnel{})
+		SetRunner(func() ([]byte, error) { return []byte("{}"), nil })
+	default:
+		return fmt.Errorf("unknown tunnel provider %q", name)
+	}
+	return nil
+}